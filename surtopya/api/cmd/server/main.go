@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/exports"
+	"github.com/TimLai666/surtopya-api/internal/realtime"
+	"github.com/TimLai666/surtopya-api/internal/repository"
 	"github.com/TimLai666/surtopya-api/internal/routes"
+	"github.com/TimLai666/surtopya-api/internal/scheduler"
+	"github.com/TimLai666/surtopya-api/internal/storage"
+	"github.com/TimLai666/surtopya-api/internal/templates"
 	"github.com/joho/godotenv"
 )
 
@@ -15,6 +22,11 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// Survey import/export subcommands run against the database and exit
+	if len(os.Args) > 1 && os.Args[1] == "survey" {
+		os.Exit(runSurveyCommand(os.Args[2:]))
+	}
+
 	// Initialize database connection
 	dbConfig := database.LoadConfigFromEnv()
 	if err := database.Connect(dbConfig); err != nil {
@@ -23,6 +35,29 @@ func main() {
 	} else {
 		log.Println("Successfully connected to database")
 		defer database.Close()
+
+		// Seed curated survey templates
+		templatesDir := os.Getenv("TEMPLATES_DIR")
+		if templatesDir == "" {
+			templatesDir = "templates"
+		}
+		if err := templates.Seed(repository.NewTemplateRepository(database.GetDB()), templatesDir); err != nil {
+			log.Printf("Warning: Could not seed templates: %v", err)
+		}
+
+		// Start background jobs (scheduled open/close, abandoned-response sweeper)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go scheduler.New(database.GetDB(), scheduler.LoadConfigFromEnv()).Run(ctx)
+
+		// Run queued response exports and delete expired export files
+		exportConfig := exports.LoadConfigFromEnv()
+		go exports.NewWorker(database.GetDB(), storage.NewDisk(exportConfig.StorageDir), exportConfig).Run(ctx)
+
+		// Stream completed responses to live dashboards, across instances
+		if err := realtime.Start(ctx, database.GetDB(), dbConfig.ConnString()); err != nil {
+			log.Printf("Warning: Could not start live responses: %v", err)
+		}
 	}
 
 	// Setup router
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/TimLai666/surtopya-api/internal/surveydoc"
+	"github.com/google/uuid"
+)
+
+const surveyUsage = `Usage:
+  main survey export [-o file] <survey-id>
+  main survey import -owner <user-id|email> [-org <organization-id>] <file|->
+
+Export writes the survey as a portable JSON document; import validates a
+document and creates it as a new unpublished draft. Both connect to the
+database configured by the DB_* environment variables.`
+
+// runSurveyCommand runs the survey export/import subcommands and returns the exit code
+func runSurveyCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, surveyUsage)
+		return 2
+	}
+
+	if err := database.Connect(database.LoadConfigFromEnv()); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not connect to database: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = exportSurvey(args[1:])
+	case "import":
+		err = importSurvey(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, surveyUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func exportSurvey(args []string) error {
+	flags := flag.NewFlagSet("survey export", flag.ContinueOnError)
+	output := flags.String("o", "", "write the document to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one survey ID\n\n%s", surveyUsage)
+	}
+
+	id, err := uuid.Parse(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid survey ID: %w", err)
+	}
+
+	db := database.GetDB()
+	survey, err := repository.NewSurveyRepository(db).GetByID(id)
+	if err != nil {
+		return err
+	}
+	if survey == nil {
+		return fmt.Errorf("survey %s not found", id)
+	}
+
+	quotas, err := repository.NewQuotaRepository(db).GetBySurveyID(id)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(surveydoc.Export(survey, quotas), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal survey document: %w", err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
+
+func importSurvey(args []string) error {
+	flags := flag.NewFlagSet("survey import", flag.ContinueOnError)
+	owner := flags.String("owner", "", "user ID or email of the new survey's owner (required)")
+	org := flags.String("org", "", "organization to create the survey in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *owner == "" {
+		return fmt.Errorf("expected -owner and one file\n\n%s", surveyUsage)
+	}
+
+	var data []byte
+	var err error
+	if flags.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read survey document: %w", err)
+	}
+
+	doc, err := surveydoc.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	ownerID, err := resolveUser(repository.NewUserRepository(db), *owner)
+	if err != nil {
+		return err
+	}
+
+	survey, quotas := doc.Build(ownerID)
+	if *org != "" {
+		orgID, err := uuid.Parse(*org)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID: %w", err)
+		}
+		organization, err := repository.NewOrganizationRepository(db).GetByID(orgID)
+		if err != nil {
+			return err
+		}
+		if organization == nil {
+			return fmt.Errorf("organization %s not found", orgID)
+		}
+		survey.OrganizationID = &orgID
+	}
+
+	surveyRepo := repository.NewSurveyRepository(db)
+	if err := surveyRepo.CreateWithQuotas(survey, quotas); err != nil {
+		return err
+	}
+	if err := surveyRepo.SaveRevision(survey, &ownerID); err != nil {
+		return err
+	}
+
+	fmt.Println(survey.ID)
+	return nil
+}
+
+// resolveUser looks up a user by ID or email
+func resolveUser(userRepo *repository.UserRepository, idOrEmail string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(idOrEmail); err == nil {
+		user, err := userRepo.GetByID(id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if user == nil {
+			return uuid.Nil, fmt.Errorf("user %s not found", id)
+		}
+		return user.ID, nil
+	}
+
+	if !strings.Contains(idOrEmail, "@") {
+		return uuid.Nil, fmt.Errorf("owner must be a user ID or email")
+	}
+
+	user, err := userRepo.GetByEmail(idOrEmail)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if user == nil {
+		return uuid.Nil, fmt.Errorf("user %s not found", idOrEmail)
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,135 @@
+// Package converter turns surveys exported from other tools into Surtopya
+// questions. Source types are mapped to the closest Surtopya question type;
+// anything that cannot be represented is listed in the conversion report.
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Supported source formats
+const (
+	FormatGoogleForms = "google-forms" // Google Forms API form resource (JSON)
+	FormatLimeSurvey  = "limesurvey"   // LimeSurvey survey structure export (.lss XML)
+)
+
+// ErrUnknownFormat is returned for an unsupported source format
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Issue kinds
+const (
+	IssueSkipped = "skipped" // The item was not converted
+	IssueChanged = "changed" // The item was converted with a loss or approximation
+)
+
+// Issue describes a source item that could not be converted exactly
+type Issue struct {
+	Item    string `json:"item"` // Source question title or code
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Report summarizes a conversion
+type Report struct {
+	Source    string  `json:"source"`
+	Converted int     `json:"converted"` // Source items converted, possibly with changes
+	Skipped   int     `json:"skipped"`
+	Issues    []Issue `json:"issues"`
+}
+
+// Result is a converted survey
+type Result struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Questions   []models.Question `json:"questions"`
+	Report      Report            `json:"report"`
+}
+
+// Convert converts an exported survey; language selects the LimeSurvey
+// language to import and defaults to the survey's base language
+func Convert(format string, data []byte, language string) (*Result, error) {
+	switch format {
+	case FormatGoogleForms:
+		return ConvertGoogleForms(data)
+	case FormatLimeSurvey:
+		return ConvertLimeSurvey(data, language)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// builder accumulates converted questions and report issues
+type builder struct {
+	result Result
+}
+
+func newBuilder(source string) *builder {
+	return &builder{result: Result{
+		Questions: []models.Question{},
+		Report:    Report{Source: source, Issues: []Issue{}},
+	}}
+}
+
+// add appends a question and returns it for further setup;
+// the pointer is only valid until the next add
+func (b *builder) add(questionType, title, description string) *models.Question {
+	q := models.Question{
+		ID:        uuid.New(),
+		Type:      questionType,
+		Title:     title,
+		SortOrder: len(b.result.Questions),
+	}
+	if description != "" {
+		q.Description = &description
+	}
+	b.result.Questions = append(b.result.Questions, q)
+	return &b.result.Questions[len(b.result.Questions)-1]
+}
+
+func (b *builder) converted() {
+	b.result.Report.Converted++
+}
+
+func (b *builder) skip(item, format string, args ...interface{}) {
+	b.result.Report.Skipped++
+	b.issue(item, IssueSkipped, format, args...)
+}
+
+func (b *builder) change(item, format string, args ...interface{}) {
+	b.issue(item, IssueChanged, format, args...)
+}
+
+func (b *builder) issue(item, kind, format string, args ...interface{}) {
+	b.result.Report.Issues = append(b.result.Report.Issues, Issue{
+		Item:    item,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+var spacePattern = regexp.MustCompile(`\s+`)
+
+// plainText strips HTML markup from rich text
+func plainText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+// label returns the first non-empty value, for naming items in the report
+func label(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return "(untitled)"
+}
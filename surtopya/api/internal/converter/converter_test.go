@@ -0,0 +1,184 @@
+package converter
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+// question is the part of a converted question the tests compare
+type question struct {
+	Type      string
+	Title     string
+	Options   []string
+	Required  bool
+	MaxRating int
+}
+
+func summarize(questions []models.Question) []question {
+	result := make([]question, len(questions))
+	for i, q := range questions {
+		result[i] = question{q.Type, q.Title, q.Options, q.Required, q.MaxRating}
+	}
+	return result
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		fixture     string
+		language    string
+		title       string
+		description string
+		questions   []question
+		converted   int
+		skipped     int
+	}{
+		{
+			name:        "google forms",
+			format:      FormatGoogleForms,
+			fixture:     "google-form.json",
+			title:       "Customer feedback",
+			description: "Tell us about your visit",
+			questions: []question{
+				{"single", "Did you buy something?", []string{"Yes", "No"}, true, 0},
+				{"multi", "How did you hear about us?", []string{"Friends", "Ads", "Other"}, false, 0},
+				{"section", "Your purchase", nil, false, 0},
+				{"rating", "How likely are you to recommend us?", nil, false, 10},
+				{"single", "Rate each aspect - Price", []string{"Bad", "Good"}, true, 0},
+				{"single", "Rate each aspect - Service", []string{"Bad", "Good"}, false, 0},
+				{"long", "Anything else?", nil, false, 0},
+			},
+			converted: 6,
+			skipped:   2,
+		},
+		{
+			name:        "limesurvey base language",
+			format:      FormatLimeSurvey,
+			fixture:     "limesurvey.lss",
+			title:       "Shopping habits",
+			description: "A short survey",
+			questions: []question{
+				{"section", "About you", nil, false, 0},
+				{"single", "How often do you shop ?", []string{"Daily", "Weekly", "Other"}, true, 0},
+				{"long", "Any comments?", nil, false, 0},
+				{"section", "Details", nil, false, 0},
+				{"single", "Rate the store - Prices", []string{"Poor", "Good"}, false, 0},
+				{"single", "Rate the store - Service", []string{"Poor", "Good"}, false, 0},
+			},
+			converted: 3,
+			skipped:   1,
+		},
+		{
+			name:     "limesurvey other language",
+			format:   FormatLimeSurvey,
+			fixture:  "limesurvey.lss",
+			language: "zh-Hant",
+			title:    "購物習慣",
+			questions: []question{
+				{"section", "關於你", nil, false, 0},
+				{"single", "你多常購物？", []string{"每天", "每週", "Other"}, true, 0},
+				{"section", "細節", nil, false, 0},
+			},
+			converted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.format, readFixture(t, tt.fixture), tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Title != tt.title || result.Description != tt.description {
+				t.Errorf("title, description = %q, %q; want %q, %q", result.Title, result.Description, tt.title, tt.description)
+			}
+			if got := summarize(result.Questions); !reflect.DeepEqual(got, tt.questions) {
+				t.Errorf("questions =\n%+v\nwant\n%+v", got, tt.questions)
+			}
+			if result.Report.Converted != tt.converted || result.Report.Skipped != tt.skipped {
+				t.Errorf("converted, skipped = %d, %d; want %d, %d", result.Report.Converted, result.Report.Skipped, tt.converted, tt.skipped)
+			}
+			for i, q := range result.Questions {
+				if q.SortOrder != i {
+					t.Errorf("question %d has sort order %d", i, q.SortOrder)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertGoogleFormsLogic(t *testing.T) {
+	result, err := ConvertGoogleForms(readFixture(t, "google-form.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	section := result.Questions[2].ID.String()
+	want := []models.LogicRule{
+		{TriggerOption: "No", DestinationQuestionID: models.EndSurveyDestination},
+		{TriggerOption: "Yes", DestinationQuestionID: section},
+	}
+	if got := result.Questions[0].Logic; !reflect.DeepEqual(got, want) {
+		t.Errorf("logic = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertLimeSurveyWithoutGroups(t *testing.T) {
+	// Rows are out of order on purpose: the import follows question_order,
+	// then qid, not the order of the file
+	data := []byte(`<document>
+ <questions><rows>
+  <row><qid>3</qid><parent_qid>0</parent_qid><gid>9</gid><type>S</type><title>C</title><question>Third</question><question_order>2</question_order></row>
+  <row><qid>2</qid><parent_qid>0</parent_qid><gid>8</gid><type>S</type><title>B</title><question>Second</question><question_order>1</question_order></row>
+  <row><qid>1</qid><parent_qid>0</parent_qid><gid>9</gid><type>S</type><title>A</title><question>First</question><question_order>1</question_order></row>
+ </rows></questions>
+</document>`)
+
+	result, err := ConvertLimeSurvey(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []question{
+		{"short", "First", nil, false, 0},
+		{"short", "Second", nil, false, 0},
+		{"short", "Third", nil, false, 0},
+	}
+	if got := summarize(result.Questions); !reflect.DeepEqual(got, want) {
+		t.Errorf("questions = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"unknown format", "typeform", `{}`},
+		{"malformed google forms", FormatGoogleForms, `{"items": [`},
+		{"empty google forms", FormatGoogleForms, `{}`},
+		{"malformed limesurvey", FormatLimeSurvey, `<document><questions>`},
+		{"limesurvey without questions", FormatLimeSurvey, `<document><groups><rows/></groups></document>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Convert(tt.format, []byte(tt.data), ""); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Google Forms API form resource, as returned by forms.get
+// (https://developers.google.com/forms/api/reference/rest/v1/forms)
+
+type gfForm struct {
+	FormID string   `json:"formId"`
+	Info   gfInfo   `json:"info"`
+	Items  []gfItem `json:"items"`
+}
+
+type gfInfo struct {
+	Title         string `json:"title"`
+	DocumentTitle string `json:"documentTitle"`
+	Description   string `json:"description"`
+}
+
+type gfItem struct {
+	ItemID            string               `json:"itemId"`
+	Title             string               `json:"title"`
+	Description       string               `json:"description"`
+	QuestionItem      *gfQuestionItem      `json:"questionItem"`
+	QuestionGroupItem *gfQuestionGroupItem `json:"questionGroupItem"`
+	PageBreakItem     *json.RawMessage     `json:"pageBreakItem"`
+	TextItem          *json.RawMessage     `json:"textItem"`
+	ImageItem         *json.RawMessage     `json:"imageItem"`
+	VideoItem         *json.RawMessage     `json:"videoItem"`
+}
+
+type gfQuestionItem struct {
+	Question gfQuestion `json:"question"`
+}
+
+type gfQuestionGroupItem struct {
+	Questions []gfQuestion `json:"questions"`
+	Grid      *gfGrid      `json:"grid"`
+}
+
+type gfGrid struct {
+	Columns gfChoiceQuestion `json:"columns"`
+}
+
+type gfQuestion struct {
+	Required           bool              `json:"required"`
+	ChoiceQuestion     *gfChoiceQuestion `json:"choiceQuestion"`
+	TextQuestion       *gfTextQuestion   `json:"textQuestion"`
+	ScaleQuestion      *gfScaleQuestion  `json:"scaleQuestion"`
+	DateQuestion       *gfDateQuestion   `json:"dateQuestion"`
+	TimeQuestion       *json.RawMessage  `json:"timeQuestion"`
+	FileUploadQuestion *json.RawMessage  `json:"fileUploadQuestion"`
+	RatingQuestion     *gfRatingQuestion `json:"ratingQuestion"`
+	RowQuestion        *gfRowQuestion    `json:"rowQuestion"`
+}
+
+type gfChoiceQuestion struct {
+	Type    string     `json:"type"` // RADIO, CHECKBOX or DROP_DOWN
+	Options []gfOption `json:"options"`
+}
+
+type gfOption struct {
+	Value         string `json:"value"`
+	IsOther       bool   `json:"isOther"`
+	GoToAction    string `json:"goToAction"` // NEXT_SECTION, RESTART_FORM or SUBMIT_FORM
+	GoToSectionID string `json:"goToSectionId"`
+}
+
+type gfTextQuestion struct {
+	Paragraph bool `json:"paragraph"`
+}
+
+type gfScaleQuestion struct {
+	Low       int    `json:"low"`
+	High      int    `json:"high"`
+	LowLabel  string `json:"lowLabel"`
+	HighLabel string `json:"highLabel"`
+}
+
+type gfDateQuestion struct {
+	IncludeTime bool `json:"includeTime"`
+}
+
+type gfRatingQuestion struct {
+	RatingScaleLevel int `json:"ratingScaleLevel"`
+}
+
+type gfRowQuestion struct {
+	Title string `json:"title"`
+}
+
+// gfJump is a branch to a section, resolved once all sections are known
+type gfJump struct {
+	question  uuid.UUID
+	option    string
+	sectionID string
+	item      string
+}
+
+// ConvertGoogleForms converts a Google Forms API form resource. Sections become
+// section questions, so "go to section" branches become logic rules; grids are
+// expanded into one choice question per row.
+func ConvertGoogleForms(data []byte) (*Result, error) {
+	var form gfForm
+	if err := json.Unmarshal(data, &form); err != nil {
+		return nil, fmt.Errorf("invalid Google Forms JSON: %w", err)
+	}
+	if form.FormID == "" && form.Info.Title == "" && len(form.Items) == 0 {
+		return nil, errors.New("not a Google Forms form resource")
+	}
+
+	b := newBuilder(FormatGoogleForms)
+	b.result.Title = label(form.Info.Title, form.Info.DocumentTitle)
+	b.result.Description = form.Info.Description
+
+	sections := make(map[string]uuid.UUID)
+	var jumps []gfJump
+
+	for _, item := range form.Items {
+		name := label(item.Title, item.ItemID)
+
+		switch {
+		case item.QuestionItem != nil:
+			q, ok := convertGoogleQuestion(b, name, item.Title, item.Description, item.QuestionItem.Question)
+			if !ok {
+				continue
+			}
+			if choice := item.QuestionItem.Question.ChoiceQuestion; choice != nil {
+				for _, option := range choice.Options {
+					switch {
+					case option.GoToSectionID != "":
+						jumps = append(jumps, gfJump{q, option.Value, option.GoToSectionID, name})
+					case option.GoToAction == "SUBMIT_FORM":
+						addLogic(b, q, option.Value, models.EndSurveyDestination)
+					case option.GoToAction == "RESTART_FORM":
+						b.change(name, "branch to restart the form on %q was not converted", option.Value)
+					}
+				}
+			}
+			b.converted()
+
+		case item.QuestionGroupItem != nil:
+			convertGoogleGrid(b, name, item)
+
+		case item.PageBreakItem != nil:
+			sections[item.ItemID] = b.add("section", item.Title, item.Description).ID
+			b.converted()
+
+		case item.TextItem != nil:
+			b.add("section", item.Title, item.Description)
+			b.change(name, "text block converted to a section, which starts a new page")
+			b.converted()
+
+		case item.ImageItem != nil, item.VideoItem != nil:
+			b.skip(name, "images and videos are not supported")
+
+		default:
+			b.skip(name, "unknown item type")
+		}
+	}
+
+	for _, jump := range jumps {
+		sectionID, ok := sections[jump.sectionID]
+		if !ok {
+			b.change(jump.item, "branch on %q points to a missing section and was not converted", jump.option)
+			continue
+		}
+		addLogic(b, jump.question, jump.option, sectionID.String())
+	}
+
+	return &b.result, nil
+}
+
+// convertGoogleQuestion adds a single question, reporting whether it was converted
+func convertGoogleQuestion(b *builder, name, title, description string, question gfQuestion) (uuid.UUID, bool) {
+	var q *models.Question
+
+	switch {
+	case question.ChoiceQuestion != nil:
+		choice := question.ChoiceQuestion
+		questionType, ok := choiceTypes[choice.Type]
+		if !ok {
+			b.skip(name, "unsupported choice type %q", choice.Type)
+			return uuid.Nil, false
+		}
+		q = b.add(questionType, title, description)
+		q.Options = googleOptions(b, name, choice.Options)
+
+	case question.TextQuestion != nil:
+		questionType := "short"
+		if question.TextQuestion.Paragraph {
+			questionType = "long"
+		}
+		q = b.add(questionType, title, description)
+
+	case question.ScaleQuestion != nil:
+		scale := question.ScaleQuestion
+		q = b.add("rating", title, description)
+		q.MaxRating = scale.High
+		if scale.Low != 1 {
+			b.change(name, "linear scale %d-%d converted to a 1-%d rating", scale.Low, scale.High, scale.High)
+		}
+		if scale.LowLabel != "" || scale.HighLabel != "" {
+			b.change(name, "scale labels %q and %q were dropped", scale.LowLabel, scale.HighLabel)
+		}
+
+	case question.RatingQuestion != nil:
+		q = b.add("rating", title, description)
+		q.MaxRating = question.RatingQuestion.RatingScaleLevel
+
+	case question.DateQuestion != nil:
+		q = b.add("date", title, description)
+		if question.DateQuestion.IncludeTime {
+			b.change(name, "the time part of the date question was dropped")
+		}
+
+	case question.TimeQuestion != nil:
+		q = b.add("short", title, description)
+		b.change(name, "time question converted to short text")
+
+	case question.FileUploadQuestion != nil:
+		b.skip(name, "file upload questions are not supported")
+		return uuid.Nil, false
+
+	default:
+		b.skip(name, "unknown question type")
+		return uuid.Nil, false
+	}
+
+	q.Required = question.Required
+	return q.ID, true
+}
+
+// convertGoogleGrid expands a grid into one choice question per row
+func convertGoogleGrid(b *builder, name string, item gfItem) {
+	group := item.QuestionGroupItem
+	if group.Grid == nil {
+		b.skip(name, "question groups other than grids are not supported")
+		return
+	}
+
+	questionType := "single"
+	if group.Grid.Columns.Type == "CHECKBOX" {
+		questionType = "multi"
+	}
+
+	var options []string
+	for _, column := range group.Grid.Columns.Options {
+		options = append(options, column.Value)
+	}
+
+	for _, row := range group.Questions {
+		title := item.Title
+		if row.RowQuestion != nil {
+			title = item.Title + " - " + row.RowQuestion.Title
+		}
+		q := b.add(questionType, title, item.Description)
+		q.Options = append([]string{}, options...)
+		q.Required = row.Required
+	}
+
+	b.change(name, "grid expanded into %d %s-choice questions, one per row, as there is no matrix question type", len(group.Questions), questionType)
+	b.converted()
+}
+
+// choiceTypes maps Google Forms choice types to question types
+var choiceTypes = map[string]string{
+	"RADIO":     "single",
+	"CHECKBOX":  "multi",
+	"DROP_DOWN": "select",
+}
+
+func googleOptions(b *builder, name string, options []gfOption) []string {
+	values := make([]string, 0, len(options))
+	for _, option := range options {
+		if option.IsOther {
+			values = append(values, "Other")
+			b.change(name, "the free-text \"Other\" option became a plain \"Other\" option")
+			continue
+		}
+		values = append(values, option.Value)
+	}
+	return values
+}
+
+// addLogic adds a logic rule to a converted question
+func addLogic(b *builder, questionID uuid.UUID, option, destination string) {
+	for i := range b.result.Questions {
+		if b.result.Questions[i].ID == questionID {
+			b.result.Questions[i].Logic = append(b.result.Questions[i].Logic, models.LogicRule{
+				TriggerOption:         option,
+				DestinationQuestionID: destination,
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package converter
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+// LimeSurvey survey structure export (.lss). The file is a dump of database
+// tables, each a <rows> list of <row> elements with one child per column.
+// Before LimeSurvey 4, texts are stored per language on the question, group and
+// answer rows; from version 4 they are in separate *_l10ns tables.
+
+type lssDocument struct {
+	Tables []lssTable `xml:",any"`
+}
+
+type lssTable struct {
+	XMLName xml.Name
+	Rows    []lssRow `xml:"rows>row"`
+}
+
+type lssRow struct {
+	Fields []lssField `xml:",any"`
+}
+
+type lssField struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// lssRecord is a row as column name -> value
+type lssRecord map[string]string
+
+func (r lssRecord) int(column string) int {
+	n, _ := strconv.Atoi(r[column])
+	return n
+}
+
+// lssQuestion is a question with its texts in the imported language
+type lssQuestion struct {
+	fields lssRecord
+	text   string
+	help   string
+}
+
+// lssAnswer is an answer option in the imported language
+type lssAnswer struct {
+	code  string
+	text  string
+	order int
+	scale int
+}
+
+// fixedOptions are the options of LimeSurvey types with predefined answers
+var fixedOptions = map[string][]string{
+	"Y": {"Yes", "No"},
+	"G": {"Female", "Male"},
+	"C": {"Yes", "Uncertain", "No"},
+	"E": {"Increase", "Same", "Decrease"},
+}
+
+// ConvertLimeSurvey converts a LimeSurvey .lss export. language selects the
+// survey language to import; it defaults to the survey's base language.
+// Question groups become sections, array questions are expanded into one
+// question per row, and display conditions (relevance equations) are reported
+// but not converted.
+func ConvertLimeSurvey(data []byte, language string) (*Result, error) {
+	var doc lssDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid LimeSurvey XML: %w", err)
+	}
+
+	tables := make(map[string][]lssRecord)
+	for _, table := range doc.Tables {
+		for _, row := range table.Rows {
+			record := make(lssRecord, len(row.Fields))
+			for _, field := range row.Fields {
+				record[field.XMLName.Local] = strings.TrimSpace(field.Value)
+			}
+			tables[table.XMLName.Local] = append(tables[table.XMLName.Local], record)
+		}
+	}
+	if len(tables["questions"]) == 0 {
+		return nil, errors.New("not a LimeSurvey survey structure export")
+	}
+
+	if language == "" {
+		language = lssBaseLanguage(tables)
+	}
+	inLanguage := func(r lssRecord) bool {
+		return r["language"] == "" || language == "" || r["language"] == language
+	}
+
+	b := newBuilder(FormatLimeSurvey)
+	for _, settings := range tables["surveys_languagesettings"] {
+		if settings["surveyls_language"] == language || language == "" {
+			b.result.Title = settings["surveyls_title"]
+			b.result.Description = plainText(settings["surveyls_description"])
+			break
+		}
+	}
+	if b.result.Title == "" {
+		b.result.Title = "Imported LimeSurvey survey"
+	}
+
+	// Question texts, from the question rows (before 4.0) or question_l10ns
+	texts := make(map[string][2]string)
+	for _, table := range []string{"questions", "subquestions", "question_l10ns"} {
+		for _, r := range tables[table] {
+			if _, ok := r["question"]; ok && inLanguage(r) {
+				texts[r["qid"]] = [2]string{plainText(r["question"]), plainText(r["help"])}
+			}
+		}
+	}
+
+	// Questions and subquestions, once per qid
+	questions := make(map[string][]lssQuestion) // by gid
+	subquestions := make(map[string][]lssQuestion)
+	seen := make(map[string]bool)
+	for _, table := range []string{"questions", "subquestions"} {
+		for _, r := range tables[table] {
+			if seen[r["qid"]] || !inLanguage(r) {
+				continue
+			}
+			seen[r["qid"]] = true
+			q := lssQuestion{fields: r, text: texts[r["qid"]][0], help: texts[r["qid"]][1]}
+			if parent := r["parent_qid"]; parent != "" && parent != "0" {
+				subquestions[parent] = append(subquestions[parent], q)
+			} else {
+				questions[r["gid"]] = append(questions[r["gid"]], q)
+			}
+		}
+	}
+
+	answers := lssAnswers(tables, inLanguage)
+
+	// Groups in order, with their texts
+	groupTexts := make(map[string][2]string)
+	for _, table := range []string{"groups", "group_l10ns"} {
+		for _, r := range tables[table] {
+			if _, ok := r["group_name"]; ok && inLanguage(r) {
+				groupTexts[r["gid"]] = [2]string{r["group_name"], plainText(r["description"])}
+			}
+		}
+	}
+	var groups []lssRecord
+	seenGroups := make(map[string]bool)
+	for _, r := range tables["groups"] {
+		if !seenGroups[r["gid"]] && inLanguage(r) {
+			seenGroups[r["gid"]] = true
+			groups = append(groups, r)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].int("group_order") < groups[j].int("group_order") })
+	if len(groups) == 0 {
+		// Questions without group rows still get imported, in one unnamed group
+		var all []lssQuestion
+		for _, groupQuestions := range questions {
+			all = append(all, groupQuestions...)
+		}
+		questions = map[string][]lssQuestion{"": all}
+		groups = []lssRecord{{"gid": ""}}
+	}
+
+	for _, group := range groups {
+		gid := group["gid"]
+		if len(groups) > 1 || groupTexts[gid][0] != "" {
+			b.add("section", groupTexts[gid][0], groupTexts[gid][1])
+		}
+
+		groupQuestions := questions[gid]
+		sortLimeSurveyQuestions(groupQuestions)
+		for _, q := range groupQuestions {
+			subs := subquestions[q.fields["qid"]]
+			sortLimeSurveyQuestions(subs)
+			convertLimeSurveyQuestion(b, q, subs, answers[q.fields["qid"]])
+		}
+	}
+
+	return &b.result, nil
+}
+
+// sortLimeSurveyQuestions orders questions by question_order, then qid, so
+// the order does not depend on the order they were collected in
+func sortLimeSurveyQuestions(questions []lssQuestion) {
+	sort.Slice(questions, func(i, j int) bool {
+		a, b := questions[i].fields, questions[j].fields
+		if a.int("question_order") != b.int("question_order") {
+			return a.int("question_order") < b.int("question_order")
+		}
+		return a.int("qid") < b.int("qid")
+	})
+}
+
+// convertLimeSurveyQuestion converts a question with its subquestions and answers
+func convertLimeSurveyQuestion(b *builder, q lssQuestion, subs []lssQuestion, answers []lssAnswer) {
+	name := label(q.fields["title"], q.text)
+	title := label(q.text, q.fields["title"])
+	required := q.fields["mandatory"] == "Y"
+	questionType := q.fields["type"]
+
+	options := func(scale int) []string {
+		var values []string
+		for _, a := range answers {
+			if a.scale == scale {
+				values = append(values, label(a.text, a.code))
+			}
+		}
+		return values
+	}
+	withOther := func(values []string) []string {
+		if q.fields["other"] == "Y" {
+			b.change(name, "the free-text \"Other\" option became a plain \"Other\" option")
+			return append(values, "Other")
+		}
+		return values
+	}
+	// perRow adds one question per subquestion (row of an array)
+	perRow := func(rowType string, setup func(added *models.Question)) {
+		for _, row := range subs {
+			added := b.add(rowType, title+" - "+label(row.text, row.fields["title"]), q.help)
+			added.Required = required
+			setup(added)
+		}
+	}
+
+	switch questionType {
+	case "L", "!", "O":
+		added := b.add(map[string]string{"L": "single", "!": "select", "O": "single"}[questionType], title, q.help)
+		added.Required = required
+		added.Options = withOther(options(0))
+		if questionType == "O" {
+			b.change(name, "the comment field was dropped")
+		}
+
+	case "M", "P":
+		var values []string
+		for _, sub := range subs {
+			values = append(values, label(sub.text, sub.fields["title"]))
+		}
+		added := b.add("multi", title, q.help)
+		added.Required = required
+		added.Options = withOther(values)
+		if questionType == "P" {
+			b.change(name, "the comment fields were dropped")
+		}
+
+	case "Y", "G":
+		added := b.add("single", title, q.help)
+		added.Required = required
+		added.Options = append([]string{}, fixedOptions[questionType]...)
+
+	case "5":
+		added := b.add("rating", title, q.help)
+		added.Required = required
+		added.MaxRating = 5
+
+	case "S", "N":
+		added := b.add("short", title, q.help)
+		added.Required = required
+		if questionType == "N" {
+			b.change(name, "numerical question converted to short text without number validation")
+		}
+
+	case "T", "U":
+		added := b.add("long", title, q.help)
+		added.Required = required
+
+	case "D":
+		added := b.add("date", title, q.help)
+		added.Required = required
+
+	case "X":
+		b.add("section", title, q.help)
+		b.change(name, "text display converted to a section, which starts a new page")
+
+	case "Q", "K":
+		perRow("short", func(*models.Question) {})
+		if questionType == "K" {
+			b.change(name, "multiple numerical input converted to short text questions without number validation")
+		} else {
+			b.change(name, "multiple short text expanded into %d short text questions", len(subs))
+		}
+
+	case "A", "B":
+		maxRating := 5
+		if questionType == "B" {
+			maxRating = 10
+		}
+		perRow("rating", func(added *models.Question) { added.MaxRating = maxRating })
+		b.change(name, "array expanded into %d rating questions, one per row, as there is no matrix question type", len(subs))
+
+	case "C", "E", "F", "H", "1":
+		values := fixedOptions[questionType]
+		if values == nil {
+			values = options(0)
+		}
+		perRow("single", func(added *models.Question) { added.Options = append([]string{}, values...) })
+		b.change(name, "array expanded into %d single-choice questions, one per row, as there is no matrix question type", len(subs))
+		if questionType == "1" {
+			b.change(name, "only the first scale of the dual-scale array was converted")
+		}
+
+	case ":", ";":
+		b.skip(name, "arrays of numbers or texts are not supported")
+		return
+
+	case "R":
+		b.skip(name, "ranking questions are not supported")
+		return
+
+	case "|":
+		b.skip(name, "file upload questions are not supported")
+		return
+
+	case "*", "I":
+		b.skip(name, "equations and language switches are not survey questions")
+		return
+
+	default:
+		b.skip(name, "unknown question type %q", questionType)
+		return
+	}
+
+	if relevance := strings.TrimSpace(q.fields["relevance"]); relevance != "" && relevance != "1" {
+		b.change(name, "display condition %q was not converted", relevance)
+	}
+	b.converted()
+}
+
+// lssAnswers collects the answer options of each question in the imported language
+func lssAnswers(tables map[string][]lssRecord, inLanguage func(lssRecord) bool) map[string][]lssAnswer {
+	l10n := make(map[string]string)
+	for _, r := range tables["answer_l10ns"] {
+		if inLanguage(r) {
+			l10n[r["aid"]] = r["answer"]
+		}
+	}
+
+	answers := make(map[string][]lssAnswer)
+	for _, r := range tables["answers"] {
+		text, ok := r["answer"]
+		if !ok {
+			text = l10n[r["aid"]]
+		} else if !inLanguage(r) {
+			continue
+		}
+		answers[r["qid"]] = append(answers[r["qid"]], lssAnswer{
+			code:  r["code"],
+			text:  plainText(text),
+			order: r.int("sortorder"),
+			scale: r.int("scale_id"),
+		})
+	}
+
+	for qid := range answers {
+		list := answers[qid]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].order < list[j].order })
+	}
+	return answers
+}
+
+// lssBaseLanguage returns the survey's base language
+func lssBaseLanguage(tables map[string][]lssRecord) string {
+	for _, r := range tables["surveys"] {
+		if r["language"] != "" {
+			return r["language"]
+		}
+	}
+	for _, r := range tables["surveys_languagesettings"] {
+		if r["surveyls_language"] != "" {
+			return r["surveyls_language"]
+		}
+	}
+	return ""
+}
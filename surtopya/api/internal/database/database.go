@@ -33,15 +33,18 @@ func LoadConfigFromEnv() Config {
 	}
 }
 
-// Connect establishes a connection to the database
-func Connect(cfg Config) error {
-	connStr := fmt.Sprintf(
+// ConnString returns the connection string for the configuration
+func (cfg Config) ConnString() string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
+}
 
+// Connect establishes a connection to the database
+func Connect(cfg Config) error {
 	var err error
-	DB, err = sql.Open("postgres", connStr)
+	DB, err = sql.Open("postgres", cfg.ConnString())
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
 	}
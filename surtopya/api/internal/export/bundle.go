@@ -0,0 +1,105 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export formats
+const (
+	FormatCSV = "csv" // The table as CSV
+	FormatSAV = "sav" // An SPSS system file
+	FormatDTA = "dta" // A Stata dataset
+	FormatR   = "r"   // A coded CSV and an R script that labels it
+)
+
+// Formats lists the export formats
+var Formats = []string{FormatCSV, FormatSAV, FormatDTA, FormatR}
+
+// FileName returns the download name of a survey's export in a format: the
+// CSV itself, or a zip bundle for the other formats
+func FileName(surveyID uuid.UUID, format string) string {
+	if format == FormatCSV {
+		return fmt.Sprintf("responses-%s.csv", surveyID)
+	}
+	return fmt.Sprintf("responses-%s-%s.zip", surveyID, format)
+}
+
+// ContentType returns the media type of an export in a format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/zip"
+}
+
+// Write writes the table in a format: CSV as is, or a zip bundle with a
+// codebook for the other formats
+func Write(w io.Writer, format string, src Source, meta Meta) error {
+	if format == FormatCSV {
+		return WriteCSV(w, src)
+	}
+	return WriteBundle(w, format, src, meta)
+}
+
+// codebookFile is the name of the codebook in every bundle
+const codebookFile = "codebook.md"
+
+// bundleFiles returns the names of the files of a bundle
+func bundleFiles(format string) []string {
+	switch format {
+	case FormatSAV:
+		return []string{"responses.sav", codebookFile}
+	case FormatDTA:
+		return []string{"responses.dta", codebookFile}
+	case FormatR:
+		return []string{"responses.csv", "labels.R", codebookFile}
+	}
+	return []string{"responses.csv", codebookFile}
+}
+
+// WriteBundle writes a zip archive holding the table in a format along with
+// a codebook documenting its variables
+func WriteBundle(w io.Writer, format string, src Source, meta Meta) error {
+	if meta.GeneratedAt.IsZero() {
+		meta.GeneratedAt = time.Now()
+	}
+	label := meta.Survey.Title
+
+	zw := zip.NewWriter(w)
+	for _, name := range bundleFiles(format) {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: meta.GeneratedAt})
+		if err != nil {
+			return fmt.Errorf("failed to write export bundle: %w", err)
+		}
+
+		switch name {
+		case codebookFile:
+			err = writeCodebook(f, format, src, meta)
+		case "responses.sav":
+			err = WriteSAV(f, src, label)
+		case "responses.dta":
+			err = WriteDTA(f, src, label)
+		case "labels.R":
+			err = writeRScript(f, src.Header(), "responses.csv", label)
+		default:
+			if format == FormatR {
+				err = writeCodedCSV(f, src)
+			} else {
+				err = WriteCSV(f, src)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write export bundle: %w", err)
+	}
+	return nil
+}
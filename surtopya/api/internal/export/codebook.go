@@ -0,0 +1,247 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/render"
+)
+
+// Meta describes an export for its codebook
+type Meta struct {
+	Survey      *models.Survey
+	Filter      models.ReportFilter
+	GeneratedAt time.Time
+}
+
+// codebookEscaper escapes characters with a meaning in Markdown, and line
+// breaks that would end a table row
+var codebookEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`#`, `\#`, `<`, `\<`, `>`, `\>`, `|`, `\|`, "\r\n", " ", "\n", " ",
+)
+
+func mdEscape(s string) string {
+	return codebookEscaper.Replace(s)
+}
+
+// questionTypeNames describes question types in the codebook
+var questionTypeNames = map[string]string{
+	"single": "Single choice",
+	"select": "Dropdown",
+	"multi":  "Multiple choice",
+	"rating": "Rating",
+	"date":   "Date",
+	"text":   "Open text",
+	"short":  "Open text (short)",
+	"long":   "Open text (long)",
+}
+
+// columnTypeNames describes column types in the codebook
+var columnTypeNames = map[string]string{
+	TypeString:      "Text",
+	TypeNumeric:     "Number",
+	TypeCategorical: "Coded",
+	TypeBinary:      "0/1",
+	TypeDate:        "Date",
+	TypeDateTime:    "Date and time (UTC)",
+}
+
+// writeCodebook writes a Markdown codebook documenting the variables of the
+// table: each question's type, its option codes, missing-value conventions
+// and skip logic
+func writeCodebook(w io.Writer, format string, src Source, meta Meta) error {
+	var b strings.Builder
+	columns := src.Header()
+	survey := meta.Survey
+
+	fmt.Fprintf(&b, "# Codebook: %s\n\n", mdEscape(survey.Title))
+	fmt.Fprintf(&b, "- Survey ID: %s\n", survey.ID)
+	fmt.Fprintf(&b, "- Survey revision: %d\n", survey.Revision)
+	fmt.Fprintf(&b, "- Exported: %s\n", meta.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Responses: %d\n", src.Len())
+	fmt.Fprintf(&b, "- Filter: %s\n", mdEscape(describeFilter(survey, meta.Filter)))
+	fmt.Fprintf(&b, "- Files: %s\n\n", strings.Join(bundleFiles(format), ", "))
+
+	b.WriteString("## Missing values\n\n")
+	b.WriteString("Answer cells of choice, rating and coded text variables record why an answer is missing. ")
+	b.WriteString("Text and date answers are left empty whatever the reason.\n\n")
+	b.WriteString("| Reason | Meaning | SPSS and R | Stata | CSV |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	meanings := map[Missing]string{
+		MissingSkipped:    "Shown to the respondent but not answered",
+		MissingNotAsked:   "Bypassed by skip logic",
+		MissingNotReached: "The respondent stopped before reaching it",
+	}
+	for i, m := range MissingReasons {
+		fmt.Fprintf(&b, "| %s | %s | %d | .%c | empty |\n", m.Label(), meanings[m], m.Code(), 'a'+i)
+	}
+	b.WriteString("\nIn SPSS the codes are declared user-missing. ")
+	b.WriteString("The R script turns them into NA and keeps the reason in the `na_reason` attribute of each column.\n\n")
+
+	b.WriteString("## Response variables\n\n")
+	b.WriteString("| Variable | Type | Label |\n|---|---|---|\n")
+	for _, col := range columns {
+		if col.Question == nil {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", mdEscape(col.Name), columnTypeNames[col.Type], mdEscape(col.Label))
+		}
+	}
+	b.WriteString("\n")
+	for _, col := range columns {
+		if col.Question == nil && col.Type == TypeCategorical {
+			fmt.Fprintf(&b, "Codes of %s: ", mdEscape(col.Name))
+			var codes []string
+			for i, v := range col.Values {
+				codes = append(codes, fmt.Sprintf("%d = %s", i+1, mdEscape(v)))
+			}
+			b.WriteString(strings.Join(codes, ", ") + "\n\n")
+		}
+		if col.Name == "weight" && col.Question == nil {
+			b.WriteString("The weight variable holds raking weights to the survey's target margins, with a mean of 1. ")
+			b.WriteString("Use it as `WEIGHT BY weight` in SPSS, `[pweight=weight]` in Stata, or with `survey::svydesign(ids = ~1, weights = ~weight)` in R.\n\n")
+		}
+	}
+
+	b.WriteString("## Questions\n")
+	for start := 0; start < len(columns); {
+		q := columns[start].Question
+		if q == nil {
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(columns) && columns[end].Question == q {
+			end++
+		}
+		writeCodebookQuestion(&b, survey, columns[start:end])
+		start = end
+	}
+
+	b.WriteString("\n## Limits\n\n")
+	fmt.Fprintf(&b, "- Stata strings hold at most %d bytes; longer text answers are cut in the .dta file.\n", dtaMaxString)
+	fmt.Fprintf(&b, "- SPSS and Stata variable labels hold at most %d bytes and %d characters.\n", savMaxVarLabel, dtaMaxLabel)
+	b.WriteString("- Options that were removed from a question after it was answered are coded after the current options.\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write codebook: %w", err)
+	}
+	return nil
+}
+
+// writeCodebookQuestion documents a question and its variables
+func writeCodebookQuestion(b *strings.Builder, survey *models.Survey, columns []Column) {
+	first := columns[0]
+	q := first.Question
+
+	fmt.Fprintf(b, "\n### Q%d. %s\n\n", first.Number, mdEscape(q.Title))
+	if q.Description != nil && *q.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", mdEscape(*q.Description))
+	}
+	typeName := questionTypeNames[q.Type]
+	if typeName == "" {
+		typeName = q.Type
+	}
+	fmt.Fprintf(b, "- Type: %s\n", typeName)
+	fmt.Fprintf(b, "- Required: %s\n", yesNo(q.Required))
+	if q.Type == "rating" {
+		top := q.MaxRating
+		if top <= 0 {
+			top = 5
+		}
+		fmt.Fprintf(b, "- Scale: 1 to %d\n", top)
+	}
+	if q.IsScreener && q.Eligibility != nil {
+		fmt.Fprintf(b, "- Screener: %s\n", mdEscape(describeEligibility(*q.Eligibility)))
+	}
+	for _, note := range render.RoutingNotes(survey.Questions, *q) {
+		fmt.Fprintf(b, "- Logic: %s\n", mdEscape(note))
+	}
+	if len(q.Logic) == 0 {
+		b.WriteString("- Logic: none; continue to the next question\n")
+	}
+
+	b.WriteString("\n| Variable | Type | Label | Missing codes |\n|---|---|---|---|\n")
+	for _, col := range columns {
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", mdEscape(col.Name), columnTypeNames[col.Type], mdEscape(col.Label), yesNo(col.codedMissing()))
+	}
+
+	switch first.Type {
+	case TypeCategorical:
+		b.WriteString("\n| Code | Option |\n|---|---|\n")
+		for i, v := range first.Values {
+			fmt.Fprintf(b, "| %d | %s |\n", i+1, mdEscape(v))
+		}
+	}
+	if len(columns) > 1 || first.Type == TypeBinary {
+		b.WriteString("\n0/1 variables hold 1 if the option or code applies to the answer and 0 if it does not.\n")
+	}
+}
+
+// describeFilter summarizes the filter of an export
+func describeFilter(survey *models.Survey, filter models.ReportFilter) string {
+	var parts []string
+	if len(filter.Statuses) > 0 {
+		parts = append(parts, "status "+strings.Join(filter.Statuses, " or "))
+	} else {
+		parts = append(parts, "all statuses")
+	}
+	if filter.From != nil {
+		parts = append(parts, "from "+filter.From.UTC().Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		parts = append(parts, "before "+filter.To.UTC().Format(time.RFC3339))
+	}
+	for _, cond := range filter.Segment {
+		title := cond.QuestionID.String()
+		for _, q := range survey.Questions {
+			if q.ID == cond.QuestionID {
+				title = q.Title
+				break
+			}
+		}
+		condition := fmt.Sprintf("%q %s", title, strings.ReplaceAll(cond.Operator, "_", " "))
+		if len(cond.Values) > 0 {
+			condition += " " + strings.Join(cond.Values, ", ")
+		}
+		if cond.Min != nil || cond.Max != nil {
+			condition += fmt.Sprintf(" %s to %s", formatBound(cond.Min), formatBound(cond.Max))
+		}
+		parts = append(parts, condition)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeEligibility summarizes the answers that keep a respondent eligible
+func describeEligibility(rule models.ScreenerRule) string {
+	if len(rule.QualifyingOptions) > 0 {
+		return "eligible if the answer includes " + strings.Join(rule.QualifyingOptions, ", ")
+	}
+	var low, high *float64
+	if rule.MinRating != nil {
+		v := float64(*rule.MinRating)
+		low = &v
+	}
+	if rule.MaxRating != nil {
+		v := float64(*rule.MaxRating)
+		high = &v
+	}
+	return fmt.Sprintf("eligible if the rating is %s to %s", formatBound(low), formatBound(high))
+}
+
+func formatBound(v *float64) string {
+	if v == nil {
+		return "any"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
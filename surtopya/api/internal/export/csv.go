@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the table as UTF-8 CSV with a header row of column names.
+// A byte order mark leads the file so spreadsheet apps detect UTF-8.
+func WriteCSV(w io.Writer, src Source) error {
+	return writeCSV(w, src, func(j int, cell string, _ Missing) string { return cell })
+}
+
+// writeCodedCSV writes the table as CSV with categorical columns as their
+// 1-based codes and missing answers of coded columns as -1, -2 or -3, the
+// layout the R labels script reads
+func writeCodedCSV(w io.Writer, src Source) error {
+	columns := src.Header()
+	codes := make([]map[string]int, len(columns))
+	for j, col := range columns {
+		if col.Type == TypeCategorical {
+			codes[j] = categoryCodes(col)
+		}
+	}
+
+	return writeCSV(w, src, func(j int, cell string, missing Missing) string {
+		if cell == "" {
+			if missing != NotMissing && columns[j].codedMissing() {
+				return strconv.Itoa(missing.Code())
+			}
+			return ""
+		}
+		if codes[j] != nil {
+			if code, ok := codes[j][cell]; ok {
+				return strconv.Itoa(code)
+			}
+		}
+		return cell
+	})
+}
+
+// writeCSV writes a header row of column names and each row, with each cell
+// passed through format
+func writeCSV(w io.Writer, src Source, format func(j int, cell string, missing Missing) string) error {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	cw := csv.NewWriter(w)
+	columns := src.Header()
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	cw.Write(header)
+	record := make([]string, len(columns))
+	err := src.Scan(func(row []string, missing []Missing) error {
+		for j, cell := range row {
+			record[j] = format(j, cell, missing[j])
+		}
+		return cw.Write(record)
+	})
+	cw.Flush()
+
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the Stata 14 (format 118) dataset format
+const (
+	dtaMaxString    = 2045 // Widest fixed-width string; longer text is cut
+	dtaMaxVariables = 32767
+	dtaMaxLabel     = 80 // Characters of a variable or dataset label
+	dtaNameBytes    = 129
+	dtaFormatBytes  = 57
+	dtaLabelBytes   = 321
+)
+
+// Stata variable types
+const (
+	dtaTypeDouble = 65526
+	dtaTypeLong   = 65528
+)
+
+// Stata missing values: . and the extended missing values .a, .b, ... follow it
+const (
+	dtaMissingLong   = 2147483621
+	dtaMissingDouble = 0x7fe0000000000000
+	dtaExtendedStep  = 0x0000010000000000
+)
+
+// dtaEpoch is Stata's date origin, 1 January 1960
+var dtaEpoch = time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// dtaVariable is a table column laid out as a Stata variable
+type dtaVariable struct {
+	typ    uint16
+	width  int // Bytes per value
+	format string
+	label  string // Name of its value label, if any
+	codes  map[string]int
+}
+
+// WriteDTA writes the table as a Stata 14 dataset (format 118, UTF-8).
+// Categorical columns hold their 1-based codes with value labels, 0/1
+// columns are labelled No and Yes, and answer columns with coded missing
+// values hold .a (skipped), .b (not asked) or .c (not reached). Text longer
+// than 2045 bytes is cut. Dates are %td and times %tc values.
+func WriteDTA(w io.Writer, src Source, label string) error {
+	columns := src.Header()
+	if len(columns) > dtaMaxVariables {
+		return fmt.Errorf("failed to write Stata file: more than %d variables", dtaMaxVariables)
+	}
+	sizes, err := measure(src)
+	if err != nil {
+		return fmt.Errorf("failed to write Stata file: %w", err)
+	}
+	variables := dtaLayout(columns, sizes)
+	rowWidth := 0
+	for _, v := range variables {
+		rowWidth += v.width
+	}
+
+	// Everything up to the data, with the map filled in below
+	var head bytes.Buffer
+	offsets := make([]uint64, 14)
+	head.WriteString("<stata_dta><header><release>118</release><byteorder>LSF</byteorder><K>")
+	writeLE(&head, uint16(len(columns)))
+	head.WriteString("</K><N>")
+	writeLE(&head, uint64(src.Len()))
+	head.WriteString("</N><label>")
+	datasetLabel := truncateRunes(label, dtaMaxLabel)
+	writeLE(&head, uint16(len(datasetLabel)))
+	head.WriteString(datasetLabel)
+	head.WriteString("</label><timestamp>")
+	timestamp := time.Now().Format("02 Jan 2006 15:04")
+	head.WriteByte(byte(len(timestamp)))
+	head.WriteString(timestamp)
+	head.WriteString("</timestamp></header>")
+
+	offsets[1] = uint64(head.Len())
+	head.WriteString("<map>")
+	mapStart := head.Len()
+	head.Write(make([]byte, 8*len(offsets)))
+	head.WriteString("</map>")
+
+	offsets[2] = uint64(head.Len())
+	head.WriteString("<variable_types>")
+	for _, v := range variables {
+		writeLE(&head, v.typ)
+	}
+	head.WriteString("</variable_types>")
+
+	offsets[3] = uint64(head.Len())
+	head.WriteString("<varnames>")
+	for _, col := range columns {
+		writeFixed(&head, col.Name, dtaNameBytes)
+	}
+	head.WriteString("</varnames>")
+
+	offsets[4] = uint64(head.Len())
+	head.WriteString("<sortlist>")
+	head.Write(make([]byte, 2*(len(columns)+1)))
+	head.WriteString("</sortlist>")
+
+	offsets[5] = uint64(head.Len())
+	head.WriteString("<formats>")
+	for _, v := range variables {
+		writeFixed(&head, v.format, dtaFormatBytes)
+	}
+	head.WriteString("</formats>")
+
+	offsets[6] = uint64(head.Len())
+	head.WriteString("<value_label_names>")
+	for _, v := range variables {
+		writeFixed(&head, v.label, dtaNameBytes)
+	}
+	head.WriteString("</value_label_names>")
+
+	offsets[7] = uint64(head.Len())
+	head.WriteString("<variable_labels>")
+	for _, col := range columns {
+		writeFixed(&head, truncateRunes(col.Label, dtaMaxLabel), dtaLabelBytes)
+	}
+	head.WriteString("</variable_labels>")
+
+	offsets[8] = uint64(head.Len())
+	head.WriteString("<characteristics></characteristics>")
+
+	offsets[9] = uint64(head.Len())
+	head.WriteString("<data>")
+
+	// Everything after the data
+	var tail bytes.Buffer
+	tail.WriteString("</data>")
+	offsets[10] = uint64(head.Len()) + uint64(rowWidth)*uint64(src.Len()) + uint64(tail.Len())
+	tail.WriteString("<strls></strls>")
+	offsets[11] = offsets[10] + uint64(len("<strls></strls>"))
+	tail.WriteString("<value_labels>")
+	for j, v := range variables {
+		if v.label != "" {
+			writeValueLabel(&tail, v.label, valueLabels(columns[j]))
+		}
+	}
+	tail.WriteString("</value_labels>")
+	offsets[12] = offsets[10] + uint64(tail.Len()) - uint64(len("</data>"))
+	tail.WriteString("</stata_dta>")
+	offsets[13] = offsets[12] + uint64(len("</stata_dta>"))
+
+	data := head.Bytes()
+	for i, offset := range offsets {
+		binary.LittleEndian.PutUint64(data[mapStart+8*i:], offset)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(data)
+	row := make([]byte, rowWidth)
+	rows := 0
+	err = src.Scan(func(cells []string, missing []Missing) error {
+		pos := 0
+		for j, v := range variables {
+			dtaValue(row[pos:pos+v.width], columns[j], v, cells[j], missing[j])
+			pos += v.width
+		}
+		rows++
+		_, err := bw.Write(row)
+		return err
+	})
+	if err == nil && rows != src.Len() {
+		err = fmt.Errorf("read %d rows of %d", rows, src.Len())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write Stata file: %w", err)
+	}
+	bw.Write(tail.Bytes())
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write Stata file: %w", err)
+	}
+	return nil
+}
+
+// dtaLayout chooses the storage type, format and value label of each column
+func dtaLayout(columns []Column, sizes []columnSize) []dtaVariable {
+	variables := make([]dtaVariable, len(columns))
+	for j, col := range columns {
+		var v dtaVariable
+		switch col.Type {
+		case TypeString:
+			width := max(1, min(sizes[j].width, dtaMaxString))
+			v = dtaVariable{typ: uint16(width), width: width, format: "%-" + strconv.Itoa(min(width, 100)) + "s"}
+		case TypeDate:
+			v = dtaVariable{typ: dtaTypeLong, width: 4, format: "%td"}
+		case TypeDateTime:
+			v = dtaVariable{typ: dtaTypeDouble, width: 8, format: "%tc"}
+		case TypeCategorical, TypeBinary:
+			v = dtaVariable{typ: dtaTypeLong, width: 4, format: "%8.0g", label: col.Name}
+			if col.Type == TypeCategorical {
+				v.codes = categoryCodes(col)
+			}
+		default:
+			v = dtaVariable{typ: dtaTypeDouble, width: 8, format: "%10.0g"}
+			if col.codedMissing() {
+				v.label = col.Name
+			}
+		}
+		variables[j] = v
+	}
+	return variables
+}
+
+// dtaValue encodes a cell into buf
+func dtaValue(buf []byte, col Column, v dtaVariable, cell string, missing Missing) {
+	if col.Type == TypeString {
+		clear(buf)
+		copy(buf, truncateUTF8(cell, dtaMaxString))
+		return
+	}
+
+	extended := 0 // . or, with coded missing values, the reason's .a, .b or .c
+	if cell == "" && col.codedMissing() {
+		extended = int(missing)
+	}
+
+	switch v.typ {
+	case dtaTypeLong:
+		value := int32(dtaMissingLong + extended)
+		switch col.Type {
+		case TypeDate:
+			if d, err := time.Parse("2006-01-02", cell); err == nil {
+				value = int32((d.Unix() - dtaEpoch.Unix()) / 86400)
+			}
+		case TypeCategorical:
+			if code, ok := v.codes[cell]; ok {
+				value = int32(code)
+			}
+		default:
+			if n, err := strconv.Atoi(cell); err == nil {
+				value = int32(n)
+			}
+		}
+		binary.LittleEndian.PutUint32(buf, uint32(value))
+
+	case dtaTypeDouble:
+		value := math.Float64frombits(dtaMissingDouble + uint64(extended)*dtaExtendedStep)
+		if col.Type == TypeDateTime {
+			if t, err := time.Parse(time.RFC3339, cell); err == nil {
+				value = float64(t.UnixMilli() - dtaEpoch.UnixMilli())
+			}
+		} else if n, err := strconv.ParseFloat(cell, 64); err == nil {
+			value = n
+		}
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(value))
+	}
+}
+
+// writeValueLabel writes a value label table; extended missing codes are
+// stored as the matching long missing values
+func writeValueLabel(b *bytes.Buffer, name string, labels []valueLabel) {
+	// Missing codes -1, -2 and -3 become .a, .b and .c, which sort after
+	// every other value
+	values := make([]int32, len(labels))
+	texts := make([]string, len(labels))
+	order := make([]int, len(labels))
+	for i, l := range labels {
+		values[i] = int32(l.value)
+		if l.value < 0 {
+			values[i] = int32(dtaMissingLong - int(l.value))
+		}
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+	sorted := make([]int32, len(order))
+	for i, k := range order {
+		sorted[i] = values[k]
+		texts[i] = labels[k].label
+	}
+	values = sorted
+
+	var txt bytes.Buffer
+	offsets := make([]uint32, len(texts))
+	for i, text := range texts {
+		offsets[i] = uint32(txt.Len())
+		txt.WriteString(truncateUTF8(text, 32000))
+		txt.WriteByte(0)
+	}
+
+	b.WriteString("<lbl>")
+	writeLE(b, uint32(8+8*len(values)+txt.Len()))
+	writeFixed(b, name, dtaNameBytes)
+	b.Write(make([]byte, 3))
+	writeLE(b, uint32(len(values)))
+	writeLE(b, uint32(txt.Len()))
+	writeLE(b, offsets)
+	writeLE(b, values)
+	b.Write(txt.Bytes())
+	b.WriteString("</lbl>")
+}
+
+// writeLE writes fixed-size data little-endian
+func writeLE(b *bytes.Buffer, data interface{}) {
+	binary.Write(b, binary.LittleEndian, data)
+}
+
+// writeFixed writes s null-padded to n bytes, keeping room for a final null
+func writeFixed(b *bytes.Buffer, s string, n int) {
+	buf := make([]byte, n)
+	copy(buf, truncateUTF8(s, n-1))
+	b.Write(buf)
+}
+
+// truncateRunes shortens s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
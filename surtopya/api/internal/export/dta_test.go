@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dtaSection returns the n fixed-width fields of a section, nulls trimmed
+func dtaSection(t *testing.T, file []byte, tag string, n, width int) []string {
+	t.Helper()
+	start := bytes.Index(file, []byte("<"+tag+">"))
+	if start < 0 {
+		t.Fatalf("no <%s> section", tag)
+	}
+	start += len(tag) + 2
+	body := file[start : start+n*width]
+	if !bytes.HasPrefix(file[start+n*width:], []byte("</"+tag+">")) {
+		t.Fatalf("<%s> is not %d fields of %d bytes", tag, n, width)
+	}
+	fields := make([]string, n)
+	for i := range fields {
+		fields[i] = strings.TrimRight(string(body[i*width:(i+1)*width]), "\x00")
+	}
+	return fields
+}
+
+func TestWriteDTA(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDTA(&buf, sampleTable(), "Coffee habits"); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+
+	header := "<stata_dta><header><release>118</release><byteorder>LSF</byteorder><K>"
+	if !bytes.HasPrefix(file, []byte(header)) {
+		t.Fatalf("file starts %q, want %q", file[:min(len(file), len(header))], header)
+	}
+	rest := file[len(header):]
+	if k := binary.LittleEndian.Uint16(rest); k != 3 {
+		t.Errorf("K = %d, want 3", k)
+	}
+	rest = rest[2:]
+	if !bytes.HasPrefix(rest, []byte("</K><N>")) {
+		t.Fatalf("no N after K")
+	}
+	rest = rest[len("</K><N>"):]
+	if n := binary.LittleEndian.Uint64(rest); n != 2 {
+		t.Errorf("N = %d, want 2", n)
+	}
+	rest = rest[8:]
+	if !bytes.HasPrefix(rest, []byte("</N><label>")) {
+		t.Fatalf("no label after N")
+	}
+	rest = rest[len("</N><label>"):]
+	length := binary.LittleEndian.Uint16(rest)
+	if label := string(rest[2 : 2+length]); label != "Coffee habits" {
+		t.Errorf("dataset label = %q", label)
+	}
+	if !bytes.HasSuffix(file, []byte("</stata_dta>")) {
+		t.Error("file does not end with </stata_dta>")
+	}
+
+	// The map points at each section, then at the end of the file
+	mapStart := bytes.Index(file, []byte("<map>")) + len("<map>")
+	sections := []string{"<stata_dta>", "<map>", "<variable_types>", "<varnames>", "<sortlist>", "<formats>",
+		"<value_label_names>", "<variable_labels>", "<characteristics>", "<data>", "<strls>", "<value_labels>", "</stata_dta>"}
+	for i := range len(sections) + 1 {
+		want := len(file)
+		if i < len(sections) {
+			want = bytes.LastIndex(file, []byte(sections[i]))
+		}
+		if offset := binary.LittleEndian.Uint64(file[mapStart+8*i:]); int(offset) != want {
+			t.Errorf("map entry %d = %d, want %d", i, offset, want)
+		}
+	}
+
+	tests := []struct {
+		section string
+		width   int
+		want    []string
+	}{
+		{"varnames", dtaNameBytes, []string{"response_id", "q1", "satisfaction_score"}},
+		{"formats", dtaFormatBytes, []string{"%-2s", "%8.0g", "%10.0g"}},
+		{"value_label_names", dtaNameBytes, []string{"", "q1", ""}},
+		{"variable_labels", dtaLabelBytes, []string{"Response ID", "最喜歡的顏色", "Satisfaction"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.section, func(t *testing.T) {
+			if got := dtaSection(t, file, tt.section, 3, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	start := bytes.Index(file, []byte("<variable_types>")) + len("<variable_types>")
+	var types []uint16
+	for i := range 3 {
+		types = append(types, binary.LittleEndian.Uint16(file[start+2*i:]))
+	}
+	if want := []uint16{2, dtaTypeLong, dtaTypeDouble}; !reflect.DeepEqual(types, want) {
+		t.Errorf("variable types = %v, want %v", types, want)
+	}
+}
+
+func TestWriteDTALongLabel(t *testing.T) {
+	table := sampleTable()
+	table.Columns[1].Label = strings.Repeat("顏", 100)
+
+	var buf bytes.Buffer
+	if err := WriteDTA(&buf, table, "Coffee habits"); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := dtaSection(t, buf.Bytes(), "variable_labels", 3, dtaLabelBytes)
+	if want := strings.Repeat("顏", dtaMaxLabel); labels[1] != want {
+		t.Errorf("label = %q, want %d characters", labels[1], dtaMaxLabel)
+	}
+}
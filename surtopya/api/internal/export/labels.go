@@ -0,0 +1,42 @@
+package export
+
+// valueLabel names a numeric code of a column
+type valueLabel struct {
+	value float64
+	label string
+}
+
+// codedMissing reports whether the column writes missing answers as codes
+// for their reason. Text and date answers are left empty instead.
+func (c Column) codedMissing() bool {
+	if c.Question == nil {
+		return false
+	}
+	switch c.Type {
+	case TypeCategorical, TypeBinary, TypeNumeric:
+		return true
+	}
+	return false
+}
+
+// valueLabels returns the labelled codes of a column in ascending order:
+// missing-value codes, then categories or No and Yes
+func valueLabels(c Column) []valueLabel {
+	var labels []valueLabel
+	if c.codedMissing() {
+		for i := len(MissingReasons) - 1; i >= 0; i-- {
+			m := MissingReasons[i]
+			labels = append(labels, valueLabel{float64(m.Code()), m.Label()})
+		}
+	}
+
+	switch c.Type {
+	case TypeCategorical:
+		for i, v := range c.Values {
+			labels = append(labels, valueLabel{float64(i + 1), v})
+		}
+	case TypeBinary:
+		labels = append(labels, valueLabel{0, "No"}, valueLabel{1, "Yes"})
+	}
+	return labels
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// rEscaper escapes text inside a double-quoted R string
+var rEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func rString(s string) string {
+	return `"` + rEscaper.Replace(s) + `"`
+}
+
+// rPreamble reads the coded CSV and defines the helpers the per-column calls use
+const rPreamble = `responses <- read.csv(data_file, fileEncoding = "UTF-8-BOM", na.strings = "",
+                      colClasses = "character", check.names = FALSE)
+
+missing_codes <- c("-1" = "Skipped", "-2" = "Not asked", "-3" = "Not reached")
+
+# Turns the missing-value codes of a column into NA, keeping the reason of
+# each missing answer in the "na_reason" attribute
+set_missing <- function(x) {
+  reason <- factor(unname(missing_codes[x]), levels = unname(missing_codes))
+  x[!is.na(reason)] <- NA
+  attr(x, "na_reason") <- reason
+  x
+}
+
+set_factor <- function(x, levels, labels) {
+  reason <- attr(x, "na_reason")
+  x <- factor(as.integer(x), levels = levels, labels = labels)
+  attr(x, "na_reason") <- reason
+  x
+}
+
+set_numeric <- function(x) {
+  reason <- attr(x, "na_reason")
+  x <- as.numeric(x)
+  attr(x, "na_reason") <- reason
+  x
+}
+
+`
+
+// writeRScript writes an R script that loads the coded CSV written by
+// writeCodedCSV in base R, turning coded columns into factors, dates into
+// Date and POSIXct values and missing-value codes into NA with their reason
+func writeRScript(w io.Writer, columns []Column, dataFile, title string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Loads %s\n", strings.ReplaceAll(title, "\n", " "))
+	b.WriteString("# Run from the folder holding the data file, e.g. source(\"labels.R\")\n\n")
+	fmt.Fprintf(&b, "data_file <- %s\n", rString(dataFile))
+	b.WriteString(rPreamble)
+
+	for _, col := range columns {
+		ref := "responses[[" + rString(col.Name) + "]]"
+		if col.codedMissing() {
+			fmt.Fprintf(&b, "%s <- set_missing(%s)\n", ref, ref)
+		}
+
+		switch col.Type {
+		case TypeCategorical, TypeBinary:
+			var levels, labels []string
+			for _, l := range valueLabels(col) {
+				if l.value < 0 {
+					continue
+				}
+				levels = append(levels, strconv.FormatFloat(l.value, 'f', -1, 64))
+				labels = append(labels, rString(l.label))
+			}
+			fmt.Fprintf(&b, "%s <- set_factor(%s, c(%s), c(%s))\n", ref, ref, strings.Join(levels, ", "), strings.Join(labels, ", "))
+		case TypeNumeric:
+			if col.codedMissing() {
+				fmt.Fprintf(&b, "%s <- set_numeric(%s)\n", ref, ref)
+			} else {
+				fmt.Fprintf(&b, "%s <- as.numeric(%s)\n", ref, ref)
+			}
+		case TypeDate:
+			fmt.Fprintf(&b, "%s <- as.Date(%s)\n", ref, ref)
+		case TypeDateTime:
+			fmt.Fprintf(&b, "%s <- as.POSIXct(%s, format = \"%%Y-%%m-%%dT%%H:%%M:%%SZ\", tz = \"UTC\")\n", ref, ref)
+		}
+		fmt.Fprintf(&b, "attr(%s, \"label\") <- %s\n", ref, rString(col.Label))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write R script: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the SPSS system file format
+const (
+	savMaxString      = 32767 // Widest string variable
+	savSegmentWidth   = 255   // Width of each segment of a very long string but the last
+	savSegmentLength  = 252   // Width of a very long string per segment
+	savMaxValueLabel  = 120
+	savMaxVarLabel    = 255
+	savMaxFileLabel   = 64
+	savShortNameBytes = 8
+)
+
+// Special values of the SPSS system file format
+var (
+	savSysmis  = -math.MaxFloat64
+	savHighest = math.MaxFloat64
+	savLowest  = math.Nextafter(-math.MaxFloat64, 0)
+	savEpoch   = time.Date(1582, 10, 14, 0, 0, 0, 0, time.UTC).Unix() // Dates count seconds from the Gregorian calendar's start
+)
+
+// Print format types
+const (
+	savFormatA        = 1
+	savFormatF        = 5
+	savFormatDate     = 20
+	savFormatDateTime = 22
+)
+
+// savVariable is a table column laid out as SPSS variables. Strings wider
+// than 255 bytes are split into segments, each written as a variable.
+type savVariable struct {
+	column   int
+	name     string   // Short name of the first segment
+	segments []string // Short names of the segments of a string
+	widths   []int    // Widths of the segments of a string; nil for numbers
+	index    int      // 1-based position of the first 8-byte element in a case
+	format   int32
+	codes    map[string]int // Codes of a categorical column
+}
+
+// WriteSAV writes the table as an uncompressed SPSS system file with UTF-8
+// text. Categorical columns hold their 1-based codes with value labels,
+// 0/1 columns are labelled No and Yes, and answer columns with coded missing
+// values declare -1, -2 and -3 as user-missing. Dates are SPSS dates.
+func WriteSAV(w io.Writer, src Source, label string) error {
+	columns := src.Header()
+	sizes, err := measure(src)
+	if err != nil {
+		return fmt.Errorf("failed to write SPSS file: %w", err)
+	}
+	variables, elements := savLayout(columns, sizes)
+
+	sw := &savWriter{w: bufio.NewWriter(w)}
+	now := time.Now()
+	sw.bytes([]byte("$FL2"), 4)
+	sw.bytes([]byte("@(#) SPSS DATA FILE Surtopya"), 60)
+	sw.int32(2) // Layout code
+	sw.int32(int32(elements))
+	sw.int32(0) // Not compressed
+	sw.int32(0) // No weight variable
+	sw.int32(int32(src.Len()))
+	sw.float64(100) // Compression bias
+	sw.bytes([]byte(now.Format("02 Jan 06")), 9)
+	sw.bytes([]byte(now.Format("15:04:05")), 8)
+	sw.bytes([]byte(truncateUTF8(label, savMaxFileLabel)), savMaxFileLabel)
+	sw.bytes(nil, 3)
+
+	for _, v := range variables {
+		col := columns[v.column]
+		if v.widths == nil {
+			sw.variable(0, v.name, v.format, truncateUTF8(col.Label, savMaxVarLabel), col.codedMissing())
+			continue
+		}
+		for s, width := range v.widths {
+			variableLabel := ""
+			if s == 0 {
+				variableLabel = truncateUTF8(col.Label, savMaxVarLabel)
+			}
+			sw.variable(width, v.segments[s], savFormatA<<16|int32(width)<<8, variableLabel, false)
+			for e := 1; e < (width+7)/8; e++ {
+				sw.variable(-1, "", 0, "", false)
+			}
+		}
+	}
+
+	for _, v := range variables {
+		col := columns[v.column]
+		labels := valueLabels(col)
+		if len(labels) == 0 {
+			continue
+		}
+		sw.int32(3)
+		sw.int32(int32(len(labels)))
+		for _, l := range labels {
+			sw.float64(l.value)
+			text := truncateUTF8(l.label, savMaxValueLabel)
+			sw.bytes([]byte{byte(len(text))}, 1)
+			sw.bytes([]byte(text), (len(text)+1+7)/8*8-1)
+		}
+		sw.int32(4)
+		sw.int32(1)
+		sw.int32(int32(v.index))
+	}
+
+	// Machine integer and floating point info, declaring UTF-8
+	sw.extension(3, 4, []int32{20, 0, 0, -1, 1, 1, 2, 65001})
+	sw.int32(7)
+	sw.int32(4)
+	sw.int32(8)
+	sw.int32(3)
+	sw.float64(savSysmis)
+	sw.float64(savHighest)
+	sw.float64(savLowest)
+
+	var longNames, veryLong []string
+	for _, v := range variables {
+		longNames = append(longNames, v.name+"="+columns[v.column].Name)
+		if len(v.widths) > 1 {
+			veryLong = append(veryLong, fmt.Sprintf("%s=%05d\x00\t", v.name, v.width()))
+		}
+	}
+	sw.text(13, strings.Join(longNames, "\t"))
+	if len(veryLong) > 0 {
+		sw.text(14, strings.Join(veryLong, ""))
+	}
+	sw.text(20, "UTF-8")
+
+	sw.int32(999)
+	sw.int32(0)
+
+	rows := 0
+	err = src.Scan(func(row []string, missing []Missing) error {
+		rows++
+		for _, v := range variables {
+			col := columns[v.column]
+			if v.widths == nil {
+				sw.float64(savNumber(col, v.codes, row[v.column], missing[v.column]))
+				continue
+			}
+			value := []byte(truncateUTF8(row[v.column], savMaxString))
+			for s, width := range v.widths {
+				start := s * savSegmentWidth
+				var chunk []byte
+				if start < len(value) {
+					chunk = value[start:min(start+savSegmentWidth, len(value))]
+				}
+				sw.padded(chunk, (width+7)/8*8)
+			}
+		}
+		return sw.err
+	})
+	if err == nil && rows != src.Len() {
+		err = fmt.Errorf("read %d rows of %d", rows, src.Len())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write SPSS file: %w", err)
+	}
+	if sw.err != nil {
+		return fmt.Errorf("failed to write SPSS file: %w", sw.err)
+	}
+	if err := sw.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write SPSS file: %w", err)
+	}
+	return nil
+}
+
+// savLayout assigns short names, widths and formats to the columns and
+// returns the number of 8-byte elements in a case
+func savLayout(columns []Column, sizes []columnSize) ([]savVariable, int) {
+	names := newShortNames()
+	variables := make([]savVariable, len(columns))
+	elements := 0
+	for j, col := range columns {
+		v := savVariable{column: j, name: names.add(col.Name), index: elements + 1}
+		switch col.Type {
+		case TypeString:
+			width := max(1, min(sizes[j].width, savMaxString))
+			v.widths = []int{width}
+			v.segments = []string{v.name}
+			if width > savSegmentWidth {
+				n := (width + savSegmentLength - 1) / savSegmentLength
+				v.widths = make([]int, n)
+				for s := range v.widths {
+					v.widths[s] = savSegmentWidth
+				}
+				v.widths[n-1] = width - (n-1)*savSegmentLength
+				for s := 1; s < n; s++ {
+					v.segments = append(v.segments, names.add(v.name+strconv.Itoa(s)))
+				}
+			}
+			for _, width := range v.widths {
+				elements += (width + 7) / 8
+			}
+		case TypeDate:
+			v.format = savFormatDate<<16 | 11<<8
+			elements++
+		case TypeDateTime:
+			v.format = savFormatDateTime<<16 | 20<<8
+			elements++
+		default:
+			if col.Type == TypeCategorical {
+				v.codes = categoryCodes(col)
+			}
+			decimals := sizes[j].decimals
+			v.format = savFormatF<<16 | int32(8+decimals)<<8 | int32(decimals)
+			elements++
+		}
+		variables[j] = v
+	}
+	return variables, elements
+}
+
+// width returns the full width of a string variable
+func (v savVariable) width() int {
+	if len(v.widths) <= 1 {
+		return v.widths[0]
+	}
+	return (len(v.widths)-1)*savSegmentLength + v.widths[len(v.widths)-1]
+}
+
+// savNumber returns the value of a numeric cell
+func savNumber(col Column, codes map[string]int, cell string, missing Missing) float64 {
+	if cell == "" {
+		if missing != NotMissing && col.codedMissing() {
+			return float64(missing.Code())
+		}
+		return savSysmis
+	}
+
+	switch col.Type {
+	case TypeCategorical:
+		if code, ok := codes[cell]; ok {
+			return float64(code)
+		}
+	case TypeDate:
+		if t, err := time.Parse("2006-01-02", cell); err == nil {
+			return float64(t.Unix() - savEpoch)
+		}
+	case TypeDateTime:
+		if t, err := time.Parse(time.RFC3339, cell); err == nil {
+			return float64(t.Unix() - savEpoch)
+		}
+	default:
+		if v, err := strconv.ParseFloat(cell, 64); err == nil {
+			return v
+		}
+	}
+	return savSysmis
+}
+
+// savWriter writes little-endian SPSS records, keeping the first error
+type savWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *savWriter) write(data interface{}) {
+	if sw.err == nil {
+		sw.err = binary.Write(sw.w, binary.LittleEndian, data)
+	}
+}
+
+func (sw *savWriter) int32(v int32)     { sw.write(v) }
+func (sw *savWriter) float64(v float64) { sw.write(v) }
+
+// bytes writes b padded with zeros or truncated to n bytes
+func (sw *savWriter) bytes(b []byte, n int) {
+	buf := make([]byte, n)
+	copy(buf, b)
+	sw.write(buf)
+}
+
+// padded writes b padded with spaces to n bytes
+func (sw *savWriter) padded(b []byte, n int) {
+	buf := make([]byte, n)
+	copy(buf, b)
+	for i := len(b); i < n; i++ {
+		buf[i] = ' '
+	}
+	sw.write(buf)
+}
+
+// variable writes a variable record; width is 0 for numbers, the width of a
+// string, or -1 for the continuation of a string
+func (sw *savWriter) variable(width int, name string, format int32, label string, missing bool) {
+	sw.int32(2)
+	sw.int32(int32(width))
+	sw.int32(boolInt32(label != ""))
+	if missing {
+		sw.int32(int32(len(MissingReasons)))
+	} else {
+		sw.int32(0)
+	}
+	sw.int32(format)
+	sw.int32(format)
+	sw.padded([]byte(name), savShortNameBytes)
+	if label != "" {
+		sw.int32(int32(len(label)))
+		sw.bytes([]byte(label), (len(label)+3)/4*4)
+	}
+	if missing {
+		for _, m := range MissingReasons {
+			sw.float64(float64(m.Code()))
+		}
+	}
+}
+
+// extension writes a record of 4-byte integers
+func (sw *savWriter) extension(subtype int32, size int32, data []int32) {
+	sw.int32(7)
+	sw.int32(subtype)
+	sw.int32(size)
+	sw.int32(int32(len(data)))
+	sw.write(data)
+}
+
+// text writes a record of text
+func (sw *savWriter) text(subtype int32, text string) {
+	sw.int32(7)
+	sw.int32(subtype)
+	sw.int32(1)
+	sw.int32(int32(len(text)))
+	sw.write([]byte(text))
+}
+
+func boolInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// shortNames assigns the unique 8-byte upper-case names SPSS files carry
+// besides the long variable names
+type shortNames map[string]bool
+
+func newShortNames() shortNames {
+	return make(shortNames)
+}
+
+func (s shortNames) add(name string) string {
+	base := strings.ToUpper(name)
+	if len(base) > savShortNameBytes {
+		base = base[:savShortNameBytes]
+	}
+	candidate := base
+	for n := 1; s[candidate]; n++ {
+		suffix := strconv.Itoa(n)
+		candidate = base[:min(len(base), savShortNameBytes-len(suffix))] + suffix
+	}
+	s[candidate] = true
+	return candidate
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,233 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+// sampleTable has a string column, a categorical answer with coded missing
+// values and a UTF-8 label, and a numeric column whose name is longer than
+// an SPSS short name
+func sampleTable() *Table {
+	return &Table{
+		Columns: []Column{
+			{Name: "response_id", Label: "Response ID", Type: TypeString},
+			{Name: "q1", Label: "最喜歡的顏色", Type: TypeCategorical, Values: []string{"Red", "Blue"}, Question: &models.Question{}},
+			{Name: "satisfaction_score", Label: "Satisfaction", Type: TypeNumeric},
+		},
+		Rows: [][]string{
+			{"r1", "Blue", "4.5"},
+			{"r2", "", ""},
+		},
+		Missing: [][]Missing{
+			{NotMissing, NotMissing, NotMissing},
+			{NotMissing, MissingSkipped, NotMissing},
+		},
+	}
+}
+
+// savVariableRecord is a type 2 record of an SPSS dictionary
+type savVariableRecord struct {
+	name    string
+	width   int32
+	label   string
+	missing int32
+}
+
+// savDictionary is what the tests read back from an SPSS file
+type savDictionary struct {
+	product   string
+	layout    int32
+	elements  int32
+	cases     int32
+	fileLabel string
+	variables []savVariableRecord
+	longNames string
+	encoding  string
+	data      []float64 // Numeric elements of the cases, strings skipped
+}
+
+// readSAV parses the dictionary of an uncompressed SPSS file and the
+// numeric elements of its cases
+func readSAV(t *testing.T, file []byte) savDictionary {
+	t.Helper()
+	r := bytes.NewReader(file)
+	read := func(data interface{}) {
+		t.Helper()
+		if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+			t.Fatalf("truncated SPSS file: %v", err)
+		}
+	}
+	readInt := func() int32 {
+		var v int32
+		read(&v)
+		return v
+	}
+	readText := func(n int) string {
+		buf := make([]byte, n)
+		read(buf)
+		return string(buf)
+	}
+
+	var d savDictionary
+	if magic := readText(4); magic != "$FL2" {
+		t.Fatalf("magic = %q, want $FL2", magic)
+	}
+	d.product = strings.TrimRight(readText(60), "\x00")
+	d.layout = readInt()
+	d.elements = readInt()
+	readInt() // Compression
+	readInt() // Weight
+	d.cases = readInt()
+	var bias float64
+	read(&bias)
+	readText(9 + 8) // Date and time
+	d.fileLabel = strings.TrimRight(readText(64), "\x00")
+	readText(3)
+
+	for {
+		switch recordType := readInt(); recordType {
+		case 2:
+			var v savVariableRecord
+			v.width = readInt()
+			hasLabel := readInt()
+			v.missing = readInt()
+			readInt() // Print format
+			readInt() // Write format
+			v.name = strings.TrimRight(readText(8), " ")
+			if hasLabel == 1 {
+				n := int(readInt())
+				v.label = readText((n + 3) / 4 * 4)[:n]
+			}
+			readText(8 * int(v.missing))
+			if v.width >= 0 {
+				d.variables = append(d.variables, v)
+			}
+		case 3:
+			for n := readInt(); n > 0; n-- {
+				readText(8)
+				var length [1]byte
+				read(length[:])
+				readText((int(length[0])+1+7)/8*8 - 1)
+			}
+		case 4:
+			readText(4 * int(readInt()))
+		case 7:
+			subtype, size, count := readInt(), readInt(), readInt()
+			text := readText(int(size * count))
+			switch subtype {
+			case 13:
+				d.longNames = text
+			case 20:
+				d.encoding = text
+			}
+		case 999:
+			readInt()
+			for r.Len() > 0 {
+				var element [8]byte
+				read(element[:])
+				d.data = append(d.data, math.Float64frombits(binary.LittleEndian.Uint64(element[:])))
+			}
+			return d
+		default:
+			t.Fatalf("unexpected record type %d", recordType)
+		}
+	}
+}
+
+func TestWriteSAV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSAV(&buf, sampleTable(), "Coffee habits"); err != nil {
+		t.Fatal(err)
+	}
+	d := readSAV(t, buf.Bytes())
+
+	if !strings.HasPrefix(d.product, "@(#) SPSS DATA FILE") {
+		t.Errorf("product = %q", d.product)
+	}
+	if d.layout != 2 {
+		t.Errorf("layout code = %d, want 2", d.layout)
+	}
+	if d.elements != 3 {
+		t.Errorf("elements = %d, want 3", d.elements)
+	}
+	if d.cases != 2 {
+		t.Errorf("cases = %d, want 2", d.cases)
+	}
+	if d.fileLabel != "Coffee habits" {
+		t.Errorf("file label = %q", d.fileLabel)
+	}
+	if d.encoding != "UTF-8" {
+		t.Errorf("encoding = %q, want UTF-8", d.encoding)
+	}
+	if want := "RESPONSE=response_id\tQ1=q1\tSATISFAC=satisfaction_score"; d.longNames != want {
+		t.Errorf("long names = %q, want %q", d.longNames, want)
+	}
+
+	tests := []struct {
+		name    string
+		width   int32
+		label   string
+		missing int32
+	}{
+		{"RESPONSE", 2, "Response ID", 0},
+		{"Q1", 0, "最喜歡的顏色", 3},
+		{"SATISFAC", 0, "Satisfaction", 0},
+	}
+	if len(d.variables) != len(tests) {
+		t.Fatalf("got %d variables, want %d", len(d.variables), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := d.variables[i]
+			if v.name != tt.name {
+				t.Errorf("name = %q, want %q", v.name, tt.name)
+			}
+			if v.width != tt.width {
+				t.Errorf("width = %d, want %d", v.width, tt.width)
+			}
+			if v.label != tt.label {
+				t.Errorf("label = %q, want %q", v.label, tt.label)
+			}
+			if v.missing != tt.missing {
+				t.Errorf("missing values = %d, want %d", v.missing, tt.missing)
+			}
+		})
+	}
+
+	// Each case is the string element, then q1 and satisfaction_score
+	if len(d.data) != 6 {
+		t.Fatalf("got %d data elements, want 6", len(d.data))
+	}
+	got := []float64{d.data[1], d.data[2], d.data[4], d.data[5]}
+	want := []float64{2, 4.5, float64(MissingSkipped.Code()), savSysmis}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("numeric values = %v, want %v", got, want)
+	}
+}
+
+func TestWriteSAVLongLabel(t *testing.T) {
+	table := sampleTable()
+	table.Columns[2].Label = strings.Repeat("é", savMaxVarLabel)
+
+	var buf bytes.Buffer
+	if err := WriteSAV(&buf, table, strings.Repeat("x", 100)); err != nil {
+		t.Fatal(err)
+	}
+	d := readSAV(t, buf.Bytes())
+
+	if len(d.fileLabel) != savMaxFileLabel {
+		t.Errorf("file label is %d bytes, want %d", len(d.fileLabel), savMaxFileLabel)
+	}
+	label := d.variables[2].label
+	if len(label) > savMaxVarLabel || !strings.HasPrefix(label, "éé") || !utf8.ValidString(label) {
+		t.Errorf("label of %d bytes was not cut at a character boundary: %q", len(label), label)
+	}
+}
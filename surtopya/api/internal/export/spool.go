@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Spool is an exported table whose rows are kept in a temporary file rather
+// than in memory, for surveys with too many responses to hold at once. Rows
+// are added in order and can then be scanned any number of times.
+type Spool struct {
+	columns []Column
+	file    *os.File
+	w       *bufio.Writer
+	buf     []byte // Encoding of the row being added
+	rows    int
+}
+
+// NewSpool creates an empty spool in the system's temporary directory
+func NewSpool(columns []Column) (*Spool, error) {
+	file, err := os.CreateTemp("", "surtopya-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export spool: %w", err)
+	}
+	return &Spool{columns: columns, file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// Add appends a row. Each cell is stored with its length, followed by the
+// row's missing-value reasons.
+func (s *Spool) Add(row []string, missing []Missing) error {
+	s.buf = s.buf[:0]
+	for _, cell := range row {
+		s.buf = binary.AppendUvarint(s.buf, uint64(len(cell)))
+		s.buf = append(s.buf, cell...)
+	}
+	for _, m := range missing {
+		s.buf = append(s.buf, byte(m))
+	}
+	if _, err := s.w.Write(s.buf); err != nil {
+		return fmt.Errorf("failed to write export spool: %w", err)
+	}
+	s.rows++
+	return nil
+}
+
+// Header returns the columns of the spooled table
+func (s *Spool) Header() []Column { return s.columns }
+
+// Len returns the number of rows added
+func (s *Spool) Len() int { return s.rows }
+
+// Scan calls fn with each row in order. The slices passed to fn are reused
+// between rows.
+func (s *Spool) Scan(fn func(row []string, missing []Missing) error) error {
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export spool: %w", err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read export spool: %w", err)
+	}
+
+	r := bufio.NewReader(s.file)
+	row := make([]string, len(s.columns))
+	missing := make([]Missing, len(s.columns))
+	for i := 0; i < s.rows; i++ {
+		for j := range row {
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("failed to read export spool: %w", err)
+			}
+			cell := make([]byte, n)
+			if _, err := io.ReadFull(r, cell); err != nil {
+				return fmt.Errorf("failed to read export spool: %w", err)
+			}
+			row[j] = string(cell)
+		}
+		for j := range missing {
+			b, err := r.ReadByte()
+			if err != nil {
+				return fmt.Errorf("failed to read export spool: %w", err)
+			}
+			missing[j] = Missing(b)
+		}
+		if err := fn(row, missing); err != nil {
+			return err
+		}
+	}
+
+	// Later rows are appended at the end
+	if _, err := s.file.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to read export spool: %w", err)
+	}
+	return nil
+}
+
+// Close removes the spool's temporary file
+func (s *Spool) Close() error {
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
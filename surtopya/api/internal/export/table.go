@@ -0,0 +1,408 @@
+// Package export lays out survey responses as a flat table, one row per
+// response and one or more columns per question, and writes it in data file
+// formats.
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Column types
+const (
+	TypeString      = "string"
+	TypeNumeric     = "numeric"
+	TypeCategorical = "categorical" // Cells hold one of Values
+	TypeBinary      = "binary"      // Cells hold 0 or 1
+	TypeDate        = "date"        // Cells hold YYYY-MM-DD
+	TypeDateTime    = "datetime"    // Cells hold RFC 3339 times
+)
+
+// Missing tells why an answer cell is empty
+type Missing int8
+
+// Reasons for a missing answer. Formats with coded missing values write
+// them as codes -1, -2 and -3 (SPSS and R) or .a, .b and .c (Stata).
+const (
+	NotMissing        Missing = iota
+	MissingSkipped            // Shown to the respondent but not answered
+	MissingNotAsked           // Bypassed by skip logic
+	MissingNotReached         // The respondent stopped before reaching it
+)
+
+// MissingReasons lists the reasons for a missing answer, in code order
+var MissingReasons = []Missing{MissingSkipped, MissingNotAsked, MissingNotReached}
+
+// Label describes the reason
+func (m Missing) Label() string {
+	switch m {
+	case MissingSkipped:
+		return "Skipped"
+	case MissingNotAsked:
+		return "Not asked"
+	case MissingNotReached:
+		return "Not reached"
+	}
+	return ""
+}
+
+// Code is the reason's negative missing-value code
+func (m Missing) Code() int {
+	return -int(m)
+}
+
+// Column is a variable of the exported table
+type Column struct {
+	Name     string // Letters, digits and underscores, starting with a letter
+	Label    string
+	Type     string
+	Values   []string         // Categories of a categorical column, in order; codes are 1-based indexes
+	Question *models.Question // The question whose answers the column holds, if any
+	Number   int              // The question's number, as in Q3
+}
+
+// Source is an exported table read row by row. Writers may scan it more
+// than once, to size columns before writing them.
+type Source interface {
+	Header() []Column
+	Len() int
+	Scan(fn func(row []string, missing []Missing) error) error
+}
+
+// Table is the exported responses held in memory. An empty cell is a
+// missing value; Missing holds the reason for empty answer cells, row by row.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	Missing [][]Missing
+}
+
+// Header returns the columns of the table
+func (t *Table) Header() []Column { return t.Columns }
+
+// Len returns the number of rows
+func (t *Table) Len() int { return len(t.Rows) }
+
+// Scan calls fn with each row in order
+func (t *Table) Scan(fn func(row []string, missing []Missing) error) error {
+	for i, row := range t.Rows {
+		if err := fn(row, t.Missing[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnSize is the widest cell of a column, in bytes, and for numeric
+// columns the most decimals of its numbers
+type columnSize struct {
+	width    int
+	decimals int
+}
+
+// measure scans a source for the size of each column
+func measure(src Source) ([]columnSize, error) {
+	columns := src.Header()
+	sizes := make([]columnSize, len(columns))
+	err := src.Scan(func(row []string, _ []Missing) error {
+		for j, cell := range row {
+			sizes[j].width = max(sizes[j].width, len(cell))
+			if columns[j].Type != TypeNumeric {
+				continue
+			}
+			if dot := strings.IndexByte(cell, '.'); dot >= 0 {
+				sizes[j].decimals = max(sizes[j].decimals, min(len(cell)-dot-1, 16))
+			}
+		}
+		return nil
+	})
+	return sizes, err
+}
+
+// Layout is the columns of a survey's exported responses and how a response
+// fills them
+type Layout struct {
+	Columns []Column
+	survey  *models.Survey
+	cells   []func(models.ResponseRecord) string
+}
+
+// NewLayout lays out the columns of a survey's responses. Single-choice and
+// dropdown questions become categorical columns; each option of a
+// multiple-choice question and each code of a coded text question becomes a
+// 0/1 column. answered holds values of choice questions, by question ID,
+// that were answered but are no longer options; they are coded after the
+// options.
+func NewLayout(survey *models.Survey, codes []models.AnswerCode, answered map[uuid.UUID][]string) *Layout {
+	l := &Layout{survey: survey}
+	l.Append(Column{Name: "response_id", Label: "Response ID", Type: TypeString}, func(r models.ResponseRecord) string { return r.ID.String() })
+	l.Append(Column{Name: "status", Label: "Response status", Type: TypeCategorical, Values: models.ValidResponseStatuses}, func(r models.ResponseRecord) string { return r.Status })
+	l.Append(Column{Name: "started_at", Label: "Started at", Type: TypeDateTime}, func(r models.ResponseRecord) string { return formatTime(&r.StartedAt) })
+	l.Append(Column{Name: "completed_at", Label: "Completed at", Type: TypeDateTime}, func(r models.ResponseRecord) string { return formatTime(r.CompletedAt) })
+
+	codebooks := make(map[uuid.UUID][]models.AnswerCode)
+	for _, code := range codes {
+		codebooks[code.QuestionID] = append(codebooks[code.QuestionID], code)
+	}
+
+	number := 0
+	for i, q := range survey.Questions {
+		if q.Type == "section" {
+			continue
+		}
+		number++
+		for _, col := range questionColumns(q, number, codebooks[q.ID], answered[q.ID]) {
+			col.Question = &survey.Questions[i]
+			col.Number = number
+			l.Append(col.Column, col.cell)
+		}
+	}
+	return l
+}
+
+// Append adds a column to the end of the layout
+func (l *Layout) Append(column Column, cell func(models.ResponseRecord) string) {
+	l.Columns = append(l.Columns, column)
+	l.cells = append(l.cells, cell)
+}
+
+// AppendWeights adds a weight column holding each response's weight
+func (l *Layout) AppendWeights(weights map[uuid.UUID]float64) {
+	l.Append(Column{Name: "weight", Label: "Raking weight", Type: TypeNumeric}, func(r models.ResponseRecord) string {
+		return strconv.FormatFloat(weights[r.ID], 'f', 6, 64)
+	})
+}
+
+// Row lays out a response. Unanswered questions are marked as skipped,
+// bypassed by skip logic or not reached, following the response's path
+// through the survey.
+func (l *Layout) Row(record models.ResponseRecord) ([]string, []Missing) {
+	reasons := missingReasons(l.survey, record)
+	row := make([]string, len(l.Columns))
+	missing := make([]Missing, len(l.Columns))
+	for j, col := range l.Columns {
+		row[j] = l.cells[j](record)
+		if row[j] == "" && col.Question != nil {
+			missing[j] = reasons[col.Question.ID]
+		}
+	}
+	return row, missing
+}
+
+// Table lays out the responses as a table held in memory
+func (l *Layout) Table(records []models.ResponseRecord) *Table {
+	t := &Table{
+		Columns: l.Columns,
+		Rows:    make([][]string, len(records)),
+		Missing: make([][]Missing, len(records)),
+	}
+	for i, record := range records {
+		t.Rows[i], t.Missing[i] = l.Row(record)
+	}
+	return t
+}
+
+// NewTable lays out the responses to a survey as a table held in memory
+func NewTable(survey *models.Survey, codes []models.AnswerCode, records []models.ResponseRecord) *Table {
+	return NewLayout(survey, codes, AnsweredValues(survey, records)).Table(records)
+}
+
+// missingReasons tells why each unanswered question of a response has no
+// answer. Questions off the response's skip-logic path were not asked;
+// questions on it were skipped, unless they come after the last answer of a
+// response that was never completed.
+func missingReasons(survey *models.Survey, record models.ResponseRecord) map[uuid.UUID]Missing {
+	choices := make(map[uuid.UUID]string)
+	for id, answer := range record.Answers {
+		if answer.Value != nil {
+			choices[id] = *answer.Value
+		}
+	}
+	path := models.LogicPath(survey.Questions, choices)
+
+	last := -1
+	for i, q := range path {
+		if _, ok := record.Answers[q.ID]; ok {
+			last = i
+		}
+	}
+
+	reasons := make(map[uuid.UUID]Missing, len(survey.Questions))
+	for _, q := range survey.Questions {
+		reasons[q.ID] = MissingNotAsked
+	}
+	for i, q := range path {
+		if record.Status == "completed" || i <= last {
+			reasons[q.ID] = MissingSkipped
+		} else {
+			reasons[q.ID] = MissingNotReached
+		}
+	}
+	return reasons
+}
+
+// column is a column of the layout and the cell a response gives it
+type column struct {
+	Column
+	cell func(models.ResponseRecord) string
+}
+
+// questionColumns returns the columns of the question numbered n
+func questionColumns(q models.Question, n int, codebook []models.AnswerCode, answered []string) []column {
+	name := fmt.Sprintf("q%d", n)
+	label := fmt.Sprintf("Q%d. %s", n, q.Title)
+	answer := func(r models.ResponseRecord) (models.AnswerValue, bool) {
+		value, ok := r.Answers[q.ID]
+		return value, ok
+	}
+
+	switch q.Type {
+	case "single", "select":
+		return []column{{Column{Name: name, Label: label, Type: TypeCategorical, Values: categories(q, answered)}, func(r models.ResponseRecord) string {
+			if value, ok := answer(r); ok && value.Value != nil {
+				return *value.Value
+			}
+			return ""
+		}}}
+
+	case "multi":
+		var columns []column
+		for i, option := range categories(q, answered) {
+			option := option
+			columns = append(columns, column{
+				Column{Name: fmt.Sprintf("%s_%d", name, i+1), Label: fmt.Sprintf("Q%d. %s: %s", n, q.Title, option), Type: TypeBinary},
+				func(r models.ResponseRecord) string {
+					value, ok := answer(r)
+					if !ok {
+						return ""
+					}
+					return boolCell(slices.Contains(value.Values, option))
+				},
+			})
+		}
+		return columns
+
+	case "rating":
+		return []column{{Column{Name: name, Label: label, Type: TypeNumeric}, func(r models.ResponseRecord) string {
+			if value, ok := answer(r); ok && value.Rating != nil {
+				return strconv.Itoa(*value.Rating)
+			}
+			return ""
+		}}}
+
+	case "date":
+		return []column{{Column{Name: name, Label: label, Type: TypeDate}, func(r models.ResponseRecord) string {
+			if value, ok := answer(r); ok && value.Date != nil {
+				if _, err := time.Parse("2006-01-02", *value.Date); err == nil {
+					return *value.Date
+				}
+			}
+			return ""
+		}}}
+	}
+
+	// Open text, followed by one column per code of its codebook
+	columns := []column{{Column{Name: name, Label: label, Type: TypeString}, func(r models.ResponseRecord) string {
+		if value, ok := answer(r); ok && value.Text != nil {
+			return *value.Text
+		}
+		return ""
+	}}}
+	for i, code := range codebook {
+		codeID := code.ID
+		columns = append(columns, column{
+			Column{Name: fmt.Sprintf("%s_code%d", name, i+1), Label: fmt.Sprintf("Q%d code: %s", n, code.Label), Type: TypeBinary},
+			func(r models.ResponseRecord) string {
+				if value, ok := answer(r); !ok || value.Text == nil {
+					return ""
+				}
+				for _, id := range r.Codes[q.ID] {
+					if id == codeID {
+						return "1"
+					}
+				}
+				return "0"
+			},
+		})
+	}
+	return columns
+}
+
+// categories returns a choice question's options followed by answered
+// values that are no longer options
+func categories(q models.Question, answered []string) []string {
+	values := append([]string{}, q.Options...)
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		seen[v] = true
+	}
+	for _, v := range answered {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// AnsweredValues returns the values answered to each choice question of the
+// survey, by question ID, in the order they first appear in the responses
+func AnsweredValues(survey *models.Survey, records []models.ResponseRecord) map[uuid.UUID][]string {
+	answered := make(map[uuid.UUID][]string)
+	for _, q := range survey.Questions {
+		switch q.Type {
+		case "single", "select", "multi":
+		default:
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, record := range records {
+			answer, ok := record.Answers[q.ID]
+			if !ok {
+				continue
+			}
+			values := answer.Values
+			if answer.Value != nil {
+				values = []string{*answer.Value}
+			}
+			for _, v := range values {
+				if !seen[v] {
+					seen[v] = true
+					answered[q.ID] = append(answered[q.ID], v)
+				}
+			}
+		}
+	}
+	return answered
+}
+
+// categoryCodes maps the categories of a column to their 1-based codes
+func categoryCodes(col Column) map[string]int {
+	codes := make(map[string]int, len(col.Values))
+	for i, v := range col.Values {
+		if _, ok := codes[v]; !ok {
+			codes[v] = i + 1
+		}
+	}
+	return codes
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func boolCell(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
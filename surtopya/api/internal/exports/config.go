@@ -0,0 +1,65 @@
+// Package exports runs background exports of survey responses. A worker
+// claims queued jobs, pages through the responses into a temporary spool
+// so memory use does not grow with the survey, writes the export file to
+// storage, and the file is downloaded through signed links that expire.
+package exports
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds background export configuration
+type Config struct {
+	StorageDir   string        // Where export files are kept
+	PollInterval time.Duration // How often the worker looks for queued jobs
+	BatchSize    int           // Responses read per query
+	Retention    time.Duration // How long a finished export is kept
+	LinkTTL      time.Duration // How long a download link works
+	StaleAfter   time.Duration // A running job without a heartbeat for this long is retried
+	MaxAttempts  int
+	SigningKey   string // Signs download links; kept apart from JWT_SECRET so neither can forge the other
+}
+
+// LoadConfigFromEnv loads background export config from environment variables
+func LoadConfigFromEnv() Config {
+	return Config{
+		StorageDir:   getEnv("EXPORT_STORAGE_DIR", "exports"),
+		PollInterval: getDuration("EXPORT_POLL_INTERVAL", 5*time.Second),
+		BatchSize:    getInt("EXPORT_BATCH_SIZE", 500),
+		Retention:    getDuration("EXPORT_RETENTION", 24*time.Hour),
+		LinkTTL:      getDuration("EXPORT_LINK_TTL", 15*time.Minute),
+		StaleAfter:   getDuration("EXPORT_STALE_AFTER", 5*time.Minute),
+		MaxAttempts:  getInt("EXPORT_MAX_ATTEMPTS", 3),
+		SigningKey:   os.Getenv("EXPORT_SIGNING_KEY"),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
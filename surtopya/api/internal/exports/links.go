@@ -0,0 +1,65 @@
+package exports
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Links signs and checks export download links. A link carries its expiry
+// time and an HMAC of the job ID and expiry, so it works without a login and
+// stops working once it expires.
+type Links struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewLinks creates Links signing with the configured key. Without a key, a
+// random one is used, so links only work on this instance until it restarts.
+func NewLinks(cfg Config) *Links {
+	key := []byte(cfg.SigningKey)
+	if len(key) == 0 {
+		log.Println("Warning: EXPORT_SIGNING_KEY is not set; export download links use a random key")
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &Links{key: key, ttl: cfg.LinkTTL}
+}
+
+// Expiry returns when a link made now expires
+func (l *Links) Expiry(now time.Time) time.Time {
+	return now.Add(l.ttl).Truncate(time.Second)
+}
+
+// URL returns a download link for a job that works until expires, relative
+// to the API base URL
+func (l *Links) URL(jobID uuid.UUID, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", l.sign(jobID, expires.Unix()))
+	return "/export-jobs/" + jobID.String() + "/download?" + query.Encode()
+}
+
+// Valid reports whether the expires and signature parameters of a link are
+// a valid, unexpired signature for the job
+func (l *Links) Valid(jobID uuid.UUID, expires, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+	want := l.sign(jobID, unix)
+	return hmac.Equal([]byte(signature), []byte(want))
+}
+
+func (l *Links) sign(jobID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(jobID.String() + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
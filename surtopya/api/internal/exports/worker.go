@@ -0,0 +1,308 @@
+package exports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/export"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/TimLai666/surtopya-api/internal/storage"
+	"github.com/TimLai666/surtopya-api/internal/weighting"
+)
+
+// expireBatch is how many expired exports are deleted per run
+const expireBatch = 100
+
+// jobError is a failure the user is told about, such as weights that cannot
+// be computed; other failures are logged and reported generically
+type jobError struct {
+	message string
+}
+
+func (e *jobError) Error() string { return e.message }
+
+// Worker runs queued export jobs and deletes expired export files
+type Worker struct {
+	cfg           Config
+	store         storage.Store
+	jobRepo       *repository.ExportJobRepository
+	surveyRepo    *repository.SurveyRepository
+	reportRepo    *repository.ReportRepository
+	codingRepo    *repository.CodingRepository
+	weightingRepo *repository.WeightingRepository
+}
+
+// NewWorker creates a Worker writing export files to the store
+func NewWorker(db *sql.DB, store storage.Store, cfg Config) *Worker {
+	return &Worker{
+		cfg:           cfg,
+		store:         store,
+		jobRepo:       repository.NewExportJobRepository(db),
+		surveyRepo:    repository.NewSurveyRepository(db),
+		reportRepo:    repository.NewReportRepository(db),
+		codingRepo:    repository.NewCodingRepository(db),
+		weightingRepo: repository.NewWeightingRepository(db),
+	}
+}
+
+// Run polls for jobs every interval until the context is cancelled. A job
+// interrupted by shutdown is left running and retried by any instance once
+// its heartbeat goes stale.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) runOnce(ctx context.Context) {
+	if err := w.expireFiles(); err != nil {
+		log.Printf("Exports: %v", err)
+	}
+
+	staleBefore := time.Now().Add(-w.cfg.StaleAfter)
+	if n, err := w.jobRepo.FailAbandoned(staleBefore, w.cfg.MaxAttempts); err != nil {
+		log.Printf("Exports: %v", err)
+	} else if n > 0 {
+		log.Printf("Exports: marked %d abandoned exports as failed", n)
+	}
+
+	for ctx.Err() == nil {
+		job, err := w.jobRepo.Claim(staleBefore, w.cfg.MaxAttempts)
+		if err != nil {
+			log.Printf("Exports: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		w.process(ctx, job)
+	}
+}
+
+// process runs a job and records its outcome
+func (w *Worker) process(ctx context.Context, job *models.ExportJob) {
+	err := w.run(ctx, job)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	if errors.Is(err, repository.ErrExportJobLost) {
+		log.Printf("Exports: job %s was taken over by another worker", job.ID)
+		return
+	}
+
+	message := "The export failed"
+	var userErr *jobError
+	if errors.As(err, &userErr) {
+		message = userErr.message
+	} else {
+		log.Printf("Exports: job %s failed: %v", job.ID, err)
+	}
+	if err := w.jobRepo.Fail(job, message); err != nil && !errors.Is(err, repository.ErrExportJobLost) {
+		log.Printf("Exports: %v", err)
+	}
+}
+
+// run exports the responses of a job to storage
+func (w *Worker) run(ctx context.Context, job *models.ExportJob) error {
+	survey, err := w.surveyRepo.GetByID(job.SurveyID)
+	if err != nil {
+		return err
+	}
+	if survey == nil {
+		return &jobError{"The survey no longer exists"}
+	}
+
+	total, err := w.reportRepo.CountResponses(survey.ID, job.Filter)
+	if err != nil {
+		return err
+	}
+	if err := w.jobRepo.SetTotal(job, total); err != nil {
+		return err
+	}
+
+	layout, err := w.layout(survey, job.Filter)
+	if err != nil {
+		return err
+	}
+
+	spool, err := export.NewSpool(layout.Columns)
+	if err != nil {
+		return err
+	}
+	defer spool.Close()
+
+	var after *models.ResponseRecord
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		records, err := w.reportRepo.GetResponseRecordsPage(survey.ID, job.Filter, after, w.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := spool.Add(layout.Row(record)); err != nil {
+				return err
+			}
+		}
+		if err := w.jobRepo.Progress(job, spool.Len()); err != nil {
+			return err
+		}
+		if len(records) < w.cfg.BatchSize {
+			break
+		}
+		after = &records[len(records)-1]
+	}
+
+	// Each attempt writes its own file, so an attempt that lost the job to
+	// another worker cannot overwrite or delete the file that completes it
+	key := fmt.Sprintf("%s-%d-%s", job.ID, job.Attempts, job.Format)
+	file, err := w.store.Create(key)
+	if err != nil {
+		return err
+	}
+	stop := w.heartbeat(job)
+	counter := &countingWriter{w: file}
+	meta := export.Meta{Survey: survey, Filter: job.Filter, GeneratedAt: time.Now()}
+	err = export.Write(counter, job.Format, spool, meta)
+	stop()
+	if err != nil {
+		file.Abort()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(w.cfg.Retention)
+	if err := w.jobRepo.Complete(job, key, export.FileName(survey.ID, job.Format), counter.n, expiresAt); err != nil {
+		if !errors.Is(err, repository.ErrExportJobLost) {
+			w.store.Delete(key)
+		}
+		return err
+	}
+	return nil
+}
+
+// heartbeat refreshes the job's heartbeat on a ticker until the returned
+// function is called, so writing a large file does not outlast StaleAfter
+func (w *Worker) heartbeat(job *models.ExportJob) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.cfg.StaleAfter / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := w.jobRepo.Heartbeat(job); err != nil {
+					log.Printf("Exports: heartbeat of job %s: %v", job.ID, err)
+					if errors.Is(err, repository.ErrExportJobLost) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// layout lays out the survey's columns as the synchronous export does, with
+// categories for answered values that are no longer options and a weight
+// column when the survey has a weighting scheme
+func (w *Worker) layout(survey *models.Survey, filter models.ReportFilter) (*export.Layout, error) {
+	codes, err := w.codingRepo.GetCodes(survey.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var choiceIDs []string
+	for _, q := range survey.Questions {
+		switch q.Type {
+		case "single", "select", "multi":
+			choiceIDs = append(choiceIDs, q.ID.String())
+		}
+	}
+	answered, err := w.reportRepo.GetAnsweredValues(survey.ID, filter, choiceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := export.NewLayout(survey, codes, answered)
+
+	scheme, err := w.weightingRepo.Get(survey.ID)
+	if err != nil || scheme == nil {
+		return layout, err
+	}
+	// Weights are computed over the whole filtered sample, without the segment
+	weightFilter := filter
+	weightFilter.Segment = nil
+	ids, choices, err := w.reportRepo.GetChoiceAnswers(survey.ID, weightFilter, weighting.MarginQuestionIDs(scheme))
+	if err != nil {
+		return nil, err
+	}
+	result, err := weighting.Compute(survey, scheme, ids, choices)
+	if errors.Is(err, weighting.ErrNoResponses) || errors.Is(err, weighting.ErrEmptyCategory) {
+		return nil, &jobError{"Weights cannot be computed: " + err.Error()}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute weights: %w", err)
+	}
+	layout.AppendWeights(result.Weights)
+
+	return layout, nil
+}
+
+// expireFiles deletes export files past their retention
+func (w *Worker) expireFiles() error {
+	jobs, err := w.jobRepo.ListExpired(time.Now(), expireBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.StorageKey != nil {
+			if err := w.store.Delete(*job.StorageKey); err != nil {
+				log.Printf("Exports: %v", err)
+				continue
+			}
+		}
+		if err := w.jobRepo.MarkExpired(job.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Permissions on a survey, granted through access roles
+const (
+	permissionView    = "view"    // Read the survey and its settings
+	permissionEdit    = "edit"    // Update, publish and unpublish; manage quotas
+	permissionResults = "results" // Read responses and statistics
+	permissionManage  = "manage"  // Delete or move between organizations
+)
+
+// rolePermissions maps each access role to the permissions it grants
+var rolePermissions = map[string][]string{
+	models.AccessRoleOwner:   {permissionView, permissionEdit, permissionResults, permissionManage},
+	models.AccessRoleEditor:  {permissionView, permissionEdit, permissionResults},
+	models.AccessRoleAnalyst: {permissionView, permissionResults},
+	models.AccessRoleViewer:  {permissionView},
+}
+
+// hasSurveyPermission reports whether a user's access role on a survey grants the permission
+func hasSurveyPermission(surveyRepo *repository.SurveyRepository, survey *models.Survey, userID uuid.UUID, permission string) (bool, error) {
+	role, err := surveyRepo.GetAccessRole(survey, userID)
+	if err != nil {
+		return false, err
+	}
+	return contains(rolePermissions[role], permission), nil
+}
+
+// authorizeSurvey checks that the user holds the permission on the survey,
+// writing the error response and returning false otherwise
+func authorizeSurvey(c *gin.Context, surveyRepo *repository.SurveyRepository, survey *models.Survey, userID uuid.UUID, permission string) bool {
+	allowed, err := hasSurveyPermission(surveyRepo, survey, userID, permission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check survey access"})
+		return false
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return false
+	}
+
+	return true
+}
+
+// canCreateInOrganization checks that the user may create surveys in the organization,
+// writing the error response and returning false otherwise
+func canCreateInOrganization(c *gin.Context, orgRepo *repository.OrganizationRepository, orgID, userID uuid.UUID) bool {
+	role, err := orgRepo.GetMemberRole(orgID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organization access"})
+		return false
+	}
+
+	if role != models.AccessRoleOwner && role != models.AccessRoleEditor {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only organization owners and editors can add surveys"})
+		return false
+	}
+
+	return true
+}
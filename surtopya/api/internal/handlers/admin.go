@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminHandler handles moderator and admin requests
+type AdminHandler struct {
+	userRepo    *repository.UserRepository
+	surveyRepo  *repository.SurveyRepository
+	datasetRepo *repository.DatasetRepository
+	auditRepo   *repository.AuditRepository
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler() *AdminHandler {
+	db := database.GetDB()
+	return &AdminHandler{
+		userRepo:    repository.NewUserRepository(db),
+		surveyRepo:  repository.NewSurveyRepository(db),
+		datasetRepo: repository.NewDatasetRepository(db),
+		auditRepo:   repository.NewAuditRepository(db),
+	}
+}
+
+// SearchUsers handles GET /api/v1/admin/users
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	search := c.Query("search")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	users, err := h.userRepo.Search(search, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		return
+	}
+
+	if !h.audit(c, auditEntry(c, "user.search", "user", nil, gin.H{"search": search})) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// GetUser handles GET /api/v1/admin/users/:id
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	if !h.audit(c, auditEntry(c, "user.view", "user", &user.ID, nil)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUserRoleRequest represents the request body for changing a user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateUserRole handles PUT /api/v1/admin/users/:id/role
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidRoles, req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	actorID, _ := c.Get("userID")
+	if user.ID == actorID.(uuid.UUID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change your own role"})
+		return
+	}
+
+	entry := auditEntry(c, "user.role_change", "user", &user.ID, gin.H{"from": user.Role, "to": req.Role})
+	if err := h.userRepo.UpdateRole(user.ID, req.Role, entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	user.Role = req.Role
+	c.JSON(http.StatusOK, user)
+}
+
+// GrantPointsRequest represents the request body for granting points
+type GrantPointsRequest struct {
+	Amount      int    `json:"amount" binding:"required"`
+	Description string `json:"description"`
+}
+
+// GrantPoints handles POST /api/v1/admin/users/:id/points
+func (h *AdminHandler) GrantPoints(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req GrantPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	entry := auditEntry(c, "user.points_grant", "user", &user.ID, gin.H{
+		"amount":      req.Amount,
+		"description": req.Description,
+	})
+	transaction, err := h.userRepo.GrantPoints(user.ID, req.Amount, req.Description, entry)
+	if errors.Is(err, repository.ErrNegativeBalance) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Points balance cannot become negative"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant points"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// TakedownSurveyRequest represents the request body for taking down a survey
+type TakedownSurveyRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// TakedownSurvey handles POST /api/v1/admin/surveys/:id/takedown
+func (h *AdminHandler) TakedownSurvey(c *gin.Context) {
+	survey, ok := h.getSurvey(c)
+	if !ok {
+		return
+	}
+
+	var req TakedownSurveyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	entry := auditEntry(c, "survey.takedown", "survey", &survey.ID, gin.H{
+		"reason":       req.Reason,
+		"wasPublished": survey.IsPublished,
+	})
+	if err := h.surveyRepo.Takedown(survey.ID, req.Reason, entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to take down survey"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Survey taken down successfully"})
+}
+
+// RestoreSurvey handles POST /api/v1/admin/surveys/:id/restore
+func (h *AdminHandler) RestoreSurvey(c *gin.Context) {
+	survey, ok := h.getSurvey(c)
+	if !ok {
+		return
+	}
+
+	entry := auditEntry(c, "survey.restore", "survey", &survey.ID, nil)
+	if err := h.surveyRepo.Restore(survey.ID, entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore survey"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Survey restored successfully"})
+}
+
+// DeactivateDataset handles POST /api/v1/admin/datasets/:id/deactivate
+func (h *AdminHandler) DeactivateDataset(c *gin.Context) {
+	h.setDatasetActive(c, false)
+}
+
+// ActivateDataset handles POST /api/v1/admin/datasets/:id/activate
+func (h *AdminHandler) ActivateDataset(c *gin.Context) {
+	h.setDatasetActive(c, true)
+}
+
+func (h *AdminHandler) setDatasetActive(c *gin.Context, isActive bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dataset ID"})
+		return
+	}
+
+	dataset, err := h.datasetRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dataset"})
+		return
+	}
+
+	if dataset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+		return
+	}
+
+	action := "dataset.deactivate"
+	if isActive {
+		action = "dataset.activate"
+	}
+	if err := h.datasetRepo.SetActive(id, isActive, auditEntry(c, action, "dataset", &dataset.ID, nil)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dataset"})
+		return
+	}
+
+	dataset.IsActive = isActive
+	c.JSON(http.StatusOK, dataset)
+}
+
+// GetAuditLogs handles GET /api/v1/admin/audit-logs
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if limit > 200 {
+		limit = 200
+	}
+
+	var actorID, targetID *uuid.UUID
+	if v := c.Query("actorId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+			return
+		}
+		actorID = &id
+	}
+	if v := c.Query("targetId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+			return
+		}
+		targetID = &id
+	}
+
+	entries, err := h.auditRepo.GetAll(actorID, targetID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"auditLogs": entries})
+}
+
+// getUser loads the user from the :id param
+func (h *AdminHandler) getUser(c *gin.Context) (*models.User, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return nil, false
+	}
+
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// getSurvey loads the survey from the :id param
+func (h *AdminHandler) getSurvey(c *gin.Context) (*models.Survey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// auditEntry builds the audit log entry of an admin action by the current
+// user. Actions that change data store it in their own transaction.
+func auditEntry(c *gin.Context, action, targetType string, targetID *uuid.UUID, details gin.H) *models.AuditLog {
+	entry := &models.AuditLog{
+		ID:         uuid.New(),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+	}
+	if actorID, exists := c.Get("userID"); exists {
+		id := actorID.(uuid.UUID)
+		entry.ActorID = &id
+	}
+	if ip := c.ClientIP(); ip != "" {
+		entry.IPAddress = &ip
+	}
+	return entry
+}
+
+// audit records an admin action that reads data, writing an error response
+// and returning false if it cannot be recorded
+func (h *AdminHandler) audit(c *gin.Context, entry *models.AuditLog) bool {
+	if err := h.auditRepo.Create(entry); err != nil {
+		log.Printf("Failed to audit %s: %v", entry.Action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record audit log"})
+		return false
+	}
+	return true
+}
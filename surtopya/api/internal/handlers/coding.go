@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/TimLai666/surtopya-api/internal/textcluster"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CodingHandler handles codebook and open-text answer coding requests
+type CodingHandler struct {
+	codingRepo *repository.CodingRepository
+	surveyRepo *repository.SurveyRepository
+}
+
+// NewCodingHandler creates a new CodingHandler
+func NewCodingHandler() *CodingHandler {
+	db := database.GetDB()
+	return &CodingHandler{
+		codingRepo: repository.NewCodingRepository(db),
+		surveyRepo: repository.NewSurveyRepository(db),
+	}
+}
+
+// Limits of the coding endpoints
+const (
+	maxCodeAssignment = 1000 // Answers per bulk assignment
+	maxSuggestAnswers = 5000 // Answers clustered per suggestion request
+)
+
+// textQuestionTypes are the question types with open-text answers
+var textQuestionTypes = map[string]bool{"text": true, "short": true, "long": true}
+
+// CodeRequest represents the request body for creating or updating a code
+type CodeRequest struct {
+	QuestionID  string  `json:"questionId"`
+	Label       string  `json:"label"`
+	Description *string `json:"description"`
+	Color       *string `json:"color"`
+	Position    *int    `json:"position"`
+}
+
+// GetCodes handles GET /api/v1/surveys/:id/codes
+// It returns the survey's codebook, or one question's with ?question=.
+func (h *CodingHandler) GetCodes(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	var questionID *uuid.UUID
+	if value := c.Query("question"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+			return
+		}
+		questionID = &id
+	}
+
+	codes, err := h.codingRepo.GetCodes(survey.ID, questionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"codes": codes})
+}
+
+// CreateCode handles POST /api/v1/surveys/:id/codes
+func (h *CodingHandler) CreateCode(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	var req CodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	questionID, err := uuid.Parse(req.QuestionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+	if _, ok := textQuestion(c, survey, questionID); !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	creatorID := userID.(uuid.UUID)
+	code := &models.AnswerCode{SurveyID: survey.ID, QuestionID: questionID, CreatedBy: &creatorID}
+	if !applyCodeRequest(c, code, &req) {
+		return
+	}
+
+	if err := h.codingRepo.CreateCode(code); err != nil {
+		if errors.Is(err, repository.ErrDuplicateCodeLabel) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The question already has a code with this label"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, code)
+}
+
+// UpdateCode handles PUT /api/v1/surveys/:id/codes/:codeId
+func (h *CodingHandler) UpdateCode(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	code, ok := h.getSurveyCode(c, survey)
+	if !ok {
+		return
+	}
+
+	var req CodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !applyCodeRequest(c, code, &req) {
+		return
+	}
+
+	if err := h.codingRepo.UpdateCode(code); err != nil {
+		if errors.Is(err, repository.ErrDuplicateCodeLabel) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The question already has a code with this label"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, code)
+}
+
+// DeleteCode handles DELETE /api/v1/surveys/:id/codes/:codeId
+// Deleting a code removes it from every answer.
+func (h *CodingHandler) DeleteCode(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	code, ok := h.getSurveyCode(c, survey)
+	if !ok {
+		return
+	}
+
+	if err := h.codingRepo.DeleteCode(code.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Code deleted successfully"})
+}
+
+// GetAnswers handles GET /api/v1/surveys/:id/questions/:questionId/answers
+// It lists the question's text answers with their codes for tagging.
+// Query parameters: code (only answers with the code), uncoded=true,
+// search (text substring), limit (default 50, at most 200) and offset.
+func (h *CodingHandler) GetAnswers(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getTextQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	aq := repository.AnswerQuery{
+		Uncoded: c.Query("uncoded") == "true",
+		Search:  strings.TrimSpace(c.Query("search")),
+	}
+	if value := c.Query("code"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code ID"})
+			return
+		}
+		aq.CodeID = &id
+	}
+	aq.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	aq.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if aq.Limit <= 0 {
+		aq.Limit = 50
+	}
+	if aq.Limit > 200 {
+		aq.Limit = 200
+	}
+	if aq.Offset < 0 {
+		aq.Offset = 0
+	}
+
+	answers, total, err := h.codingRepo.GetAnswers(question.ID, aq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get answers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"answers": answers, "total": total})
+}
+
+// AssignCodes handles POST /api/v1/surveys/:id/questions/:questionId/codes
+// It tags answers with codes in bulk: mode add (default) keeps their other
+// codes, remove takes the codes off, and set replaces their codes.
+func (h *CodingHandler) AssignCodes(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getTextQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	var req models.CodeAssignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	switch req.Mode {
+	case "":
+		req.Mode = models.CodeAssignAdd
+	case models.CodeAssignAdd, models.CodeAssignRemove, models.CodeAssignSet:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be add, remove or set"})
+		return
+	}
+	if len(req.AnswerIDs) == 0 || len(req.AnswerIDs) > maxCodeAssignment {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Assign codes to between 1 and 1000 answers at a time"})
+		return
+	}
+	if len(req.CodeIDs) == 0 && req.Mode != models.CodeAssignSet {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "codeIds is required"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	matched, err := h.codingRepo.Assign(question.ID, req, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"answers": matched})
+}
+
+// SuggestCodes handles GET /api/v1/surveys/:id/questions/:questionId/code-suggestions
+// It clusters the question's text answers into candidate codes offline
+// (TF-IDF over CJK-aware tokens). ?k= sets the number of clusters, which
+// otherwise follows the number of answers. Nothing is saved.
+func (h *CodingHandler) SuggestCodes(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getTextQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	k, err := strconv.Atoi(c.DefaultQuery("k", "0"))
+	if err != nil || k < 0 || k > textcluster.MaxClusters {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "k must be between 1 and 20"})
+		return
+	}
+
+	ids, texts, err := h.codingRepo.GetAnswerTexts(question.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get answers"})
+		return
+	}
+	if len(ids) > maxSuggestAnswers {
+		ids, texts = ids[:maxSuggestAnswers], texts[:maxSuggestAnswers]
+	}
+
+	suggestions := []models.CodeSuggestion{}
+	for _, group := range textcluster.Suggest(texts, k) {
+		suggestion := models.CodeSuggestion{
+			Label:     group.Label(),
+			Terms:     group.Terms,
+			AnswerIDs: make([]uuid.UUID, len(group.Members)),
+			Examples:  make([]string, len(group.Examples)),
+		}
+		for i, member := range group.Members {
+			suggestion.AnswerIDs[i] = ids[member]
+		}
+		for i, example := range group.Examples {
+			suggestion.Examples[i] = texts[example]
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions, "answers": len(ids)})
+}
+
+// getAuthorizedSurvey loads the survey from the :id param and checks that the
+// current user may read its results, which includes coding answers
+func (h *CodingHandler) getAuthorizedSurvey(c *gin.Context) (*models.Survey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionResults) {
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// getSurveyCode loads the code from the :codeId param and checks that it belongs to the survey
+func (h *CodingHandler) getSurveyCode(c *gin.Context, survey *models.Survey) (*models.AnswerCode, bool) {
+	codeID, err := uuid.Parse(c.Param("codeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code ID"})
+		return nil, false
+	}
+
+	code, err := h.codingRepo.GetCode(codeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get code"})
+		return nil, false
+	}
+
+	if code == nil || code.SurveyID != survey.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Code not found"})
+		return nil, false
+	}
+
+	return code, true
+}
+
+// getTextQuestion loads the open-text question from the :questionId param
+func (h *CodingHandler) getTextQuestion(c *gin.Context, survey *models.Survey) (*models.Question, bool) {
+	questionID, err := uuid.Parse(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return nil, false
+	}
+	return textQuestion(c, survey, questionID)
+}
+
+// textQuestion finds an open-text question of the survey, responding with an error if there is none
+func textQuestion(c *gin.Context, survey *models.Survey, id uuid.UUID) (*models.Question, bool) {
+	question := findQuestion(survey, id)
+	if question == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return nil, false
+	}
+	if !textQuestionTypes[question.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open-text questions have codebooks"})
+		return nil, false
+	}
+	return question, true
+}
+
+// applyCodeRequest validates the request and copies it onto the code
+func applyCodeRequest(c *gin.Context, code *models.AnswerCode, req *CodeRequest) bool {
+	label := strings.TrimSpace(req.Label)
+	if label == "" || len([]rune(label)) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code label must be 1 to 200 characters"})
+		return false
+	}
+	if req.Color != nil && len(*req.Color) > 20 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code color must be at most 20 characters"})
+		return false
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code position cannot be negative"})
+		return false
+	}
+
+	code.Label = label
+	code.Description = req.Description
+	code.Color = req.Color
+	if req.Position != nil {
+		code.Position = *req.Position
+	}
+	return true
+}
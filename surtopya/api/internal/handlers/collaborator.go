@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/mailer"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CollaboratorHandler handles per-survey collaborator and invitation requests
+type CollaboratorHandler struct {
+	repo          *repository.CollaboratorRepository
+	surveyRepo    *repository.SurveyRepository
+	mailer        mailer.Mailer
+	appURL        string
+	invitationTTL time.Duration
+}
+
+// NewCollaboratorHandler creates a new CollaboratorHandler
+func NewCollaboratorHandler() *CollaboratorHandler {
+	db := database.GetDB()
+	return &CollaboratorHandler{
+		repo:          repository.NewCollaboratorRepository(db),
+		surveyRepo:    repository.NewSurveyRepository(db),
+		mailer:        mailer.New(mailer.LoadConfigFromEnv()),
+		appURL:        strings.TrimRight(getEnvDefault("APP_URL", "http://localhost:3000"), "/"),
+		invitationTTL: loadInvitationTTL(),
+	}
+}
+
+// loadInvitationTTL reads how long invitation links stay valid
+func loadInvitationTTL() time.Duration {
+	ttl := 7 * 24 * time.Hour
+	if value := os.Getenv("INVITATION_TTL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Printf("Invalid INVITATION_TTL %q, using %s", value, ttl)
+		} else {
+			ttl = d
+		}
+	}
+	return ttl
+}
+
+func getEnvDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// GetCollaborators handles GET /api/v1/surveys/:id/collaborators
+func (h *CollaboratorHandler) GetCollaborators(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionView)
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.repo.GetBySurveyID(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get collaborators"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collaborators": collaborators})
+}
+
+// UpdateCollaboratorRequest represents the request body for changing a collaborator's role
+type UpdateCollaboratorRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateCollaborator handles PUT /api/v1/surveys/:id/collaborators/:userId
+func (h *CollaboratorHandler) UpdateCollaborator(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionManage)
+	if !ok {
+		return
+	}
+
+	collaboratorID, ok := h.getCollaborator(c, survey)
+	if !ok {
+		return
+	}
+
+	var req UpdateCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidCollaboratorRoles, req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	if err := h.repo.SetRole(survey.ID, collaboratorID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collaborator"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"surveyId": survey.ID, "userId": collaboratorID, "role": req.Role})
+}
+
+// RemoveCollaborator handles DELETE /api/v1/surveys/:id/collaborators/:userId
+// Owners can remove any collaborator; collaborators can remove themselves.
+func (h *CollaboratorHandler) RemoveCollaborator(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionView)
+	if !ok {
+		return
+	}
+
+	collaboratorID, ok := h.getCollaborator(c, survey)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if collaboratorID != userID.(uuid.UUID) && !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionManage) {
+		return
+	}
+
+	if err := h.repo.Remove(survey.ID, collaboratorID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove collaborator"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed successfully"})
+}
+
+// CreateInvitationRequest represents the request body for inviting a collaborator
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// CreateInvitation handles POST /api/v1/surveys/:id/invitations
+func (h *CollaboratorHandler) CreateInvitation(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionManage)
+	if !ok {
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidCollaboratorRoles, req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	inviterID := userID.(uuid.UUID)
+	invitation := &models.SurveyInvitation{
+		ID:        uuid.New(),
+		SurveyID:  survey.ID,
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		Role:      req.Role,
+		InvitedBy: &inviterID,
+		ExpiresAt: time.Now().Add(h.invitationTTL),
+	}
+
+	if err := h.repo.CreateInvitation(invitation, token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	acceptURL := h.appURL + "/invitations/accept?token=" + url.QueryEscape(token)
+	subject := fmt.Sprintf("You've been invited to collaborate on \"%s\"", survey.Title)
+	body := fmt.Sprintf(
+		"You have been invited to collaborate on the survey \"%s\" as %s on Surtopya.\n\n"+
+			"Sign in and accept the invitation here:\n%s\n\n"+
+			"This invitation expires on %s.\n",
+		survey.Title, req.Role, acceptURL, invitation.ExpiresAt.UTC().Format(time.RFC1123),
+	)
+
+	if err := h.mailer.Send(invitation.Email, subject, body); err != nil {
+		log.Printf("Failed to send invitation %s: %v", invitation.ID, err)
+		if _, err := h.repo.DeleteInvitation(invitation.ID, survey.ID); err != nil {
+			log.Printf("Failed to revoke unsent invitation %s: %v", invitation.ID, err)
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send invitation email"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations handles GET /api/v1/surveys/:id/invitations
+func (h *CollaboratorHandler) GetInvitations(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionManage)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.repo.GetPendingInvitations(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// RevokeInvitation handles DELETE /api/v1/surveys/:id/invitations/:invitationId
+func (h *CollaboratorHandler) RevokeInvitation(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionManage)
+	if !ok {
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	found, err := h.repo.DeleteInvitation(invitationID, survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitationRequest represents the request body for accepting an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AcceptInvitation handles POST /api/v1/invitations/accept
+// The token must be redeemed by a user signed in with the invited email, so
+// a forwarded or leaked link does not grant access to anyone else.
+func (h *CollaboratorHandler) AcceptInvitation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	collaborator, err := h.repo.AcceptInvitation(req.Token, userID.(uuid.UUID))
+	if err == repository.ErrInvitationInvalid {
+		c.JSON(http.StatusGone, gin.H{"error": "Invitation is invalid or has expired"})
+		return
+	}
+	if err == repository.ErrInvitationEmailMismatch {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invitation was sent to another email address"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborator)
+}
+
+// getAuthorizedSurvey loads the survey from the :id param and checks that the current user holds the permission on it
+func (h *CollaboratorHandler) getAuthorizedSurvey(c *gin.Context, permission string) (*models.Survey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permission) {
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// getCollaborator parses the :userId param and checks that the user collaborates on the survey
+func (h *CollaboratorHandler) getCollaborator(c *gin.Context, survey *models.Survey) (uuid.UUID, bool) {
+	collaboratorID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+
+	role, err := h.repo.GetRole(survey.ID, collaboratorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get collaborator"})
+		return uuid.Nil, false
+	}
+
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collaborator not found"})
+		return uuid.Nil, false
+	}
+
+	return collaboratorID, true
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/export"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExportResponses handles GET /api/v1/surveys/:id/responses/export
+// It downloads the responses as a table, one row per response. Coded
+// open-text questions get a 0/1 column per code. With a weighting scheme, a
+// last weight column holds each response's raking weight, computed over the
+// responses matching the filter without its segment. It takes the same from,
+// to, status and segment filters as the report. Format csv downloads the
+// table as CSV; sav (SPSS), dta (Stata) and r (a coded CSV with an R labels
+// script) download a zip of the data files and a codebook. The responses are
+// loaded at once; large surveys should use CreateExportJob instead.
+func (h *ReportHandler) ExportResponses(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if !contains(export.Formats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of " + strings.Join(export.Formats, ", ")})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	records, err := h.reportRepo.GetResponseRecords(survey.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get responses"})
+		return
+	}
+
+	codes, err := h.codingRepo.GetCodes(survey.ID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get codes"})
+		return
+	}
+
+	weights, ok := h.computeWeights(c, survey, filter, false)
+	if !ok {
+		return
+	}
+
+	layout := export.NewLayout(survey, codes, export.AnsweredValues(survey, records))
+	if weights != nil {
+		layout.AppendWeights(weights.Weights)
+	}
+	table := layout.Table(records)
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName(survey.ID, format)))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer, format, table, export.Meta{Survey: survey, Filter: filter, GeneratedAt: time.Now()}); err != nil {
+		log.Printf("Failed to export responses of survey %s: %v", survey.ID, err)
+	}
+}
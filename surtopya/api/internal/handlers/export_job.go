@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/export"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxListedExportJobs limits the export jobs listed for a survey
+const maxListedExportJobs = 20
+
+// CreateExportJob handles POST /api/v1/surveys/:id/export-jobs
+// It queues a background export of the responses, for surveys too large to
+// export in one request. It takes the format (csv, sav, dta or r) and the
+// from, to, status and segment filters of ExportResponses as query
+// parameters, and responds 202 with the job to poll.
+func (h *ReportHandler) CreateExportJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if !contains(export.Formats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of " + strings.Join(export.Formats, ", ")})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid := userID.(uuid.UUID)
+	job := &models.ExportJob{
+		ID:          uuid.New(),
+		SurveyID:    survey.ID,
+		RequestedBy: &uid,
+		Format:      format,
+		Filter:      filter,
+	}
+	if err := h.exportJobRepo.Create(job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export job"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExportJobs handles GET /api/v1/surveys/:id/export-jobs
+// It lists the survey's most recent export jobs, newest first.
+func (h *ReportHandler) GetExportJobs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+
+	jobs, err := h.exportJobRepo.ListBySurvey(survey.ID, maxListedExportJobs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get export jobs"})
+		return
+	}
+
+	now := time.Now()
+	for i := range jobs {
+		h.setDownloadURL(&jobs[i], now)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetExportJob handles GET /api/v1/export-jobs/:jobId
+// It returns the status and progress of an export. Once it has completed,
+// downloadUrl is a link that works without a login until
+// downloadUrlExpiresAt; poll again for a fresh link.
+func (h *ReportHandler) GetExportJob(c *gin.Context) {
+	job, ok := h.exportJob(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.resultsSurvey(c, job.SurveyID); !ok {
+		return
+	}
+
+	h.setDownloadURL(job, time.Now())
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadExport handles GET /api/v1/export-jobs/:jobId/download
+// It downloads a completed export. It needs no login: the expires and
+// signature query parameters of the link from GetExportJob authorize it.
+func (h *ReportHandler) DownloadExport(c *gin.Context) {
+	job, ok := h.exportJob(c)
+	if !ok {
+		return
+	}
+
+	if !h.exportLinks.Valid(job.ID, c.Query("expires"), c.Query("signature"), time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The download link is invalid or has expired"})
+		return
+	}
+	if job.Status == models.ExportExpired {
+		c.JSON(http.StatusGone, gin.H{"error": "The export has expired"})
+		return
+	}
+	if job.Status != models.ExportCompleted || job.StorageKey == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "The export is not ready"})
+		return
+	}
+
+	file, err := h.exportStore.Open(*job.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusGone, gin.H{"error": "The export has expired"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to open export %s: %v", job.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export"})
+		return
+	}
+	defer file.Close()
+
+	fileName := export.FileName(job.SurveyID, job.Format)
+	if job.FileName != nil {
+		fileName = *job.FileName
+	}
+	c.Header("Content-Type", export.ContentType(job.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, fileName, file.ModTime(), file)
+}
+
+// exportJob loads the export job named in the path
+func (h *ReportHandler) exportJob(c *gin.Context) (*models.ExportJob, bool) {
+	id, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export job ID"})
+		return nil, false
+	}
+
+	job, err := h.exportJobRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get export job"})
+		return nil, false
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export job not found"})
+		return nil, false
+	}
+
+	return job, true
+}
+
+// setDownloadURL signs a download link for a completed job
+func (h *ReportHandler) setDownloadURL(job *models.ExportJob, now time.Time) {
+	if job.Status != models.ExportCompleted {
+		return
+	}
+	expires := h.exportLinks.Expiry(now)
+	if job.ExpiresAt != nil && expires.After(*job.ExpiresAt) {
+		// The file is deleted before the link would expire
+		expires = *job.ExpiresAt
+	}
+	job.DownloadURL = h.exportLinks.URL(job.ID, expires)
+	job.LinkExpires = &expires
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/TimLai666/surtopya-api/internal/insights"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetSummary handles GET /api/v1/surveys/:id/summary
+// It returns the cached AI summary of the survey's current revision, marked
+// stale if responses arrived after it was generated.
+func (h *ReportHandler) GetSummary(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+
+	insight, err := h.summarizer.Cached(survey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
+		return
+	}
+
+	if insight == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No summary has been generated for this survey version"})
+		return
+	}
+
+	c.JSON(http.StatusOK, insight)
+}
+
+// GenerateSummary handles POST /api/v1/surveys/:id/summary
+// It has the language model summarize the completed responses and caches
+// the summary for the survey's current revision.
+func (h *ReportHandler) GenerateSummary(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+
+	insight, err := h.summarizer.Summarize(c.Request.Context(), survey)
+	if errors.Is(err, insights.ErrNoResponses) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The survey has no completed responses to summarize"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to summarize survey %s: %v", survey.ID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to generate summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, insight)
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OrganizationHandler handles organization (team workspace) requests
+type OrganizationHandler struct {
+	repo       *repository.OrganizationRepository
+	userRepo   *repository.UserRepository
+	surveyRepo *repository.SurveyRepository
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler
+func NewOrganizationHandler() *OrganizationHandler {
+	db := database.GetDB()
+	return &OrganizationHandler{
+		repo:       repository.NewOrganizationRepository(db),
+		userRepo:   repository.NewUserRepository(db),
+		surveyRepo: repository.NewSurveyRepository(db),
+	}
+}
+
+// OrganizationRequest represents the request body for creating or renaming an organization
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateOrganization handles POST /api/v1/organizations
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	creatorID := userID.(uuid.UUID)
+	org := &models.Organization{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: &creatorID,
+	}
+
+	if err := h.repo.Create(org); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+// GetMyOrganizations handles GET /api/v1/organizations
+func (h *OrganizationHandler) GetMyOrganizations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	orgs, err := h.repo.GetByUserID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+// GetOrganization handles GET /api/v1/organizations/:id
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// UpdateOrganization handles PUT /api/v1/organizations/:id
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	org.Name = strings.TrimSpace(req.Name)
+	if err := h.repo.Update(org); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization handles DELETE /api/v1/organizations/:id
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(org.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// GetOrganizationSurveys handles GET /api/v1/organizations/:id/surveys
+func (h *OrganizationHandler) GetOrganizationSurveys(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	surveys, err := h.surveyRepo.GetByOrganizationID(org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get surveys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"surveys": surveys})
+}
+
+// GetMembers handles GET /api/v1/organizations/:id/members
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.GetMembers(org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// AddMemberRequest represents the request body for adding a member
+type AddMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// AddMember handles POST /api/v1/organizations/:id/members
+// The user must have signed in at least once so that their account exists.
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidAccessRoles, req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	user, err := h.userRepo.GetByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return
+	}
+
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	role, err := h.repo.GetMemberRole(org.ID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get member"})
+		return
+	}
+
+	if role != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	if err := h.repo.SetMember(org.ID, user.ID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Role:           req.Role,
+		Email:          user.Email,
+		DisplayName:    user.DisplayName,
+	})
+}
+
+// UpdateMemberRequest represents the request body for changing a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateMember handles PUT /api/v1/organizations/:id/members/:userId
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	memberID, role, ok := h.getMember(c, org)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidAccessRoles, req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	if role == models.AccessRoleOwner && req.Role != models.AccessRoleOwner && !h.hasOtherOwner(c, org) {
+		return
+	}
+
+	if err := h.repo.SetMember(org.ID, memberID, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizationId": org.ID, "userId": memberID, "role": req.Role})
+}
+
+// RemoveMember handles DELETE /api/v1/organizations/:id/members/:userId
+// Owners can remove anyone; other members can only leave.
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	org, ok := h.getMemberOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	memberID, role, ok := h.getMember(c, org)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if org.Role != models.AccessRoleOwner && memberID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if role == models.AccessRoleOwner && !h.hasOtherOwner(c, org) {
+		return
+	}
+
+	if err := h.repo.RemoveMember(org.ID, memberID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// getMemberOrganization loads the organization from the :id param and checks that
+// the current user is a member (or an owner, if ownerOnly); org.Role is set to their role
+func (h *OrganizationHandler) getMemberOrganization(c *gin.Context, ownerOnly bool) (*models.Organization, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	org, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization"})
+		return nil, false
+	}
+
+	if org == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return nil, false
+	}
+
+	role, err := h.repo.GetMemberRole(org.ID, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organization access"})
+		return nil, false
+	}
+
+	if role == "" || (ownerOnly && role != models.AccessRoleOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	org.Role = role
+	return org, true
+}
+
+// getMember parses the :userId param and returns the member's current role
+func (h *OrganizationHandler) getMember(c *gin.Context, org *models.Organization) (uuid.UUID, string, bool) {
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, "", false
+	}
+
+	role, err := h.repo.GetMemberRole(org.ID, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get member"})
+		return uuid.Nil, "", false
+	}
+
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return uuid.Nil, "", false
+	}
+
+	return memberID, role, true
+}
+
+// hasOtherOwner checks that removing or demoting one owner leaves the organization with an owner
+func (h *OrganizationHandler) hasOtherOwner(c *gin.Context, org *models.Organization) bool {
+	owners, err := h.repo.CountOwners(org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check owners"})
+		return false
+	}
+
+	if owners <= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An organization must keep at least one owner"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ProfileHandler handles respondent profile requests
+type ProfileHandler struct {
+	repo *repository.ProfileRepository
+}
+
+// NewProfileHandler creates a new ProfileHandler
+func NewProfileHandler() *ProfileHandler {
+	return &ProfileHandler{
+		repo: repository.NewProfileRepository(database.GetDB()),
+	}
+}
+
+// UpdateProfileRequest represents the request body for updating a respondent profile
+type UpdateProfileRequest struct {
+	BirthYear *int     `json:"birthYear"`
+	Gender    *string  `json:"gender"`
+	Region    *string  `json:"region"`
+	Interests []string `json:"interests"`
+	Consented bool     `json:"consented"`
+}
+
+// GetProfile handles GET /api/v1/profile
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	profile, err := h.repo.GetByUserID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile"})
+		return
+	}
+
+	if profile == nil {
+		c.JSON(http.StatusOK, &models.RespondentProfile{
+			UserID:    userID.(uuid.UUID),
+			Interests: []string{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile handles PUT /api/v1/profile
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Demographic attributes are only stored with explicit consent
+	if !req.Consented {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Consent is required to store demographic attributes"})
+		return
+	}
+
+	if req.BirthYear != nil && (*req.BirthYear < 1900 || *req.BirthYear > time.Now().Year()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid birth year"})
+		return
+	}
+
+	if req.Gender != nil && !contains(models.ValidGenders, *req.Gender) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gender"})
+		return
+	}
+
+	existing, err := h.repo.GetByUserID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile"})
+		return
+	}
+
+	// Keep the original consent time unless consent is being given for the first time
+	consentedAt := time.Now()
+	if existing != nil && existing.Consented && existing.ConsentedAt != nil {
+		consentedAt = *existing.ConsentedAt
+	}
+
+	profile := &models.RespondentProfile{
+		UserID:      userID.(uuid.UUID),
+		BirthYear:   req.BirthYear,
+		Gender:      req.Gender,
+		Region:      req.Region,
+		Interests:   normalizeTags(req.Interests),
+		Consented:   true,
+		ConsentedAt: &consentedAt,
+	}
+
+	if err := h.repo.Upsert(profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile handles DELETE /api/v1/profile (withdraws consent and removes attributes)
+func (h *ProfileHandler) DeleteProfile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.repo.Delete(userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profile deleted successfully"})
+}
+
+// validateTargetAudience checks a target audience and returns nil when it does not restrict anything
+func validateTargetAudience(c *gin.Context, audience *models.TargetAudience) (*models.TargetAudience, bool) {
+	if audience == nil {
+		return nil, true
+	}
+
+	if (audience.MinAge != nil && *audience.MinAge < 0) || (audience.MaxAge != nil && *audience.MaxAge < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target age"})
+		return nil, false
+	}
+	if audience.MinAge != nil && audience.MaxAge != nil && *audience.MinAge > *audience.MaxAge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum age cannot be greater than maximum age"})
+		return nil, false
+	}
+	for _, gender := range audience.Genders {
+		if !contains(models.ValidGenders, gender) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target gender"})
+			return nil, false
+		}
+	}
+
+	audience.Interests = normalizeTags(audience.Interests)
+
+	if audience.MinAge == nil && audience.MaxAge == nil && len(audience.Genders) == 0 &&
+		len(audience.Regions) == 0 && len(audience.Interests) == 0 {
+		return nil, true
+	}
+
+	return audience, true
+}
+
+// normalizeTags lowercases, trims and de-duplicates free-form tags
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
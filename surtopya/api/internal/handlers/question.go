@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QuestionHandler handles edits to individual survey questions.
+// Questions keep their IDs, so answers to them are preserved.
+type QuestionHandler struct {
+	repo       *repository.QuestionRepository
+	surveyRepo *repository.SurveyRepository
+}
+
+// NewQuestionHandler creates a new QuestionHandler
+func NewQuestionHandler() *QuestionHandler {
+	db := database.GetDB()
+	return &QuestionHandler{
+		repo:       repository.NewQuestionRepository(db),
+		surveyRepo: repository.NewSurveyRepository(db),
+	}
+}
+
+// AddQuestionRequest represents the request body for adding a question
+type AddQuestionRequest struct {
+	QuestionRequest
+	Position *int `json:"position"` // Zero-based; appended at the end if omitted
+	Revision *int `json:"revision"` // Alternative to the If-Match header
+}
+
+// AddQuestion handles POST /api/v1/surveys/:id/questions
+func (h *QuestionHandler) AddQuestion(c *gin.Context) {
+	survey, ok := h.getEditableSurvey(c)
+	if !ok {
+		return
+	}
+
+	var req AddQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !contains(models.ValidQuestionTypes, req.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question type"})
+		return
+	}
+
+	baseRevision, ok := h.baseRevision(c, survey, req.Revision)
+	if !ok {
+		return
+	}
+
+	position := -1
+	if req.Position != nil {
+		position = *req.Position
+	}
+
+	question := &models.Question{
+		ID:          uuid.New(),
+		SurveyID:    survey.ID,
+		Type:        req.Type,
+		Title:       req.Title,
+		Description: &req.Description,
+		Options:     req.Options,
+		Required:    req.Required,
+		Points:      req.Points,
+		MaxRating:   req.MaxRating,
+		Logic:       req.Logic,
+		IsScreener:  req.IsScreener,
+		Eligibility: req.Eligibility,
+	}
+
+	revision, err := h.repo.Insert(question, position, baseRevision)
+	if !h.handleEditError(c, survey.ID, baseRevision, err, "Failed to add question") {
+		return
+	}
+
+	h.respond(c, http.StatusCreated, survey.ID, revision, question.ID)
+}
+
+// PatchQuestionRequest represents the request body for changing a question;
+// only the fields present are updated
+type PatchQuestionRequest struct {
+	Type        *string              `json:"type"`
+	Title       *string              `json:"title"`
+	Description *string              `json:"description"`
+	Options     []string             `json:"options"`
+	Required    *bool                `json:"required"`
+	Points      *int                 `json:"points"`
+	MaxRating   *int                 `json:"maxRating"`
+	Logic       []models.LogicRule   `json:"logic"`
+	IsScreener  *bool                `json:"isScreener"`
+	Eligibility *models.ScreenerRule `json:"eligibility"`
+	Revision    *int                 `json:"revision"` // Alternative to the If-Match header
+}
+
+// PatchQuestion handles PATCH /api/v1/surveys/:id/questions/:questionId
+func (h *QuestionHandler) PatchQuestion(c *gin.Context) {
+	survey, ok := h.getEditableSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	var req PatchQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	baseRevision, ok := h.baseRevision(c, survey, req.Revision)
+	if !ok {
+		return
+	}
+
+	if req.Type != nil {
+		if !contains(models.ValidQuestionTypes, *req.Type) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question type"})
+			return
+		}
+		question.Type = *req.Type
+	}
+	if req.Title != nil {
+		question.Title = *req.Title
+	}
+	if req.Description != nil {
+		question.Description = req.Description
+	}
+	if req.Options != nil {
+		question.Options = req.Options
+	}
+	if req.Required != nil {
+		question.Required = *req.Required
+	}
+	if req.Points != nil {
+		question.Points = *req.Points
+	}
+	if req.MaxRating != nil {
+		question.MaxRating = *req.MaxRating
+	}
+	if req.Logic != nil {
+		question.Logic = req.Logic
+	}
+	if req.IsScreener != nil {
+		question.IsScreener = *req.IsScreener
+	}
+	if req.Eligibility != nil {
+		question.Eligibility = req.Eligibility
+	}
+
+	revision, err := h.repo.Update(question, baseRevision)
+	if !h.handleEditError(c, survey.ID, baseRevision, err, "Failed to update question") {
+		return
+	}
+
+	h.respond(c, http.StatusOK, survey.ID, revision, question.ID)
+}
+
+// DeleteQuestion handles DELETE /api/v1/surveys/:id/questions/:questionId
+// Questions that already have answers are only deleted with ?force=true,
+// since their answers are deleted with them. Logic rules that jump to the
+// question are removed from the other questions.
+func (h *QuestionHandler) DeleteQuestion(c *gin.Context) {
+	survey, ok := h.getEditableSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	baseRevision, ok := h.baseRevision(c, survey, nil)
+	if !ok {
+		return
+	}
+
+	if c.Query("force") != "true" {
+		answers, err := h.repo.CountAnswers(question.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count answers"})
+			return
+		}
+		if answers > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       fmt.Sprintf("Question has %d answers; delete with force=true to remove them", answers),
+				"answerCount": answers,
+			})
+			return
+		}
+	}
+
+	revision, err := h.repo.Delete(survey.ID, question.ID, baseRevision)
+	if !h.handleEditError(c, survey.ID, baseRevision, err, "Failed to delete question") {
+		return
+	}
+
+	h.respond(c, http.StatusOK, survey.ID, revision, uuid.Nil)
+}
+
+// MoveQuestionRequest represents the request body for moving a question
+type MoveQuestionRequest struct {
+	Position *int `json:"position" binding:"required"` // Zero-based target position
+	Revision *int `json:"revision"`
+}
+
+// MoveQuestion handles POST /api/v1/surveys/:id/questions/:questionId/move
+func (h *QuestionHandler) MoveQuestion(c *gin.Context) {
+	survey, ok := h.getEditableSurvey(c)
+	if !ok {
+		return
+	}
+
+	question, ok := h.getQuestion(c, survey)
+	if !ok {
+		return
+	}
+
+	var req MoveQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	baseRevision, ok := h.baseRevision(c, survey, req.Revision)
+	if !ok {
+		return
+	}
+
+	revision, err := h.repo.Move(survey.ID, question.ID, *req.Position, baseRevision)
+	if !h.handleEditError(c, survey.ID, baseRevision, err, "Failed to move question") {
+		return
+	}
+
+	h.respond(c, http.StatusOK, survey.ID, revision, question.ID)
+}
+
+// ReorderQuestionsRequest represents the request body for reordering all questions
+type ReorderQuestionsRequest struct {
+	QuestionIDs []uuid.UUID `json:"questionIds" binding:"required"`
+	Revision    *int        `json:"revision"`
+}
+
+// ReorderQuestions handles PUT /api/v1/surveys/:id/questions/order
+func (h *QuestionHandler) ReorderQuestions(c *gin.Context) {
+	survey, ok := h.getEditableSurvey(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderQuestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	baseRevision, ok := h.baseRevision(c, survey, req.Revision)
+	if !ok {
+		return
+	}
+
+	revision, err := h.repo.Reorder(survey.ID, req.QuestionIDs, baseRevision)
+	if err == repository.ErrQuestionOrderMismatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.handleEditError(c, survey.ID, baseRevision, err, "Failed to reorder questions") {
+		return
+	}
+
+	h.respond(c, http.StatusOK, survey.ID, revision, uuid.Nil)
+}
+
+// getEditableSurvey loads the survey from the :id param and checks that the current user can edit it
+func (h *QuestionHandler) getEditableSurvey(c *gin.Context) (*models.Survey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionEdit) {
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// getQuestion loads the question from the :questionId param and checks that it belongs to the survey
+func (h *QuestionHandler) getQuestion(c *gin.Context, survey *models.Survey) (*models.Question, bool) {
+	questionID, err := uuid.Parse(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return nil, false
+	}
+
+	question, err := h.repo.GetByID(survey.ID, questionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get question"})
+		return nil, false
+	}
+
+	if question == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return nil, false
+	}
+
+	return question, true
+}
+
+// baseRevision returns the revision the edit is based on (the loaded revision
+// if the client sent none), rejecting edits based on a stale revision
+func (h *QuestionHandler) baseRevision(c *gin.Context, survey *models.Survey, bodyRevision *int) (int, bool) {
+	revision, hasBase, err := expectedRevision(c, bodyRevision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if !hasBase {
+		return survey.Revision, true
+	}
+	if revision != survey.Revision {
+		respondRevisionConflict(c, h.surveyRepo, survey.ID, revision)
+		return 0, false
+	}
+	return revision, true
+}
+
+// handleEditError writes the response for a failed edit and reports whether the edit succeeded
+func (h *QuestionHandler) handleEditError(c *gin.Context, surveyID uuid.UUID, baseRevision int, err error, message string) bool {
+	if err == repository.ErrRevisionConflict {
+		respondRevisionConflict(c, h.surveyRepo, surveyID, baseRevision)
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+		return false
+	}
+	return true
+}
+
+// respond records the new survey revision and returns the renumbered
+// questions, plus the edited question when questionID is set
+func (h *QuestionHandler) respond(c *gin.Context, status int, surveyID uuid.UUID, revision int, questionID uuid.UUID) {
+	survey, err := h.surveyRepo.GetByID(surveyID)
+	if err != nil || survey == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	// Another edit may have landed in between; snapshot the revision we wrote only
+	if survey.Revision == revision {
+		recordRevision(c, h.surveyRepo, survey)
+	}
+
+	body := gin.H{"revision": revision, "questions": survey.Questions}
+	for _, q := range survey.Questions {
+		if q.ID == questionID {
+			body["question"] = q
+		}
+	}
+
+	c.JSON(status, body)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QuotaHandler handles survey quota requests
+type QuotaHandler struct {
+	quotaRepo  *repository.QuotaRepository
+	surveyRepo *repository.SurveyRepository
+}
+
+// NewQuotaHandler creates a new QuotaHandler
+func NewQuotaHandler() *QuotaHandler {
+	db := database.GetDB()
+	return &QuotaHandler{
+		quotaRepo:  repository.NewQuotaRepository(db),
+		surveyRepo: repository.NewSurveyRepository(db),
+	}
+}
+
+// QuotaRequest represents the request body for creating or updating a quota
+type QuotaRequest struct {
+	Name             string  `json:"name"`
+	QuestionID       string  `json:"questionId"`
+	OptionValue      string  `json:"optionValue"`
+	Target           int     `json:"target"`
+	ScreenOutMessage *string `json:"screenOutMessage"`
+	IsActive         *bool   `json:"isActive"`
+}
+
+// GetQuotas handles GET /api/v1/surveys/:id/quotas
+func (h *QuotaHandler) GetQuotas(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionView)
+	if !ok {
+		return
+	}
+
+	quotas, err := h.quotaRepo.GetBySurveyID(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quotas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas})
+}
+
+// CreateQuota handles POST /api/v1/surveys/:id/quotas
+func (h *QuotaHandler) CreateQuota(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionEdit)
+	if !ok {
+		return
+	}
+
+	var req QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	quota := &models.SurveyQuota{
+		ID:       uuid.New(),
+		SurveyID: survey.ID,
+		IsActive: true,
+	}
+	if !applyQuotaRequest(c, survey, quota, &req) {
+		return
+	}
+
+	if err := h.quotaRepo.Create(quota); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quota"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, quota)
+}
+
+// UpdateQuota handles PUT /api/v1/surveys/:id/quotas/:quotaId
+func (h *QuotaHandler) UpdateQuota(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionEdit)
+	if !ok {
+		return
+	}
+
+	quota, ok := h.getSurveyQuota(c, survey)
+	if !ok {
+		return
+	}
+
+	var req QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if !applyQuotaRequest(c, survey, quota, &req) {
+		return
+	}
+
+	if err := h.quotaRepo.Update(quota); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// DeleteQuota handles DELETE /api/v1/surveys/:id/quotas/:quotaId
+func (h *QuotaHandler) DeleteQuota(c *gin.Context) {
+	survey, ok := h.getAuthorizedSurvey(c, permissionEdit)
+	if !ok {
+		return
+	}
+
+	quota, ok := h.getSurveyQuota(c, survey)
+	if !ok {
+		return
+	}
+
+	if err := h.quotaRepo.Delete(quota.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quota deleted successfully"})
+}
+
+// getAuthorizedSurvey loads the survey from the :id param and checks that the current user holds the permission on it
+func (h *QuotaHandler) getAuthorizedSurvey(c *gin.Context, permission string) (*models.Survey, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permission) {
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// getSurveyQuota loads the quota from the :quotaId param and checks that it belongs to the survey
+func (h *QuotaHandler) getSurveyQuota(c *gin.Context, survey *models.Survey) (*models.SurveyQuota, bool) {
+	quotaID, err := uuid.Parse(c.Param("quotaId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quota ID"})
+		return nil, false
+	}
+
+	quota, err := h.quotaRepo.GetByID(quotaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quota"})
+		return nil, false
+	}
+
+	if quota == nil || quota.SurveyID != survey.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quota not found"})
+		return nil, false
+	}
+
+	return quota, true
+}
+
+// applyQuotaRequest validates the request against the survey's questions and copies it onto the quota
+func applyQuotaRequest(c *gin.Context, survey *models.Survey, quota *models.SurveyQuota, req *QuotaRequest) bool {
+	if req.Target <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quota target must be greater than zero"})
+		return false
+	}
+
+	quota.QuestionID = nil
+	quota.OptionValue = nil
+
+	if req.QuestionID != "" {
+		questionID, err := uuid.Parse(req.QuestionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+			return false
+		}
+
+		var question *models.Question
+		for i := range survey.Questions {
+			if survey.Questions[i].ID == questionID {
+				question = &survey.Questions[i]
+				break
+			}
+		}
+		if question == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Question not found in survey"})
+			return false
+		}
+
+		if question.Type != "single" && question.Type != "multi" && question.Type != "select" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quotas can only be based on single, multi or select questions"})
+			return false
+		}
+
+		found := false
+		for _, option := range question.Options {
+			if option == req.OptionValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Option not found in question"})
+			return false
+		}
+
+		optionValue := req.OptionValue
+		quota.QuestionID = &questionID
+		quota.OptionValue = &optionValue
+	}
+
+	quota.Name = req.Name
+	quota.Target = req.Target
+	quota.ScreenOutMessage = req.ScreenOutMessage
+	if req.IsActive != nil {
+		quota.IsActive = *req.IsActive
+	}
+
+	return true
+}
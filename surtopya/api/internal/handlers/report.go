@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/exports"
+	"github.com/TimLai666/surtopya-api/internal/insights"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/TimLai666/surtopya-api/internal/storage"
+	"github.com/TimLai666/surtopya-api/internal/weighting"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReportHandler handles survey result analytics requests
+type ReportHandler struct {
+	reportRepo    *repository.ReportRepository
+	surveyRepo    *repository.SurveyRepository
+	codingRepo    *repository.CodingRepository
+	weightingRepo *repository.WeightingRepository
+	exportJobRepo *repository.ExportJobRepository
+	exportStore   storage.Store
+	exportLinks   *exports.Links
+	summarizer    *insights.Summarizer
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler() *ReportHandler {
+	db := database.GetDB()
+	exportConfig := exports.LoadConfigFromEnv()
+	return &ReportHandler{
+		reportRepo:    repository.NewReportRepository(db),
+		surveyRepo:    repository.NewSurveyRepository(db),
+		codingRepo:    repository.NewCodingRepository(db),
+		weightingRepo: repository.NewWeightingRepository(db),
+		exportJobRepo: repository.NewExportJobRepository(db),
+		exportStore:   storage.NewDisk(exportConfig.StorageDir),
+		exportLinks:   exports.NewLinks(exportConfig),
+		summarizer:    insights.NewSummarizer(db, insights.New(insights.LoadConfigFromEnv())),
+	}
+}
+
+// dateIntervals are the supported date histogram intervals
+var dateIntervals = map[string]bool{"day": true, "week": true, "month": true, "year": true}
+
+// maxSegmentConditions limits the conditions of a segment
+const maxSegmentConditions = 10
+
+// GetSurveyReport handles GET /api/v1/surveys/:id/report
+// It returns per-question aggregates of the answers. Query parameters:
+// from and to (RFC 3339 or YYYY-MM-DD, to is inclusive for dates), status
+// (comma-separated, "all", default completed), segment (a JSON array of
+// segment conditions), dateInterval (day, week, month or year, default
+// month), words (top words per text question, default 20, at most 100),
+// confidence (level of the confidence intervals, default 0.95) and weighted
+// (true to add estimates raked to the survey's weighting scheme).
+func (h *ReportHandler) GetSurveyReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	opts := repository.ReportOptions{DateInterval: c.DefaultQuery("dateInterval", "month")}
+	if !dateIntervals[opts.DateInterval] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dateInterval must be day, week, month or year"})
+		return
+	}
+	opts.TopWords, err = strconv.Atoi(c.DefaultQuery("words", "20"))
+	if err != nil || opts.TopWords < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "words must be a non-negative number"})
+		return
+	}
+	if opts.TopWords > 100 {
+		opts.TopWords = 100
+	}
+	if opts.Confidence, ok = parseConfidence(c); !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	report, err := h.reportRepo.GetReport(survey, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	if c.Query("weighted") == "true" {
+		result, ok := h.computeWeights(c, survey, filter, true)
+		if !ok {
+			return
+		}
+		records, err := h.reportRepo.GetResponseRecords(survey.ID, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+			return
+		}
+		weighting.Apply(report, survey, records, result)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// crosstabTypes are the question types that can be cross-tabulated
+var crosstabTypes = map[string]bool{"single": true, "multi": true, "select": true, "rating": true}
+
+// GetCrosstab handles GET /api/v1/surveys/:id/crosstab
+// It cross-tabulates the question ?row= by the question ?column= (choice or
+// rating questions), with row and column percentages and a chi-square test.
+// It takes the same from, to, status and segment filters as the report.
+func (h *ReportHandler) GetCrosstab(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	rowID, err := uuid.Parse(c.Query("row"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid row question ID"})
+		return
+	}
+	columnID, err := uuid.Parse(c.Query("column"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column question ID"})
+		return
+	}
+	if rowID == columnID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Row and column must be different questions"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	row, column := findQuestion(survey, rowID), findQuestion(survey, columnID)
+	if row == nil || column == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+	if !crosstabTypes[row.Type] || !crosstabTypes[column.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only choice and rating questions can be cross-tabulated"})
+		return
+	}
+
+	crosstab, err := h.reportRepo.GetCrosstab(survey, *row, *column, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build crosstab"})
+		return
+	}
+
+	c.JSON(http.StatusOK, crosstab)
+}
+
+// GetFunnel handles GET /api/v1/surveys/:id/funnel
+// It shows how many respondents reached each question and section, where
+// they dropped off and how long each question took, following each
+// response's skip-logic path. It takes the same from, to, status and segment
+// filters as the report, except that status defaults to all.
+func (h *ReportHandler) GetFunnel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "all")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	funnel, err := h.reportRepo.GetFunnel(survey, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build funnel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, funnel)
+}
+
+// resultsSurvey loads a survey whose results the caller may read,
+// responding with an error if not
+func (h *ReportHandler) resultsSurvey(c *gin.Context, id uuid.UUID) (*models.Survey, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return nil, false
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return nil, false
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionResults) {
+		return nil, false
+	}
+
+	return survey, true
+}
+
+// parseReportFilter reads the from, to, status and segment query parameters.
+// defaultStatus applies when status is not given.
+func parseReportFilter(c *gin.Context, defaultStatus string) (models.ReportFilter, bool) {
+	var filter models.ReportFilter
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			date, dateErr := time.Parse("2006-01-02", value)
+			if dateErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " time, use RFC 3339 or YYYY-MM-DD"})
+				return filter, false
+			}
+			t = date
+			if param == "to" {
+				// A date includes the whole day
+				t = date.AddDate(0, 0, 1)
+			}
+		}
+		if param == "from" {
+			filter.From = &t
+		} else {
+			filter.To = &t
+		}
+	}
+
+	status := c.DefaultQuery("status", defaultStatus)
+	if status != "all" {
+		for _, s := range strings.Split(status, ",") {
+			s = strings.TrimSpace(s)
+			if !contains(models.ValidResponseStatuses, s) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status " + s})
+				return filter, false
+			}
+			filter.Statuses = append(filter.Statuses, s)
+		}
+	}
+
+	if segment := c.Query("segment"); segment != "" {
+		if err := json.Unmarshal([]byte(segment), &filter.Segment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "segment must be a JSON array of conditions"})
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
+
+// validateSegment checks segment conditions against the survey's questions
+func validateSegment(c *gin.Context, survey *models.Survey, segment []models.SegmentCondition) bool {
+	if len(segment) > maxSegmentConditions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A segment has at most %d conditions", maxSegmentConditions)})
+		return false
+	}
+
+	for _, condition := range segment {
+		question := findQuestion(survey, condition.QuestionID)
+		if question == nil || question.Type == "section" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Segment question not found: " + condition.QuestionID.String()})
+			return false
+		}
+
+		switch condition.Operator {
+		case models.SegmentIn, models.SegmentNotIn:
+			if len(condition.Values) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Segment operator " + condition.Operator + " needs values"})
+				return false
+			}
+		case models.SegmentBetween:
+			if question.Type != "rating" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Segment operator between only applies to rating questions"})
+				return false
+			}
+			if condition.Min == nil && condition.Max == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Segment operator between needs min or max"})
+				return false
+			}
+		case models.SegmentAnswered, models.SegmentNotAnswered:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid segment operator " + condition.Operator})
+			return false
+		}
+	}
+
+	return true
+}
+
+// findQuestion returns the survey question with the given ID, or nil
+func findQuestion(survey *models.Survey, id uuid.UUID) *models.Question {
+	for i := range survey.Questions {
+		if survey.Questions[i].ID == id {
+			return &survey.Questions[i]
+		}
+	}
+	return nil
+}
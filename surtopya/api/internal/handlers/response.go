@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/TimLai666/surtopya-api/internal/database"
@@ -15,6 +19,8 @@ import (
 type ResponseHandler struct {
 	responseRepo *repository.ResponseRepository
 	surveyRepo   *repository.SurveyRepository
+	profileRepo  *repository.ProfileRepository
+	gracePeriod  time.Duration
 }
 
 // NewResponseHandler creates a new ResponseHandler
@@ -23,9 +29,38 @@ func NewResponseHandler() *ResponseHandler {
 	return &ResponseHandler{
 		responseRepo: repository.NewResponseRepository(db),
 		surveyRepo:   repository.NewSurveyRepository(db),
+		profileRepo:  repository.NewProfileRepository(db),
+		gracePeriod:  loadGracePeriod(),
 	}
 }
 
+// loadGracePeriod reads how long in-progress responses may still be submitted after a survey closes
+func loadGracePeriod() time.Duration {
+	gracePeriod := 10 * time.Minute
+	if value := os.Getenv("SUBMISSION_GRACE_PERIOD"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			log.Printf("Invalid SUBMISSION_GRACE_PERIOD %q, using %s", value, gracePeriod)
+		} else {
+			gracePeriod = d
+		}
+	}
+	return gracePeriod
+}
+
+// submissionClosed reports whether a survey closed longer ago than the grace period
+func (h *ResponseHandler) submissionClosed(survey *models.Survey) bool {
+	var closesAt *time.Time
+	if survey.ExpiresAt != nil {
+		closesAt = survey.ExpiresAt
+	}
+	if survey.ClosedAt != nil && (closesAt == nil || survey.ClosedAt.Before(*closesAt)) {
+		closesAt = survey.ClosedAt
+	}
+
+	return closesAt != nil && time.Now().After(closesAt.Add(h.gracePeriod))
+}
+
 // StartResponseRequest represents the request to start a survey response
 type StartResponseRequest struct {
 	AnonymousID string `json:"anonymousId,omitempty"`
@@ -73,6 +108,50 @@ func (h *ResponseHandler) StartResponse(c *gin.Context) {
 		userID = &id
 	}
 
+	// Targeted surveys only admit users whose profile matches the audience
+	if survey.TargetAudience != nil {
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required for targeted surveys"})
+			return
+		}
+
+		matches, err := h.profileRepo.MatchesAudience(*userID, survey.TargetAudience)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check target audience"})
+			return
+		}
+		if !matches {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not in the target audience for this survey"})
+			return
+		}
+	}
+
+	// Logged-in users continue their existing in-progress response
+	if userID != nil {
+		existing, err := h.responseRepo.GetInProgressByUser(surveyID, *userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get response"})
+			return
+		}
+
+		if existing != nil {
+			// Only the token hash is stored, so a fresh token is issued
+			token, err := newToken()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume response"})
+				return
+			}
+			if err := h.responseRepo.SetResumeToken(existing.ID, token); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume response"})
+				return
+			}
+			existing.ResumeToken = &token
+
+			c.JSON(http.StatusOK, existing)
+			return
+		}
+	}
+
 	// Generate anonymous ID if not authenticated and not provided
 	var anonymousID *string
 	if userID == nil {
@@ -93,6 +172,13 @@ func (h *ResponseHandler) StartResponse(c *gin.Context) {
 		StartedAt:   time.Now(),
 	}
 
+	token, err := newToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start response"})
+		return
+	}
+	response.ResumeToken = &token
+
 	if err := h.responseRepo.Create(response); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start response"})
 		return
@@ -101,6 +187,79 @@ func (h *ResponseHandler) StartResponse(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// ResumeResponseRequest represents the request to resume a response on another device
+type ResumeResponseRequest struct {
+	ResumeToken string `json:"resumeToken" binding:"required"`
+}
+
+// ResumeResponse handles POST /api/v1/responses/resume
+func (h *ResponseHandler) ResumeResponse(c *gin.Context) {
+	var req ResumeResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	response, err := h.responseRepo.GetByResumeToken(req.ResumeToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get response"})
+		return
+	}
+
+	if response == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Response not found"})
+		return
+	}
+
+	if response.Status != "in_progress" && response.Status != "abandoned" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Response is already completed"})
+		return
+	}
+
+	survey, err := h.surveyRepo.GetByID(response.SurveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if h.submissionClosed(survey) {
+		c.JSON(http.StatusGone, gin.H{"error": "Survey is closed"})
+		return
+	}
+
+	// A response swept as abandoned can be picked up again while the survey is open
+	if response.Status == "abandoned" {
+		if !survey.IsPublished {
+			c.JSON(http.StatusGone, gin.H{"error": "Survey is closed"})
+			return
+		}
+		if err := h.responseRepo.Reactivate(response.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume response"})
+			return
+		}
+		response.Status = "in_progress"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+		"survey":   survey,
+	})
+}
+
+// newToken generates a random secret token for resume sessions and invitations
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // SubmitAnswerRequest represents a single answer submission
 type SubmitAnswerRequest struct {
 	QuestionID string             `json:"questionId" binding:"required"`
@@ -127,11 +286,32 @@ func (h *ResponseHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
+	if !h.isRespondent(c, response) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if response.Status == "abandoned" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Response was abandoned; resume it to continue"})
+		return
+	}
+
 	if response.Status != "in_progress" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Response is already completed"})
 		return
 	}
 
+	survey, err := h.surveyRepo.GetByID(response.SurveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey != nil && h.submissionClosed(survey) {
+		c.JSON(http.StatusGone, gin.H{"error": "Survey is closed"})
+		return
+	}
+
 	var req SubmitAnswerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -156,6 +336,12 @@ func (h *ResponseHandler) SubmitAnswer(c *gin.Context) {
 		return
 	}
 
+	// Terminate early if the answer fails a screener question
+	if survey != nil && failsScreener(survey.Questions, []models.Answer{*answer}) {
+		h.disqualify(c, survey, responseID)
+		return
+	}
+
 	c.JSON(http.StatusOK, answer)
 }
 
@@ -184,11 +370,33 @@ func (h *ResponseHandler) SubmitAllAnswers(c *gin.Context) {
 		return
 	}
 
+	if !h.isRespondent(c, response) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if response.Status == "abandoned" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Response was abandoned; resume it to continue"})
+		return
+	}
+
 	if response.Status != "in_progress" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Response is already completed"})
 		return
 	}
 
+	// Get survey to check the submission window and award points
+	survey, err := h.surveyRepo.GetByID(response.SurveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey != nil && h.submissionClosed(survey) {
+		c.JSON(http.StatusGone, gin.H{"error": "Survey is closed"})
+		return
+	}
+
 	var req SubmitAllAnswersRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -217,10 +425,9 @@ func (h *ResponseHandler) SubmitAllAnswers(c *gin.Context) {
 		return
 	}
 
-	// Get survey to award points
-	survey, err := h.surveyRepo.GetByID(response.SurveyID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+	// Disqualify if any answer fails a screener question
+	if survey != nil && failsScreener(survey.Questions, answers) {
+		h.disqualify(c, survey, responseID)
 		return
 	}
 
@@ -230,26 +437,161 @@ func (h *ResponseHandler) SubmitAllAnswers(c *gin.Context) {
 		pointsAwarded = survey.PointsReward
 	}
 
-	if err := h.responseRepo.Complete(responseID, pointsAwarded); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete response"})
+	result, err := h.responseRepo.Complete(responseID, pointsAwarded)
+	if err == repository.ErrResponseNotInProgress {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Response is already completed"})
 		return
 	}
-
-	// Increment survey response count
-	if err := h.surveyRepo.IncrementResponseCount(response.SurveyID); err != nil {
-		// Log error but don't fail the request
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete response"})
+		return
 	}
 
 	// Get updated response
 	response, _ = h.responseRepo.GetByID(responseID)
 
+	if result.Status == "quota_full" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":       *result.ScreenOutMessage,
+			"response":      response,
+			"pointsAwarded": 0,
+			"screenedOut":   true,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Survey completed successfully",
 		"response":      response,
-		"pointsAwarded": pointsAwarded,
+		"pointsAwarded": result.PointsAwarded,
+		"screenedOut":   false,
 	})
 }
 
+// DefaultDisqualificationMessage is shown when a survey has no disqualification message of its own
+const DefaultDisqualificationMessage = "Thank you for your time. Unfortunately you are not eligible for this survey."
+
+// disqualify marks a response as disqualified and writes the screen-out reply
+func (h *ResponseHandler) disqualify(c *gin.Context, survey *models.Survey, responseID uuid.UUID) {
+	err := h.responseRepo.Disqualify(responseID, survey.ConsolationPoints)
+	if err == repository.ErrResponseNotInProgress {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Response is already completed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disqualify response"})
+		return
+	}
+
+	message := DefaultDisqualificationMessage
+	if survey.DisqualificationMessage != nil && *survey.DisqualificationMessage != "" {
+		message = *survey.DisqualificationMessage
+	}
+
+	response, _ := h.responseRepo.GetByID(responseID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       message,
+		"response":      response,
+		"pointsAwarded": survey.ConsolationPoints,
+		"screenedOut":   true,
+	})
+}
+
+// failsScreener reports whether any answer falls outside the eligibility rule of its screener question
+func failsScreener(questions []models.Question, answers []models.Answer) bool {
+	screeners := make(map[uuid.UUID]models.Question)
+	for _, q := range questions {
+		if q.IsScreener && q.Eligibility != nil {
+			screeners[q.ID] = q
+		}
+	}
+
+	for _, answer := range answers {
+		question, ok := screeners[answer.QuestionID]
+		if !ok {
+			continue
+		}
+		if !isEligible(question, answer.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEligible checks a single answer against a screener question's eligibility rule
+func isEligible(question models.Question, value models.AnswerValue) bool {
+	rule := question.Eligibility
+
+	if len(rule.QualifyingOptions) > 0 {
+		qualifying := make(map[string]bool, len(rule.QualifyingOptions))
+		for _, option := range rule.QualifyingOptions {
+			qualifying[option] = true
+		}
+
+		if value.Value != nil && qualifying[*value.Value] {
+			return true
+		}
+		for _, v := range value.Values {
+			if qualifying[v] {
+				return true
+			}
+		}
+		return false
+	}
+
+	if value.Rating != nil {
+		if rule.MinRating != nil && *value.Rating < *rule.MinRating {
+			return false
+		}
+		if rule.MaxRating != nil && *value.Rating > *rule.MaxRating {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetSurveyStats handles GET /api/v1/surveys/:id/stats
+func (h *ResponseHandler) GetSurveyStats(c *gin.Context) {
+	surveyIDStr := c.Param("id")
+	surveyID, err := uuid.Parse(surveyIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	survey, err := h.surveyRepo.GetByID(surveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionResults) {
+		return
+	}
+
+	stats, err := h.responseRepo.GetStats(surveyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // GetResponse handles GET /api/v1/responses/:id
 func (h *ResponseHandler) GetResponse(c *gin.Context) {
 	responseIDStr := c.Param("id")
@@ -270,9 +612,52 @@ func (h *ResponseHandler) GetResponse(c *gin.Context) {
 		return
 	}
 
+	// The respondent can read their response; members with access to the
+	// survey's results get read-only access
+	if !h.isRespondent(c, response) {
+		survey, err := h.surveyRepo.GetByID(response.SurveyID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if survey == nil || !exists {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionResults) {
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
+// ResponseTokenHeader carries the session token issued by StartResponse
+const ResponseTokenHeader = "X-Response-Token"
+
+// isRespondent reports whether the request comes from the creator of a response:
+// the logged-in user it belongs to, or a holder of its session token
+func (h *ResponseHandler) isRespondent(c *gin.Context, response *models.Response) bool {
+	if userID, exists := c.Get("userID"); exists && response.UserID != nil && *response.UserID == userID.(uuid.UUID) {
+		return true
+	}
+
+	token := c.GetHeader(ResponseTokenHeader)
+	if token == "" {
+		return false
+	}
+
+	matches, err := h.responseRepo.MatchesResumeToken(response.ID, token)
+	if err != nil {
+		log.Printf("Failed to verify response token: %v", err)
+		return false
+	}
+
+	return matches
+}
+
 // GetSurveyResponses handles GET /api/v1/surveys/:id/responses
 func (h *ResponseHandler) GetSurveyResponses(c *gin.Context) {
 	surveyIDStr := c.Param("id")
@@ -282,7 +667,7 @@ func (h *ResponseHandler) GetSurveyResponses(c *gin.Context) {
 		return
 	}
 
-	// Check if user owns the survey
+	// Check if user can read the survey's results
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -300,8 +685,7 @@ func (h *ResponseHandler) GetSurveyResponses(c *gin.Context) {
 		return
 	}
 
-	if survey.UserID != userID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionResults) {
 		return
 	}
 
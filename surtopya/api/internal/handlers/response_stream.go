@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// streamPingInterval keeps idle streams open through proxies
+const streamPingInterval = 25 * time.Second
+
+// StreamResponses handles GET /api/v1/surveys/:id/responses/stream
+// It is a Server-Sent Events stream for the survey owner's live dashboard:
+// a "ready" event with the completed count, then a "response" event with the
+// aggregate deltas of each newly completed response. A "resync" event means
+// events may have been missed and the report should be reloaded.
+func (h *ReportHandler) StreamResponses(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	role, err := h.surveyRepo.GetAccessRole(survey, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check survey access"})
+		return
+	}
+	if role != models.AccessRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the survey owner can watch live responses"})
+		return
+	}
+
+	hub := realtime.GetHub()
+	if hub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Live responses are unavailable"})
+		return
+	}
+
+	// Subscribe before counting so no completion falls in between
+	events, unsubscribe := hub.Subscribe(survey.ID)
+	defer unsubscribe()
+
+	completed, err := h.reportRepo.CountCompleted(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count responses"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ready", gin.H{"surveyId": survey.ID, "completed": completed})
+	c.Writer.Flush()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				// Fell too far behind; the client reconnects and reloads
+				return false
+			}
+			c.SSEvent(event.Name, event.Data)
+			return true
+		case <-ping.C:
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FieldChange describes a field whose value differs between two revisions
+type FieldChange struct {
+	Field   string      `json:"field"`
+	Base    interface{} `json:"base"`
+	Current interface{} `json:"current"`
+}
+
+// QuestionChange describes the changed fields of a question present in both revisions
+type QuestionChange struct {
+	ID     uuid.UUID     `json:"id"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// QuestionChanges describes how the questions changed between two revisions
+type QuestionChanges struct {
+	Added     []models.Question `json:"added"`
+	Removed   []uuid.UUID       `json:"removed"`
+	Modified  []QuestionChange  `json:"modified"`
+	Reordered bool              `json:"reordered"`
+}
+
+// SurveyDiff describes what changed on the server since a client's base revision
+type SurveyDiff struct {
+	Fields    []FieldChange   `json:"fields"`
+	Questions QuestionChanges `json:"questions"`
+}
+
+type surveyField struct {
+	name string
+	get  func(*models.Survey) interface{}
+}
+
+type questionField struct {
+	name string
+	get  func(*models.Question) interface{}
+}
+
+// surveyDiffFields lists the survey settings compared by diffSurveys
+var surveyDiffFields = []surveyField{
+	{"title", func(s *models.Survey) interface{} { return s.Title }},
+	{"description", func(s *models.Survey) interface{} { return s.Description }},
+	{"visibility", func(s *models.Survey) interface{} { return s.Visibility }},
+	{"isPublished", func(s *models.Survey) interface{} { return s.IsPublished }},
+	{"includeInDatasets", func(s *models.Survey) interface{} { return s.IncludeInDatasets }},
+	{"theme", func(s *models.Survey) interface{} { return s.Theme }},
+	{"pointsReward", func(s *models.Survey) interface{} { return s.PointsReward }},
+	{"consolationPoints", func(s *models.Survey) interface{} { return s.ConsolationPoints }},
+	{"disqualificationMessage", func(s *models.Survey) interface{} { return s.DisqualificationMessage }},
+	{"targetAudience", func(s *models.Survey) interface{} { return s.TargetAudience }},
+	{"organizationId", func(s *models.Survey) interface{} { return s.OrganizationID }},
+	{"opensAt", func(s *models.Survey) interface{} { return s.OpensAt }},
+	{"expiresAt", func(s *models.Survey) interface{} { return s.ExpiresAt }},
+	{"closedAt", func(s *models.Survey) interface{} { return s.ClosedAt }},
+}
+
+// questionDiffFields lists the question attributes compared by diffSurveys
+var questionDiffFields = []questionField{
+	{"type", func(q *models.Question) interface{} { return q.Type }},
+	{"title", func(q *models.Question) interface{} { return q.Title }},
+	{"description", func(q *models.Question) interface{} { return q.Description }},
+	{"options", func(q *models.Question) interface{} { return q.Options }},
+	{"required", func(q *models.Question) interface{} { return q.Required }},
+	{"points", func(q *models.Question) interface{} { return q.Points }},
+	{"maxRating", func(q *models.Question) interface{} { return q.MaxRating }},
+	{"logic", func(q *models.Question) interface{} { return q.Logic }},
+	{"isScreener", func(q *models.Question) interface{} { return q.IsScreener }},
+	{"eligibility", func(q *models.Question) interface{} { return q.Eligibility }},
+}
+
+// changedField compares two values by their JSON encoding, so nil and empty
+// slices or pointers to equal values are not reported as changes
+func changedField(changes []FieldChange, name string, base, current interface{}) []FieldChange {
+	baseJSON, _ := json.Marshal(base)
+	currentJSON, _ := json.Marshal(current)
+	if bytes.Equal(baseJSON, currentJSON) {
+		return changes
+	}
+	return append(changes, FieldChange{Field: name, Base: base, Current: current})
+}
+
+// diffSurveys reports the changes between a base revision and the current survey
+func diffSurveys(base, current *models.Survey) SurveyDiff {
+	diff := SurveyDiff{
+		Fields: []FieldChange{},
+		Questions: QuestionChanges{
+			Added:    []models.Question{},
+			Removed:  []uuid.UUID{},
+			Modified: []QuestionChange{},
+		},
+	}
+
+	for _, f := range surveyDiffFields {
+		diff.Fields = changedField(diff.Fields, f.name, f.get(base), f.get(current))
+	}
+
+	baseQuestions := make(map[uuid.UUID]*models.Question, len(base.Questions))
+	for i := range base.Questions {
+		baseQuestions[base.Questions[i].ID] = &base.Questions[i]
+	}
+
+	var baseOrder, currentOrder []uuid.UUID
+	for i := range current.Questions {
+		q := &current.Questions[i]
+		bq, ok := baseQuestions[q.ID]
+		if !ok {
+			diff.Questions.Added = append(diff.Questions.Added, *q)
+			continue
+		}
+		currentOrder = append(currentOrder, q.ID)
+		var fields []FieldChange
+		for _, f := range questionDiffFields {
+			fields = changedField(fields, f.name, f.get(bq), f.get(q))
+		}
+		if len(fields) > 0 {
+			diff.Questions.Modified = append(diff.Questions.Modified, QuestionChange{ID: q.ID, Fields: fields})
+		}
+		delete(baseQuestions, q.ID)
+	}
+
+	for _, q := range base.Questions {
+		if _, removed := baseQuestions[q.ID]; removed {
+			diff.Questions.Removed = append(diff.Questions.Removed, q.ID)
+		} else {
+			baseOrder = append(baseOrder, q.ID)
+		}
+	}
+
+	// Questions kept in both revisions are reordered if their relative order differs
+	for i := range currentOrder {
+		if currentOrder[i] != baseOrder[i] {
+			diff.Questions.Reordered = true
+			break
+		}
+	}
+
+	return diff
+}
+
+// errSurveyChanged is returned when a survey changes between loading and saving it
+const errSurveyChanged = "Survey was modified by another request; reload and try again"
+
+// surveyETag returns the entity tag of a survey revision
+func surveyETag(survey *models.Survey) string {
+	return fmt.Sprintf(`"%d"`, survey.Revision)
+}
+
+// expectedRevision reads the revision a client based its edit on, from the
+// If-Match header or the revision in the body. ok is false if neither is set;
+// "If-Match: *" matches any revision.
+func expectedRevision(c *gin.Context, bodyRevision *int) (revision int, ok bool, err error) {
+	if ifMatch := strings.TrimSpace(c.GetHeader("If-Match")); ifMatch != "" && ifMatch != "*" {
+		tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+		revision, err := strconv.Atoi(tag)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid If-Match header %q", ifMatch)
+		}
+		return revision, true, nil
+	}
+
+	if bodyRevision != nil {
+		return *bodyRevision, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// recordRevision snapshots the survey at its new revision, so that editors
+// holding an older revision can be shown what changed, and sets its ETag
+func recordRevision(c *gin.Context, surveyRepo *repository.SurveyRepository, survey *models.Survey) {
+	var userID *uuid.UUID
+	if uid, exists := c.Get("userID"); exists {
+		id := uid.(uuid.UUID)
+		userID = &id
+	}
+
+	if err := surveyRepo.SaveRevision(survey, userID); err != nil {
+		log.Printf("Failed to save revision %d of survey %s: %v", survey.Revision, survey.ID, err)
+	}
+
+	c.Header("ETag", surveyETag(survey))
+}
+
+// respondRevisionConflict rejects a stale edit with the current survey and,
+// when the base revision's snapshot is available, a diff of the server changes
+func respondRevisionConflict(c *gin.Context, surveyRepo *repository.SurveyRepository, surveyID uuid.UUID, baseRevision int) {
+	current, err := surveyRepo.GetByID(surveyID)
+	if err != nil || current == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": errSurveyChanged})
+		return
+	}
+
+	body := gin.H{
+		"error":           "Survey has been modified since revision " + strconv.Itoa(baseRevision),
+		"baseRevision":    baseRevision,
+		"currentRevision": current.Revision,
+		"survey":          current,
+	}
+
+	base, err := surveyRepo.GetRevision(surveyID, baseRevision)
+	if err != nil {
+		log.Printf("Failed to load revision %d of survey %s: %v", baseRevision, surveyID, err)
+	}
+	if base != nil {
+		body["changes"] = diffSurveys(base, current)
+	}
+
+	c.Header("ETag", surveyETag(current))
+	c.JSON(http.StatusConflict, body)
+}
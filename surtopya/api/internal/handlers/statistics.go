@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxCorrelationQuestions limits the size of a correlation matrix
+const maxCorrelationQuestions = 50
+
+// CompareSegments handles GET /api/v1/surveys/:id/compare
+// It compares the ratings of the rating question ?question= between the
+// segments ?segmentA= and ?segmentB= (JSON arrays of segment conditions) with
+// Welch's t-test and the Mann–Whitney U test. The from, to, status and
+// segment filters apply to both groups.
+func (h *ReportHandler) CompareSegments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	questionID, err := uuid.Parse(c.Query("question"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+	confidence, ok := parseConfidence(c)
+	if !ok {
+		return
+	}
+
+	var segments [2][]models.SegmentCondition
+	for i, param := range []string{"segmentA", "segmentB"} {
+		if err := json.Unmarshal([]byte(c.Query(param)), &segments[i]); err != nil || len(segments[i]) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a non-empty JSON array of conditions"})
+			return
+		}
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) ||
+		!validateSegment(c, survey, segments[0]) || !validateSegment(c, survey, segments[1]) {
+		return
+	}
+
+	if question := findQuestion(survey, questionID); question == nil || question.Type != "rating" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Question must be a rating question of the survey"})
+		return
+	}
+
+	comparison := &models.SegmentComparison{SurveyID: survey.ID, QuestionID: questionID, Filter: filter}
+	var ratings [2][]float64
+	for i, segment := range segments {
+		groupFilter := filter
+		groupFilter.Segment = append(append([]models.SegmentCondition{}, filter.Segment...), segment...)
+
+		matrix, err := h.reportRepo.GetRatingMatrix(survey.ID, []uuid.UUID{questionID}, groupFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings"})
+			return
+		}
+		for _, row := range matrix {
+			ratings[i] = append(ratings[i], row[0])
+		}
+
+		group := models.RatingGroup{Segment: segment, N: len(ratings[i])}
+		group.MeanCI, _ = stats.MeanCI(ratings[i], confidence)
+		if i == 0 {
+			comparison.A = group
+		} else {
+			comparison.B = group
+		}
+	}
+
+	comparison.TTest, _ = stats.WelchTTest(ratings[0], ratings[1])
+	comparison.MannWhitney, _ = stats.MannWhitneyU(ratings[0], ratings[1])
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetReliability handles GET /api/v1/surveys/:id/reliability
+// It computes Cronbach's alpha of the rating questions ?questions= (comma-
+// separated IDs, at least two) over the responses that rated all of them,
+// with alpha-if-deleted and corrected item-total correlations.
+func (h *ReportHandler) GetReliability(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	questionIDs, ok := parseRatingQuestions(c, survey, false)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.reportRepo.GetRatingMatrix(survey.ID, questionIDs, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings"})
+		return
+	}
+
+	var complete [][]float64
+	for _, row := range matrix {
+		if !hasNaN(row) {
+			complete = append(complete, row)
+		}
+	}
+
+	report := &models.ReliabilityReport{SurveyID: survey.ID, QuestionIDs: questionIDs, Filter: filter}
+	report.Reliability, _ = stats.CronbachAlpha(complete)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCorrelations handles GET /api/v1/surveys/:id/correlations
+// It returns the pairwise correlations of the rating questions ?questions=
+// (comma-separated IDs, default all rating questions) using ?method=
+// pearson (default) or spearman.
+func (h *ReportHandler) GetCorrelations(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	method := c.DefaultQuery("method", stats.MethodPearson)
+	if method != stats.MethodPearson && method != stats.MethodSpearman {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be pearson or spearman"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok || !validateSegment(c, survey, filter.Segment) {
+		return
+	}
+
+	questionIDs, ok := parseRatingQuestions(c, survey, true)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.reportRepo.GetRatingMatrix(survey.ID, questionIDs, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings"})
+		return
+	}
+
+	correlate := stats.Pearson
+	if method == stats.MethodSpearman {
+		correlate = stats.Spearman
+	}
+
+	result := &models.CorrelationMatrix{SurveyID: survey.ID, QuestionIDs: questionIDs, Filter: filter, Method: method}
+	result.Correlations = make([][]*stats.Correlation, len(questionIDs))
+	for i := range questionIDs {
+		result.Correlations[i] = make([]*stats.Correlation, len(questionIDs))
+	}
+	for i := range questionIDs {
+		for j := i; j < len(questionIDs); j++ {
+			// Pairwise deletion: use the responses that rated both questions
+			var x, y []float64
+			for _, row := range matrix {
+				if !math.IsNaN(row[i]) && !math.IsNaN(row[j]) {
+					x = append(x, row[i])
+					y = append(y, row[j])
+				}
+			}
+			correlation, _ := correlate(x, y)
+			result.Correlations[i][j] = correlation
+			result.Correlations[j][i] = correlation
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseConfidence reads the ?confidence= level of confidence intervals
+func parseConfidence(c *gin.Context) (float64, bool) {
+	confidence, err := strconv.ParseFloat(c.DefaultQuery("confidence", "0.95"), 64)
+	if err != nil || confidence <= 0 || confidence >= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confidence must be between 0 and 1"})
+		return 0, false
+	}
+	return confidence, true
+}
+
+// parseRatingQuestions reads the comma-separated rating question IDs of
+// ?questions=; when defaultAll is set, no IDs means all rating questions
+func parseRatingQuestions(c *gin.Context, survey *models.Survey, defaultAll bool) ([]uuid.UUID, bool) {
+	var ids []uuid.UUID
+	if value := c.Query("questions"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID " + part})
+				return nil, false
+			}
+			if question := findQuestion(survey, id); question == nil || question.Type != "rating" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Not a rating question of the survey: " + id.String()})
+				return nil, false
+			}
+			ids = append(ids, id)
+		}
+	} else if defaultAll {
+		for _, q := range survey.Questions {
+			if q.Type == "rating" {
+				ids = append(ids, q.ID)
+			}
+		}
+	}
+
+	if len(ids) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least two rating questions are needed"})
+		return nil, false
+	}
+	if len(ids) > maxCorrelationQuestions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many questions"})
+		return nil, false
+	}
+	return ids, true
+}
+
+func hasNaN(values []float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) {
+			return true
+		}
+	}
+	return false
+}
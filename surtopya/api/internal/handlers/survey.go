@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/middleware"
 	"github.com/TimLai666/surtopya-api/internal/models"
 	"github.com/TimLai666/surtopya-api/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -14,13 +15,20 @@ import (
 
 // SurveyHandler handles survey-related requests
 type SurveyHandler struct {
-	repo *repository.SurveyRepository
+	repo        *repository.SurveyRepository
+	profileRepo *repository.ProfileRepository
+	orgRepo     *repository.OrganizationRepository
+	quotaRepo   *repository.QuotaRepository
 }
 
 // NewSurveyHandler creates a new SurveyHandler
 func NewSurveyHandler() *SurveyHandler {
+	db := database.GetDB()
 	return &SurveyHandler{
-		repo: repository.NewSurveyRepository(database.GetDB()),
+		repo:        repository.NewSurveyRepository(db),
+		profileRepo: repository.NewProfileRepository(db),
+		orgRepo:     repository.NewOrganizationRepository(db),
+		quotaRepo:   repository.NewQuotaRepository(db),
 	}
 }
 
@@ -33,6 +41,11 @@ type CreateSurveyRequest struct {
 	Theme             *models.SurveyTheme `json:"theme"`
 	PointsReward      int                 `json:"pointsReward"`
 	Questions         []QuestionRequest   `json:"questions"`
+
+	ConsolationPoints       int                    `json:"consolationPoints"` // Between 0 and pointsReward
+	DisqualificationMessage *string                `json:"disqualificationMessage"`
+	TargetAudience          *models.TargetAudience `json:"targetAudience"`
+	OrganizationID          *uuid.UUID             `json:"organizationId"`
 }
 
 // QuestionRequest represents a question in the request
@@ -46,6 +59,9 @@ type QuestionRequest struct {
 	Points      int                 `json:"points"`
 	MaxRating   int                 `json:"maxRating"`
 	Logic       []models.LogicRule  `json:"logic"`
+
+	IsScreener  bool                 `json:"isScreener"`
+	Eligibility *models.ScreenerRule `json:"eligibility"`
 }
 
 // CreateSurvey handles POST /api/v1/surveys
@@ -63,6 +79,19 @@ func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
 		return
 	}
 
+	if !validateConsolationPoints(c, req.PointsReward, req.ConsolationPoints) {
+		return
+	}
+
+	targetAudience, ok := validateTargetAudience(c, req.TargetAudience)
+	if !ok {
+		return
+	}
+
+	if req.OrganizationID != nil && !canCreateInOrganization(c, h.orgRepo, *req.OrganizationID, userID.(uuid.UUID)) {
+		return
+	}
+
 	// Validate visibility
 	if req.Visibility != "public" && req.Visibility != "non-public" {
 		req.Visibility = "non-public"
@@ -84,6 +113,11 @@ func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
 		PublishedCount:    0,
 		Theme:             req.Theme,
 		PointsReward:      req.PointsReward,
+
+		ConsolationPoints:       req.ConsolationPoints,
+		DisqualificationMessage: req.DisqualificationMessage,
+		TargetAudience:          targetAudience,
+		OrganizationID:          req.OrganizationID,
 	}
 
 	if err := h.repo.Create(survey); err != nil {
@@ -110,6 +144,8 @@ func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
 				Points:      qReq.Points,
 				MaxRating:   qReq.MaxRating,
 				Logic:       qReq.Logic,
+				IsScreener:  qReq.IsScreener,
+				Eligibility: qReq.Eligibility,
 				SortOrder:   i,
 			}
 		}
@@ -121,6 +157,7 @@ func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
 		survey.Questions = questions
 	}
 
+	recordRevision(c, h.repo, survey)
 	c.JSON(http.StatusCreated, survey)
 }
 
@@ -144,19 +181,24 @@ func (h *SurveyHandler) GetSurvey(c *gin.Context) {
 		return
 	}
 
-	// Check access permission
+	// Check access permission (moderators can review any survey)
 	userID, exists := c.Get("userID")
-	if survey.Visibility == "non-public" && !survey.IsPublished {
-		if !exists || survey.UserID != userID.(uuid.UUID) {
+	if survey.Visibility == "non-public" && !survey.IsPublished && !middleware.HasRole(c, "moderator") {
+		if !exists {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 			return
 		}
+		if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionView) {
+			return
+		}
 	}
 
+	c.Header("ETag", surveyETag(survey))
 	c.JSON(http.StatusOK, survey)
 }
 
 // GetMySurveys handles GET /api/v1/surveys/my
+// It lists owned surveys and surveys shared through organizations, with the user's role on each
 func (h *SurveyHandler) GetMySurveys(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -164,7 +206,7 @@ func (h *SurveyHandler) GetMySurveys(c *gin.Context) {
 		return
 	}
 
-	surveys, err := h.repo.GetByUserID(userID.(uuid.UUID))
+	surveys, err := h.repo.GetAccessibleByUserID(userID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get surveys"})
 		return
@@ -182,7 +224,14 @@ func (h *SurveyHandler) GetPublicSurveys(c *gin.Context) {
 		limit = 100
 	}
 
-	surveys, err := h.repo.GetPublicSurveys(limit, offset)
+	// Targeted surveys are only listed for users in their audience
+	var userID *uuid.UUID
+	if uid, exists := c.Get("userID"); exists {
+		id := uid.(uuid.UUID)
+		userID = &id
+	}
+
+	surveys, err := h.repo.GetPublicSurveys(userID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get surveys"})
 		return
@@ -198,9 +247,19 @@ type UpdateSurveyRequest struct {
 	Theme             *models.SurveyTheme `json:"theme"`
 	PointsReward      *int                `json:"pointsReward"`
 	Questions         []QuestionRequest   `json:"questions"`
+
+	ConsolationPoints       *int                   `json:"consolationPoints"` // Between 0 and pointsReward
+	DisqualificationMessage *string                `json:"disqualificationMessage"`
+	TargetAudience          *models.TargetAudience `json:"targetAudience"` // An empty object clears targeting
+	OrganizationID          *string                `json:"organizationId"` // An empty string makes the survey personal
+	Revision                *int                   `json:"revision"`       // Alternative to the If-Match header
 }
 
 // UpdateSurvey handles PUT /api/v1/surveys/:id
+// When the client sends If-Match (or revision) and the survey has changed since
+// that revision, the edit is rejected with 409 and a diff of the server changes.
+// Questions left out of a sent question list are deleted; if they have answers
+// the update is rejected with 409 unless ?force=true, as for DeleteQuestion.
 func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -226,8 +285,7 @@ func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 		return
 	}
 
-	if survey.UserID != userID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionEdit) {
 		return
 	}
 
@@ -237,6 +295,19 @@ func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 		return
 	}
 
+	baseRevision, hasBase, err := expectedRevision(c, req.Revision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !hasBase {
+		baseRevision = survey.Revision
+	}
+	if baseRevision != survey.Revision {
+		respondRevisionConflict(c, h.repo, survey.ID, baseRevision)
+		return
+	}
+
 	// Update fields if provided
 	if req.Title != nil {
 		survey.Title = *req.Title
@@ -250,15 +321,31 @@ func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 	if req.PointsReward != nil {
 		survey.PointsReward = *req.PointsReward
 	}
-
-	if err := h.repo.Update(survey); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update survey"})
+	if req.ConsolationPoints != nil {
+		survey.ConsolationPoints = *req.ConsolationPoints
+	}
+	if (req.PointsReward != nil || req.ConsolationPoints != nil) &&
+		!validateConsolationPoints(c, survey.PointsReward, survey.ConsolationPoints) {
+		return
+	}
+	if req.DisqualificationMessage != nil {
+		survey.DisqualificationMessage = req.DisqualificationMessage
+	}
+	if req.TargetAudience != nil {
+		targetAudience, ok := validateTargetAudience(c, req.TargetAudience)
+		if !ok {
+			return
+		}
+		survey.TargetAudience = targetAudience
+	}
+	if req.OrganizationID != nil && !h.moveSurvey(c, survey, *req.OrganizationID, userID.(uuid.UUID)) {
 		return
 	}
 
-	// Update questions if provided
+	// Replace questions if provided
+	var questions []models.Question
 	if len(req.Questions) > 0 {
-		questions := make([]models.Question, len(req.Questions))
+		questions = make([]models.Question, len(req.Questions))
 		for i, qReq := range req.Questions {
 			qID, _ := uuid.Parse(qReq.ID)
 			if qID == uuid.Nil {
@@ -275,25 +362,84 @@ func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 				Points:      qReq.Points,
 				MaxRating:   qReq.MaxRating,
 				Logic:       qReq.Logic,
+				IsScreener:  qReq.IsScreener,
+				Eligibility: qReq.Eligibility,
 				SortOrder:   i,
 			}
 		}
+	}
 
-		if err := h.repo.SaveQuestions(survey.ID, questions); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save questions"})
-			return
-		}
+	err = h.repo.UpdateWithQuestions(survey, questions, c.Query("force") == "true")
+	if err == repository.ErrRevisionConflict {
+		respondRevisionConflict(c, h.repo, survey.ID, baseRevision)
+		return
+	}
+	if err == repository.ErrQuestionsAnswered {
+		c.JSON(http.StatusConflict, gin.H{"error": "Questions left out of the list have answers; update with force=true to delete them"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update survey"})
+		return
+	}
+	if questions != nil {
 		survey.Questions = questions
 	}
 
+	recordRevision(c, h.repo, survey)
 	c.JSON(http.StatusOK, survey)
 }
 
+// GetEstimatedReach handles GET /api/v1/surveys/:id/reach
+func (h *SurveyHandler) GetEstimatedReach(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	survey, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionView) {
+		return
+	}
+
+	reach, err := h.profileRepo.EstimateReach(survey.TargetAudience)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate reach"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"surveyId":       survey.ID,
+		"targetAudience": survey.TargetAudience,
+		"estimatedReach": reach,
+	})
+}
+
 // PublishSurveyRequest represents the request body for publishing
 type PublishSurveyRequest struct {
-	Visibility        string `json:"visibility"`
-	IncludeInDatasets bool   `json:"includeInDatasets"`
-	PointsReward      int    `json:"pointsReward"`
+	Visibility        string     `json:"visibility"`
+	IncludeInDatasets bool       `json:"includeInDatasets"`
+	PointsReward      int        `json:"pointsReward"`
+	OpensAt           *time.Time `json:"opensAt"`   // Schedules the opening instead of publishing now
+	ExpiresAt         *time.Time `json:"expiresAt"` // Closing time; required to republish an expired survey
 }
 
 // PublishSurvey handles POST /api/v1/surveys/:id/publish
@@ -322,8 +468,12 @@ func (h *SurveyHandler) PublishSurvey(c *gin.Context) {
 		return
 	}
 
-	if survey.UserID != userID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionEdit) {
+		return
+	}
+
+	if survey.TakenDownAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Survey has been taken down by a moderator"})
 		return
 	}
 
@@ -353,17 +503,65 @@ func (h *SurveyHandler) PublishSurvey(c *gin.Context) {
 		}
 	}
 
+	now := time.Now()
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry time must be in the future"})
+			return
+		}
+		survey.ExpiresAt = req.ExpiresAt
+	}
+
+	if survey.ExpiresAt != nil && !survey.ExpiresAt.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Survey has expired; set a new expiry time to publish again"})
+		return
+	}
+
+	if !validateConsolationPoints(c, req.PointsReward, survey.ConsolationPoints) {
+		return
+	}
+
+	survey.PointsReward = req.PointsReward
+	survey.ClosedAt = nil
+
+	// Schedule the opening; the scheduler publishes the survey at opensAt
+	if req.OpensAt != nil && req.OpensAt.After(now) {
+		if survey.ExpiresAt != nil && !survey.ExpiresAt.After(*req.OpensAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Opening time must be before the expiry time"})
+			return
+		}
+
+		survey.IsPublished = false
+		survey.OpensAt = req.OpensAt
+
+		if err := h.repo.Update(survey); err == repository.ErrRevisionConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": errSurveyChanged})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule survey"})
+			return
+		}
+
+		recordRevision(c, h.repo, survey)
+		c.JSON(http.StatusOK, survey)
+		return
+	}
+
 	survey.IsPublished = true
 	survey.PublishedCount++
-	survey.PointsReward = req.PointsReward
-	now := time.Now()
 	survey.PublishedAt = &now
+	survey.OpensAt = nil
 
-	if err := h.repo.Update(survey); err != nil {
+	if err := h.repo.Update(survey); err == repository.ErrRevisionConflict {
+		c.JSON(http.StatusConflict, gin.H{"error": errSurveyChanged})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish survey"})
 		return
 	}
 
+	recordRevision(c, h.repo, survey)
 	c.JSON(http.StatusOK, survey)
 }
 
@@ -393,18 +591,23 @@ func (h *SurveyHandler) UnpublishSurvey(c *gin.Context) {
 		return
 	}
 
-	if survey.UserID != userID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionEdit) {
 		return
 	}
 
+	// Unpublishing also cancels a pending scheduled opening
 	survey.IsPublished = false
+	survey.OpensAt = nil
 
-	if err := h.repo.Update(survey); err != nil {
+	if err := h.repo.Update(survey); err == repository.ErrRevisionConflict {
+		c.JSON(http.StatusConflict, gin.H{"error": errSurveyChanged})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unpublish survey"})
 		return
 	}
 
+	recordRevision(c, h.repo, survey)
 	c.JSON(http.StatusOK, survey)
 }
 
@@ -434,8 +637,7 @@ func (h *SurveyHandler) DeleteSurvey(c *gin.Context) {
 		return
 	}
 
-	if survey.UserID != userID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionManage) {
 		return
 	}
 
@@ -446,3 +648,129 @@ func (h *SurveyHandler) DeleteSurvey(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Survey deleted successfully"})
 }
+
+// DuplicateSurveyRequest represents the request body for duplicating a survey
+type DuplicateSurveyRequest struct {
+	Title          *string    `json:"title"`          // Defaults to "<title> (copy)"
+	OrganizationID *uuid.UUID `json:"organizationId"` // The copy is personal if omitted
+}
+
+// DuplicateSurvey handles POST /api/v1/surveys/:id/duplicate
+// The copy is an unpublished draft with new question IDs; logic is remapped to
+// the copied questions. Responses, quotas and collaborators are not copied.
+func (h *SurveyHandler) DuplicateSurvey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req DuplicateSurveyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	source, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if source == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.repo, source, userID.(uuid.UUID), permissionView) {
+		return
+	}
+
+	if req.OrganizationID != nil && !canCreateInOrganization(c, h.orgRepo, *req.OrganizationID, userID.(uuid.UUID)) {
+		return
+	}
+
+	title := source.Title + " (copy)"
+	if req.Title != nil && *req.Title != "" {
+		title = *req.Title
+	}
+
+	survey := &models.Survey{
+		ID:                      uuid.New(),
+		UserID:                  userID.(uuid.UUID),
+		OrganizationID:          req.OrganizationID,
+		Title:                   title,
+		Description:             source.Description,
+		Visibility:              source.Visibility,
+		IsPublished:             false,
+		IncludeInDatasets:       source.IncludeInDatasets,
+		PublishedCount:          0,
+		Theme:                   source.Theme,
+		PointsReward:            source.PointsReward,
+		ConsolationPoints:       source.ConsolationPoints,
+		DisqualificationMessage: source.DisqualificationMessage,
+		TargetAudience:          source.TargetAudience,
+	}
+
+	if !createDraft(c, h.repo, survey, source.Questions) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, survey)
+}
+
+// validateConsolationPoints checks that disqualified respondents are not
+// offered more than completing the survey pays, writing a 400 response if so
+func validateConsolationPoints(c *gin.Context, pointsReward, consolationPoints int) bool {
+	if consolationPoints < 0 || consolationPoints > pointsReward {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "consolationPoints must be between 0 and pointsReward"})
+		return false
+	}
+	return true
+}
+
+// createDraft creates a survey with copies of the given questions and records
+// its first revision, writing the error response and returning false on failure
+func createDraft(c *gin.Context, surveyRepo *repository.SurveyRepository, survey *models.Survey, questions []models.Question) bool {
+	survey.Questions = models.CloneQuestions(questions, survey.ID)
+	if err := surveyRepo.CreateWithQuotas(survey, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create survey"})
+		return false
+	}
+
+	recordRevision(c, surveyRepo, survey)
+	return true
+}
+
+// moveSurvey moves a survey into an organization, or back to personal with an empty ID
+func (h *SurveyHandler) moveSurvey(c *gin.Context, survey *models.Survey, orgIDStr string, userID uuid.UUID) bool {
+	if !authorizeSurvey(c, h.repo, survey, userID, permissionManage) {
+		return false
+	}
+
+	if orgIDStr == "" {
+		survey.OrganizationID = nil
+		return true
+	}
+
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return false
+	}
+
+	if !canCreateInOrganization(c, h.orgRepo, orgID, userID) {
+		return false
+	}
+
+	survey.OrganizationID = &orgID
+	return true
+}
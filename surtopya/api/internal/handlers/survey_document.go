@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/converter"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/surveydoc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxImportSize limits the size of imported survey files
+const maxImportSize = 5 << 20
+
+// ExportSurvey handles GET /api/v1/surveys/:id/export
+// It returns the survey as a portable JSON document (see package surveydoc)
+func (h *SurveyHandler) ExportSurvey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	survey, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionView) {
+		return
+	}
+
+	quotas, err := h.quotaRepo.GetBySurveyID(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quotas"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="survey-%s.json"`, survey.ID))
+	c.IndentedJSON(http.StatusOK, surveydoc.Export(survey, quotas))
+}
+
+// ImportSurvey handles POST /api/v1/surveys/import
+// The body is a survey document; it is validated and created as a new
+// unpublished draft, in the organization given by ?organizationId= if set
+func (h *SurveyHandler) ImportSurvey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	orgID, ok := h.importOrganization(c, userID.(uuid.UUID))
+	if !ok {
+		return
+	}
+
+	data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	doc, err := surveydoc.Parse(data)
+	if err != nil {
+		problems := []string{err.Error()}
+		if verr, ok := err.(*surveydoc.ValidationError); ok {
+			problems = verr.Problems
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey document", "problems": problems})
+		return
+	}
+
+	survey, quotas := doc.Build(userID.(uuid.UUID))
+	survey.OrganizationID = orgID
+
+	targetAudience, ok := validateTargetAudience(c, survey.TargetAudience)
+	if !ok {
+		return
+	}
+	survey.TargetAudience = targetAudience
+
+	if err := h.repo.CreateWithQuotas(survey, quotas); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import survey"})
+		return
+	}
+
+	recordRevision(c, h.repo, survey)
+	c.JSON(http.StatusCreated, survey)
+}
+
+// ConvertSurvey handles POST /api/v1/surveys/import/:format
+// The body is a survey exported from another tool (see package converter),
+// sent raw or as the "file" field of a multipart form. The converted survey is
+// created as a new unpublished draft and returned with the conversion report;
+// with ?dryRun=true only the conversion is returned.
+func (h *SurveyHandler) ConvertSurvey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	orgID, ok := h.importOrganization(c, userID.(uuid.UUID))
+	if !ok {
+		return
+	}
+
+	data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	result, err := converter.Convert(c.Param("format"), data, c.Query("language"))
+	if err == converter.ErrUnknownFormat {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown import format"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("dryRun") == "true" {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	survey := &models.Survey{
+		ID:             uuid.New(),
+		UserID:         userID.(uuid.UUID),
+		OrganizationID: orgID,
+		Title:          result.Title,
+		Description:    result.Description,
+		Visibility:     "non-public",
+		IsPublished:    false,
+		PublishedCount: 0,
+	}
+
+	if !createDraft(c, h.repo, survey, result.Questions) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"survey": survey, "report": result.Report})
+}
+
+// importOrganization returns the organization from ?organizationId=, checking
+// that the user may create surveys in it; nil means a personal survey
+func (h *SurveyHandler) importOrganization(c *gin.Context, userID uuid.UUID) (*uuid.UUID, bool) {
+	orgIDStr := c.Query("organizationId")
+	if orgIDStr == "" {
+		return nil, true
+	}
+
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return nil, false
+	}
+
+	if !canCreateInOrganization(c, h.orgRepo, orgID, userID) {
+		return nil, false
+	}
+
+	return &orgID, true
+}
+
+// readImportFile reads an uploaded file from the "file" form field or the raw request body
+func readImportFile(c *gin.Context) ([]byte, bool) {
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+			return nil, false
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+			return nil, false
+		}
+		defer f.Close()
+		body = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxImportSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return nil, false
+	}
+	if len(data) > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return nil, false
+	}
+
+	return data, true
+}
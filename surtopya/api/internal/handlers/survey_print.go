@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/TimLai666/surtopya-api/internal/render"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PrintSurvey handles GET /api/v1/surveys/:id/print
+// It renders the questionnaire for printing, as a PDF (?format=pdf, the
+// default) or as Markdown (?format=markdown). PDF text uses the TrueType font
+// at PDF_FONT_PATH if set, which is needed for non-Latin scripts; without it
+// such surveys get 501 rather than an unreadable PDF.
+func (h *SurveyHandler) PrintSurvey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "markdown" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be pdf or markdown"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	survey, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.repo, survey, userID.(uuid.UUID), permissionView) {
+		return
+	}
+
+	if format == "markdown" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="survey-%s.md"`, survey.ID))
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(render.Markdown(survey)))
+		return
+	}
+
+	var buf bytes.Buffer
+	err = render.PDF(&buf, survey, render.PDFOptions{FontPath: os.Getenv("PDF_FONT_PATH")})
+	if errors.Is(err, render.ErrUnsupportedText) {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "This survey has characters the server's PDF font cannot print; ask the administrator to set PDF_FONT_PATH, or use format=markdown",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to render survey %s as PDF: %v", survey.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="survey-%s.pdf"`, survey.ID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
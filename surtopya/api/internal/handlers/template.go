@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/database"
+	"github.com/TimLai666/surtopya-api/internal/middleware"
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TemplateHandler handles the survey template library
+type TemplateHandler struct {
+	repo       *repository.TemplateRepository
+	surveyRepo *repository.SurveyRepository
+	orgRepo    *repository.OrganizationRepository
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler() *TemplateHandler {
+	db := database.GetDB()
+	return &TemplateHandler{
+		repo:       repository.NewTemplateRepository(db),
+		surveyRepo: repository.NewSurveyRepository(db),
+		orgRepo:    repository.NewOrganizationRepository(db),
+	}
+}
+
+// GetTemplates handles GET /api/v1/templates
+// It lists public templates, plus the caller's own, optionally filtered by ?category=
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	var userID *uuid.UUID
+	if uid, exists := c.Get("userID"); exists {
+		id := uid.(uuid.UUID)
+		userID = &id
+	}
+
+	templates, err := h.repo.GetVisible(userID, c.Query("category"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetCategories handles GET /api/v1/templates/categories
+func (h *TemplateHandler) GetCategories(c *gin.Context) {
+	categories, err := h.repo.GetCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// GetTemplate handles GET /api/v1/templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, ok := h.getVisibleTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// InstantiateTemplateRequest represents the request body for creating a survey from a template
+type InstantiateTemplateRequest struct {
+	Title          *string    `json:"title"`          // Defaults to the template title
+	OrganizationID *uuid.UUID `json:"organizationId"` // The survey is personal if omitted
+}
+
+// InstantiateTemplate handles POST /api/v1/templates/:id/instantiate
+// It creates a new unpublished draft from the template
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	template, ok := h.getVisibleTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req InstantiateTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	if req.OrganizationID != nil && !canCreateInOrganization(c, h.orgRepo, *req.OrganizationID, userID.(uuid.UUID)) {
+		return
+	}
+
+	title := template.Title
+	if req.Title != nil && *req.Title != "" {
+		title = *req.Title
+	}
+
+	survey := &models.Survey{
+		ID:                      uuid.New(),
+		UserID:                  userID.(uuid.UUID),
+		OrganizationID:          req.OrganizationID,
+		Title:                   title,
+		Description:             template.Description,
+		Visibility:              "non-public",
+		IsPublished:             false,
+		PublishedCount:          0,
+		Theme:                   template.Theme,
+		DisqualificationMessage: template.DisqualificationMessage,
+	}
+
+	if !createDraft(c, h.surveyRepo, survey, template.Questions) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, survey)
+}
+
+// SaveAsTemplateRequest represents the request body for saving a survey as a template
+type SaveAsTemplateRequest struct {
+	Category    string  `json:"category" binding:"required"`
+	Title       *string `json:"title"`       // Defaults to the survey title
+	Description *string `json:"description"` // Defaults to the survey description
+	IsPublic    bool    `json:"isPublic"`
+}
+
+// SaveAsTemplate handles POST /api/v1/surveys/:id/template
+// Only the survey content is kept: rewards, targeting and scheduling are not part of templates.
+func (h *TemplateHandler) SaveAsTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SaveAsTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	req.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	if req.Category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category is required"})
+		return
+	}
+
+	survey, err := h.surveyRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get survey"})
+		return
+	}
+
+	if survey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		return
+	}
+
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionEdit) {
+		return
+	}
+
+	createdBy := userID.(uuid.UUID)
+	template := &models.SurveyTemplate{
+		ID:                      uuid.New(),
+		Category:                req.Category,
+		Title:                   survey.Title,
+		Description:             survey.Description,
+		Theme:                   survey.Theme,
+		DisqualificationMessage: survey.DisqualificationMessage,
+		Questions:               models.CloneQuestions(survey.Questions, uuid.Nil),
+		IsPublic:                req.IsPublic,
+		CreatedBy:               &createdBy,
+	}
+	if req.Title != nil && *req.Title != "" {
+		template.Title = *req.Title
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+
+	if err := h.repo.Create(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/:id
+// Templates can be deleted by their creator or a moderator; curated templates
+// are managed through the seed files and cannot be deleted.
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	template, ok := h.getVisibleTemplate(c)
+	if !ok {
+		return
+	}
+
+	if template.IsCurated {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Curated templates cannot be deleted"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isCreator := template.CreatedBy != nil && *template.CreatedBy == userID.(uuid.UUID)
+	if !isCreator && !middleware.HasRole(c, "moderator") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if err := h.repo.Delete(template.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// getVisibleTemplate loads the template from the :id param and checks that the
+// current user can see it (public, their own, or any for moderators)
+func (h *TemplateHandler) getVisibleTemplate(c *gin.Context) (*models.SurveyTemplate, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return nil, false
+	}
+
+	template, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+		return nil, false
+	}
+
+	if template != nil && !template.IsPublic && !middleware.HasRole(c, "moderator") {
+		userID, exists := c.Get("userID")
+		if !exists || template.CreatedBy == nil || *template.CreatedBy != userID.(uuid.UUID) {
+			template = nil // Private templates are not disclosed
+		}
+	}
+
+	if template == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+
+	return template, true
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/weighting"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WeightingRequest represents the request body for setting a weighting scheme
+type WeightingRequest struct {
+	Margins   []models.WeightingMargin `json:"margins"`
+	MaxWeight *float64                 `json:"maxWeight"`
+}
+
+// GetWeighting handles GET /api/v1/surveys/:id/weighting
+func (h *ReportHandler) GetWeighting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+
+	scheme, err := h.weightingRepo.Get(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get weighting scheme"})
+		return
+	}
+	if scheme == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "The survey has no weighting scheme"})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheme)
+}
+
+// SaveWeighting handles PUT /api/v1/surveys/:id/weighting
+// It sets the population margins the responses are raked to. The body is
+// JSON, or a CSV file (raw text/csv or the "file" field of a multipart form)
+// with the columns question, option and share, where question is a question
+// ID or title; maxWeight is then a query parameter.
+func (h *ReportHandler) SaveWeighting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("userID")
+	uid := userID.(uuid.UUID)
+	if !authorizeSurvey(c, h.surveyRepo, survey, uid, permissionEdit) {
+		return
+	}
+
+	var req WeightingRequest
+	if contentType := c.ContentType(); contentType == "text/csv" || strings.HasPrefix(contentType, "multipart/form-data") {
+		data, ok := readImportFile(c)
+		if !ok {
+			return
+		}
+		if req.Margins, err = parseMarginsCSV(survey, data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if value := c.Query("maxWeight"); value != "" {
+			maxWeight, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "maxWeight must be a number"})
+				return
+			}
+			req.MaxWeight = &maxWeight
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	scheme := &models.WeightingScheme{
+		SurveyID:  survey.ID,
+		Margins:   req.Margins,
+		MaxWeight: req.MaxWeight,
+		UpdatedBy: &uid,
+	}
+	if err := weighting.Validate(survey, scheme); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.weightingRepo.Save(scheme); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save weighting scheme"})
+		return
+	}
+
+	c.JSON(http.StatusOK, scheme)
+}
+
+// DeleteWeighting handles DELETE /api/v1/surveys/:id/weighting
+func (h *ReportHandler) DeleteWeighting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("userID")
+	if !authorizeSurvey(c, h.surveyRepo, survey, userID.(uuid.UUID), permissionEdit) {
+		return
+	}
+
+	if err := h.weightingRepo.Delete(survey.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete weighting scheme"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Weighting scheme deleted"})
+}
+
+// GetWeightingDiagnostics handles GET /api/v1/surveys/:id/weighting/diagnostics
+// It rakes the responses and reports how well the weights meet the margins,
+// with the design effect and effective sample size. It takes the from, to
+// and status filters of the report; weights are always computed over the
+// whole filtered sample, so segments do not apply.
+func (h *ReportHandler) GetWeightingDiagnostics(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	filter, ok := parseReportFilter(c, "completed")
+	if !ok {
+		return
+	}
+
+	survey, ok := h.resultsSurvey(c, id)
+	if !ok {
+		return
+	}
+
+	result, ok := h.computeWeights(c, survey, filter, true)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Diagnostics)
+}
+
+// computeWeights rakes the responses matching the filter, leaving out its
+// segment so that segments of a weighted report are estimated with the
+// weights of the whole sample. Without a weighting scheme it responds with
+// an error if required, and otherwise returns nil.
+func (h *ReportHandler) computeWeights(c *gin.Context, survey *models.Survey, filter models.ReportFilter, required bool) (*weighting.Result, bool) {
+	scheme, err := h.weightingRepo.Get(survey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get weighting scheme"})
+		return nil, false
+	}
+	if scheme == nil {
+		if required {
+			c.JSON(http.StatusNotFound, gin.H{"error": "The survey has no weighting scheme"})
+			return nil, false
+		}
+		return nil, true
+	}
+
+	filter.Segment = nil
+	ids, choices, err := h.reportRepo.GetChoiceAnswers(survey.ID, filter, weighting.MarginQuestionIDs(scheme))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get responses"})
+		return nil, false
+	}
+
+	result, err := weighting.Compute(survey, scheme, ids, choices)
+	if errors.Is(err, weighting.ErrNoResponses) || errors.Is(err, weighting.ErrEmptyCategory) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Weights cannot be computed: " + err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute weights"})
+		return nil, false
+	}
+
+	return result, true
+}
+
+// parseMarginsCSV reads margins from CSV rows of question, option and share,
+// after a header row. Rows of the same question form one margin.
+func parseMarginsCSV(survey *models.Survey, data []byte) ([]models.WeightingMargin, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, errors.New("the CSV file needs a header row of question, option and share")
+	}
+
+	var margins []models.WeightingMargin
+	index := make(map[uuid.UUID]int)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+
+		q := findMarginQuestion(survey, strings.TrimSpace(row[0]))
+		if q == nil {
+			return nil, fmt.Errorf("line %d: question %q not found", line, row[0])
+		}
+		share, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(row[2]), "%")), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: share %q is not a number", line, row[2])
+		}
+
+		i, ok := index[q.ID]
+		if !ok {
+			i = len(margins)
+			index[q.ID] = i
+			margins = append(margins, models.WeightingMargin{QuestionID: q.ID})
+		}
+		margins[i].Targets = append(margins[i].Targets, models.WeightingTarget{Option: row[1], Share: share})
+	}
+
+	return margins, nil
+}
+
+// findMarginQuestion finds a question by ID or by title
+func findMarginQuestion(survey *models.Survey, question string) *models.Question {
+	if id, err := uuid.Parse(question); err == nil {
+		return findQuestion(survey, id)
+	}
+	for i := range survey.Questions {
+		if survey.Questions[i].Title == question {
+			return &survey.Questions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package insights writes plain-language summaries of survey results with a
+// language model. Only aggregates and a small sample of open-text answers,
+// with personal information redacted, are sent to the model.
+package insights
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend is a language model that completes prompts
+type Backend interface {
+	// Generate returns the model's reply to the prompt under the system instructions
+	Generate(ctx context.Context, system, prompt string) (string, error)
+	// Model names the model; summaries are cached per model
+	Model() string
+}
+
+// Config holds language model configuration
+type Config struct {
+	Backend string // ollama or fake
+	BaseURL string
+	Model   string
+	Timeout time.Duration
+}
+
+// LoadConfigFromEnv loads language model config from environment variables
+func LoadConfigFromEnv() Config {
+	timeout, err := time.ParseDuration(os.Getenv("OLLAMA_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	return Config{
+		Backend: getEnv("INSIGHTS_BACKEND", "ollama"),
+		BaseURL: getEnv("OLLAMA_BASE_URL", "http://localhost:11434"),
+		Model:   getEnv("OLLAMA_MODEL", "llama3"),
+		Timeout: timeout,
+	}
+}
+
+// New creates the configured Backend, Ollama unless INSIGHTS_BACKEND is fake
+func New(cfg Config) Backend {
+	if cfg.Backend == "fake" {
+		return &Fake{}
+	}
+
+	return &Ollama{
+		BaseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		Name:    cfg.Model,
+		Client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Ollama generates with a model served by Ollama
+type Ollama struct {
+	BaseURL string
+	Name    string
+	Client  *http.Client
+}
+
+type ollamaRequest struct {
+	Model   string                 `json:"model"`
+	System  string                 `json:"system"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error"`
+}
+
+// Generate calls Ollama's /api/generate without streaming
+func (o *Ollama) Generate(ctx context.Context, system, prompt string) (string, error) {
+	body, err := json.Marshal(ollamaRequest{
+		Model:  o.Name,
+		System: system,
+		Prompt: prompt,
+		// Summaries should stick to the numbers rather than be creative
+		Options: map[string]interface{}{"temperature": 0.2},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Ollama returned %d: %s", resp.StatusCode, result.Error)
+	}
+
+	return strings.TrimSpace(result.Response), nil
+}
+
+// Model returns the Ollama model name
+func (o *Ollama) Model() string {
+	return "ollama:" + o.Name
+}
+
+// Fake is a Backend for tests and development without a model. It returns
+// Reply, or a fixed summary naming the prompt size, and records the prompts.
+type Fake struct {
+	Reply string
+	Err   error
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// Generate records the prompt and returns the canned reply
+func (f *Fake) Generate(ctx context.Context, system, prompt string) (string, error) {
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return "", f.Err
+	}
+	if f.Reply != "" {
+		return f.Reply, nil
+	}
+	return fmt.Sprintf("Summary of survey results (%d-character prompt).", len(prompt)), nil
+}
+
+// Model returns the fake model name
+func (f *Fake) Model() string {
+	return "fake"
+}
+
+// Prompts returns the prompts generated so far
+func (f *Fake) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.prompts...)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
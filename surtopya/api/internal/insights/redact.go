@@ -0,0 +1,29 @@
+package insights
+
+import "regexp"
+
+// redactions replace personal information with placeholders, in order:
+// URLs and emails go first so their digits are not taken for phone numbers
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`), "[URL]"},
+	{regexp.MustCompile(`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)+`), "[EMAIL]"},
+	// Taiwanese national ID and resident certificate numbers
+	{regexp.MustCompile(`\b[A-Za-z][1289A-Da-d]\d{8}\b`), "[ID]"},
+	{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`), "[IP]"},
+	// Card and account numbers: 13 to 19 digits, optionally grouped
+	{regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), "[NUMBER]"},
+	{regexp.MustCompile(`(?:\+|\b)\d[\d ().-]{6,}\d\b`), "[PHONE]"},
+	{regexp.MustCompile(`@[A-Za-z0-9_.]{2,}`), "[HANDLE]"},
+}
+
+// Redact replaces emails, phone numbers, URLs, ID, card and account numbers,
+// IP addresses and social media handles in text with placeholders
+func Redact(text string) string {
+	for _, r := range redactions {
+		text = r.pattern.ReplaceAllString(text, r.placeholder)
+	}
+	return text
+}
@@ -0,0 +1,95 @@
+package insights
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+)
+
+// ErrNoResponses is returned when a survey has no completed responses to summarize
+var ErrNoResponses = errors.New("survey has no completed responses")
+
+// Summarizer writes and caches summaries of survey results
+type Summarizer struct {
+	backend     Backend
+	reportRepo  *repository.ReportRepository
+	insightRepo *repository.InsightRepository
+}
+
+// NewSummarizer creates a Summarizer using the backend
+func NewSummarizer(db *sql.DB, backend Backend) *Summarizer {
+	return &Summarizer{
+		backend:     backend,
+		reportRepo:  repository.NewReportRepository(db),
+		insightRepo: repository.NewInsightRepository(db),
+	}
+}
+
+// Cached returns the summary of the survey's current revision, or nil if
+// none was generated. It is marked stale if responses arrived since.
+func (s *Summarizer) Cached(survey *models.Survey) (*models.SurveyInsight, error) {
+	insight, err := s.insightRepo.Get(survey.ID, survey.Revision, s.backend.Model())
+	if err != nil || insight == nil {
+		return nil, err
+	}
+
+	completed, err := s.reportRepo.CountCompleted(survey.ID)
+	if err != nil {
+		return nil, err
+	}
+	insight.Stale = completed != insight.Responses
+	return insight, nil
+}
+
+// Summarize generates a summary of the survey's completed responses and
+// caches it for the current revision
+func (s *Summarizer) Summarize(ctx context.Context, survey *models.Survey) (*models.SurveyInsight, error) {
+	filter := models.ReportFilter{Statuses: []string{"completed"}}
+	report, err := s.reportRepo.GetReport(survey, filter, repository.ReportOptions{
+		DateInterval: "month",
+		TopWords:     promptTopWords,
+		Confidence:   0.95,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if report.Responses == 0 {
+		return nil, ErrNoResponses
+	}
+
+	var textIDs []string
+	for _, q := range survey.Questions {
+		switch q.Type {
+		case "text", "short", "long":
+			textIDs = append(textIDs, q.ID.String())
+		}
+	}
+	samples, err := s.reportRepo.SampleTextAnswers(survey.ID, filter, textIDs, sampleAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.backend.Generate(ctx, systemPrompt, buildPrompt(survey, report, samples))
+	if err != nil {
+		return nil, err
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("model returned an empty summary")
+	}
+
+	insight := &models.SurveyInsight{
+		SurveyID:  survey.ID,
+		Revision:  survey.Revision,
+		Model:     s.backend.Model(),
+		Responses: report.Responses,
+		Summary:   summary,
+	}
+	if err := s.insightRepo.Save(insight); err != nil {
+		return nil, err
+	}
+	return insight, nil
+}
@@ -66,16 +66,17 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Get or create user in our database
-		userID, err := getOrCreateUser(logtoUserID, claims)
+		userID, role, err := getOrCreateUser(logtoUserID, claims)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
 			c.Abort()
 			return
 		}
 
-		// Set user ID in context
+		// Set user ID and role in context
 		c.Set("userID", userID)
 		c.Set("logtoUserID", logtoUserID)
+		c.Set("userRole", role)
 
 		c.Next()
 	}
@@ -94,41 +95,63 @@ func RequireAuth() gin.HandlerFunc {
 	}
 }
 
-// getOrCreateUser gets or creates a user based on Logto user ID
-func getOrCreateUser(logtoUserID string, claims jwt.MapClaims) (uuid.UUID, error) {
+// getOrCreateUser gets or creates a user based on Logto user ID, returning its ID and role
+func getOrCreateUser(logtoUserID string, claims jwt.MapClaims) (uuid.UUID, string, error) {
 	db := database.GetDB()
 
 	// Try to find existing user
 	var userID uuid.UUID
+	var role string
 	err := db.QueryRow(
-		"SELECT id FROM users WHERE logto_user_id = $1",
+		"SELECT id, role FROM users WHERE logto_user_id = $1",
 		logtoUserID,
-	).Scan(&userID)
+	).Scan(&userID, &role)
 
 	if err == nil {
-		return userID, nil
+		// Promote bootstrap admins configured in the environment
+		if role != "admin" && isBootstrapAdmin(logtoUserID) {
+			if _, err := db.Exec("UPDATE users SET role = 'admin' WHERE id = $1", userID); err != nil {
+				return uuid.Nil, "", err
+			}
+			role = "admin"
+		}
+		return userID, role, nil
 	}
 
 	if err != sql.ErrNoRows {
-		return uuid.Nil, err
+		return uuid.Nil, "", err
 	}
 
 	// Create new user
 	userID = uuid.New()
+	role = "user"
+	if isBootstrapAdmin(logtoUserID) {
+		role = "admin"
+	}
 	email, _ := claims["email"].(string)
 	name, _ := claims["name"].(string)
 	picture, _ := claims["picture"].(string)
 
 	_, err = db.Exec(`
-		INSERT INTO users (id, logto_user_id, email, display_name, avatar_url)
-		VALUES ($1, $2, $3, $4, $5)
-	`, userID, logtoUserID, nullString(email), nullString(name), nullString(picture))
+		INSERT INTO users (id, logto_user_id, email, display_name, avatar_url, role)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, userID, logtoUserID, nullString(email), nullString(name), nullString(picture), role)
 
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, "", err
 	}
 
-	return userID, nil
+	return userID, role, nil
+}
+
+// isBootstrapAdmin reports whether a Logto user ID is listed in ADMIN_LOGTO_USER_IDS
+func isBootstrapAdmin(logtoUserID string) bool {
+	for _, id := range strings.Split(os.Getenv("ADMIN_LOGTO_USER_IDS"), ",") {
+		if strings.TrimSpace(id) == logtoUserID {
+			return true
+		}
+	}
+	return false
 }
 
 func nullString(s string) *string {
@@ -148,7 +171,8 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Response-Token, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// roleRank orders roles from least to most privileged
+var roleRank = map[string]int{
+	"user":      0,
+	"moderator": 1,
+	"admin":     2,
+}
+
+// GetRole returns the role of the authenticated user ("" when unauthenticated)
+func GetRole(c *gin.Context) string {
+	role, exists := c.Get("userRole")
+	if !exists {
+		return ""
+	}
+	return role.(string)
+}
+
+// HasRole reports whether the authenticated user has at least the given role
+func HasRole(c *gin.Context, role string) bool {
+	current := GetRole(c)
+	if current == "" {
+		return false
+	}
+	return roleRank[current] >= roleRank[role]
+}
+
+// RequireRole middleware that requires the authenticated user to have at least the given role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("userID"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+		if !HasRole(c, role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import "github.com/google/uuid"
+
+// EndSurveyDestination is the logic destination that ends the survey
+const EndSurveyDestination = "end_survey"
+
+// CloneQuestions deep-copies questions for another survey, giving each a new ID
+// and remapping logic destinations to the copied questions
+func CloneQuestions(questions []Question, surveyID uuid.UUID) []Question {
+	clones := make([]Question, len(questions))
+	idMap := make(map[string]uuid.UUID, len(questions))
+
+	for i, q := range questions {
+		clone := q
+		clone.ID = uuid.New()
+		clone.SurveyID = surveyID
+		clone.SortOrder = i
+		if q.Description != nil {
+			description := *q.Description
+			clone.Description = &description
+		}
+		if q.Options != nil {
+			clone.Options = append([]string{}, q.Options...)
+		}
+		if q.Logic != nil {
+			clone.Logic = append([]LogicRule{}, q.Logic...)
+		}
+		if q.Eligibility != nil {
+			eligibility := *q.Eligibility
+			eligibility.QualifyingOptions = append([]string(nil), q.Eligibility.QualifyingOptions...)
+			clone.Eligibility = &eligibility
+		}
+
+		idMap[q.ID.String()] = clone.ID
+		clones[i] = clone
+	}
+
+	RemapLogic(clones, idMap)
+	return clones
+}
+
+// RemapLogic rewrites logic destinations through idMap (old ID -> new ID).
+// Rules pointing to a question outside the map are dropped.
+func RemapLogic(questions []Question, idMap map[string]uuid.UUID) {
+	for i := range questions {
+		var logic []LogicRule
+		for _, rule := range questions[i].Logic {
+			if rule.DestinationQuestionID != EndSurveyDestination {
+				newID, ok := idMap[rule.DestinationQuestionID]
+				if !ok {
+					continue
+				}
+				rule.DestinationQuestionID = newID.String()
+			}
+			logic = append(logic, rule)
+		}
+		questions[i].Logic = logic
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Code assignment modes
+const (
+	CodeAssignAdd    = "add"    // Add the codes, keeping others
+	CodeAssignRemove = "remove" // Remove the codes
+	CodeAssignSet    = "set"    // Replace the answer's codes with these
+)
+
+// AnswerCode is a theme in the codebook of an open-text question
+type AnswerCode struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	SurveyID    uuid.UUID  `json:"surveyId" db:"survey_id"`
+	QuestionID  uuid.UUID  `json:"questionId" db:"question_id"`
+	Label       string     `json:"label" db:"label"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	Color       *string    `json:"color,omitempty" db:"color"`
+	Position    int        `json:"position" db:"position"`
+	Answers     int        `json:"answers"` // Answers tagged with the code
+	CreatedBy   *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// CodedAnswer is an open-text answer with the codes it was tagged with
+type CodedAnswer struct {
+	AnswerID   uuid.UUID   `json:"answerId"`
+	ResponseID uuid.UUID   `json:"responseId"`
+	Text       string      `json:"text"`
+	CodeIDs    []uuid.UUID `json:"codeIds"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// CodeAssignment tags or untags answers with codes in bulk
+type CodeAssignment struct {
+	AnswerIDs []uuid.UUID `json:"answerIds" binding:"required"`
+	CodeIDs   []uuid.UUID `json:"codeIds"`
+	Mode      string      `json:"mode"` // add (default), remove or set
+}
+
+// CodeCount is how often a code was applied to the answers in a report.
+// Percentage is of the answered responses; an answer can carry several codes.
+type CodeCount struct {
+	CodeID     uuid.UUID `json:"codeId"`
+	Label      string    `json:"label"`
+	Count      int       `json:"count"`
+	Percentage float64   `json:"percentage"`
+}
+
+// CodeSuggestion is a cluster of similar answers proposed as a code
+type CodeSuggestion struct {
+	Label     string      `json:"label"` // The cluster's most distinctive terms
+	Terms     []string    `json:"terms"`
+	AnswerIDs []uuid.UUID `json:"answerIds"`
+	Examples  []string    `json:"examples"` // Answers closest to the cluster centre
+}
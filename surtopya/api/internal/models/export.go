@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResponseRecord is a response with its answers, as exported
+type ResponseRecord struct {
+	ID          uuid.UUID
+	Status      string
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	Answers     map[uuid.UUID]AnswerValue // By question ID
+	Codes       map[uuid.UUID][]uuid.UUID // Code IDs of each coded answer, by question ID
+}
+
+// Export job statuses
+const (
+	ExportQueued    = "queued"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+	ExportExpired   = "expired" // The file was deleted after its retention period
+)
+
+// ExportJob is a background export of a survey's responses to a file
+type ExportJob struct {
+	ID          uuid.UUID    `json:"id" db:"id"`
+	SurveyID    uuid.UUID    `json:"surveyId" db:"survey_id"`
+	RequestedBy *uuid.UUID   `json:"requestedBy,omitempty" db:"requested_by"`
+	Format      string       `json:"format" db:"format"`
+	Filter      ReportFilter `json:"filter" db:"filter"`
+	Status      string       `json:"status" db:"status"`
+	TotalRows   *int         `json:"totalRows,omitempty" db:"total_rows"` // Responses matching the filter, once counted
+	RowsWritten int          `json:"rowsWritten" db:"rows_written"`
+	Attempts    int          `json:"attempts" db:"attempts"`
+	StorageKey  *string      `json:"-" db:"storage_key"`
+	FileName    *string      `json:"fileName,omitempty" db:"file_name"`
+	FileSize    *int64       `json:"fileSize,omitempty" db:"file_size"`
+	Error       *string      `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time    `json:"createdAt" db:"created_at"`
+	StartedAt   *time.Time   `json:"startedAt,omitempty" db:"started_at"`
+	HeartbeatAt *time.Time   `json:"-" db:"heartbeat_at"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty" db:"expires_at"` // When the file is deleted
+	DownloadURL string       `json:"downloadUrl,omitempty"`               // A signed link, set on completed jobs
+	LinkExpires *time.Time   `json:"downloadUrlExpiresAt,omitempty"`
+}
@@ -15,10 +15,34 @@ type User struct {
 	AvatarURL    *string    `json:"avatarUrl,omitempty" db:"avatar_url"`
 	PointsBalance int       `json:"pointsBalance" db:"points_balance"`
 	IsPro        bool       `json:"isPro" db:"is_pro"`
+	Role         string     `json:"role" db:"role"`
 	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
 }
 
+// RespondentProfile holds the consented demographic attributes of a user
+type RespondentProfile struct {
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	BirthYear   *int       `json:"birthYear,omitempty" db:"birth_year"`
+	Gender      *string    `json:"gender,omitempty" db:"gender"`
+	Region      *string    `json:"region,omitempty" db:"region"`
+	Interests   []string   `json:"interests" db:"interests"`
+	Consented   bool       `json:"consented" db:"consented"`
+	ConsentedAt *time.Time `json:"consentedAt,omitempty" db:"consented_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// TargetAudience restricts which respondents can see and take a survey.
+// Empty fields do not restrict.
+type TargetAudience struct {
+	MinAge    *int     `json:"minAge,omitempty"`
+	MaxAge    *int     `json:"maxAge,omitempty"`
+	Genders   []string `json:"genders,omitempty"`
+	Regions   []string `json:"regions,omitempty"`
+	Interests []string `json:"interests,omitempty"` // Matches if any interest overlaps
+}
+
 // SurveyTheme represents the visual theme of a survey
 type SurveyTheme struct {
 	PrimaryColor    string `json:"primaryColor"`
@@ -28,22 +52,32 @@ type SurveyTheme struct {
 
 // Survey represents a survey
 type Survey struct {
-	ID                uuid.UUID    `json:"id" db:"id"`
-	UserID            uuid.UUID    `json:"userId" db:"user_id"`
-	Title             string       `json:"title" db:"title"`
-	Description       string       `json:"description" db:"description"`
-	Visibility        string       `json:"visibility" db:"visibility"`
-	IsPublished       bool         `json:"isPublished" db:"is_published"`
-	IncludeInDatasets bool         `json:"includeInDatasets" db:"include_in_datasets"`
-	PublishedCount    int          `json:"publishedCount" db:"published_count"`
-	Theme             *SurveyTheme `json:"theme,omitempty" db:"theme"`
-	PointsReward      int          `json:"pointsReward" db:"points_reward"`
-	ExpiresAt         *time.Time   `json:"expiresAt,omitempty" db:"expires_at"`
-	ResponseCount     int          `json:"responseCount" db:"response_count"`
-	CreatedAt         time.Time    `json:"createdAt" db:"created_at"`
-	UpdatedAt         time.Time    `json:"updatedAt" db:"updated_at"`
-	PublishedAt       *time.Time   `json:"publishedAt,omitempty" db:"published_at"`
-	Questions         []Question   `json:"questions,omitempty"`
+	ID                      uuid.UUID       `json:"id" db:"id"`
+	UserID                  uuid.UUID       `json:"userId" db:"user_id"`
+	OrganizationID          *uuid.UUID      `json:"organizationId,omitempty" db:"organization_id"`
+	Title                   string          `json:"title" db:"title"`
+	Description             string          `json:"description" db:"description"`
+	Visibility              string          `json:"visibility" db:"visibility"`
+	IsPublished             bool            `json:"isPublished" db:"is_published"`
+	IncludeInDatasets       bool            `json:"includeInDatasets" db:"include_in_datasets"`
+	PublishedCount          int             `json:"publishedCount" db:"published_count"`
+	Theme                   *SurveyTheme    `json:"theme,omitempty" db:"theme"`
+	PointsReward            int             `json:"pointsReward" db:"points_reward"`
+	ConsolationPoints       int             `json:"consolationPoints" db:"consolation_points"`
+	DisqualificationMessage *string         `json:"disqualificationMessage,omitempty" db:"disqualification_message"`
+	TargetAudience          *TargetAudience `json:"targetAudience,omitempty" db:"target_audience"`
+	OpensAt                 *time.Time      `json:"opensAt,omitempty" db:"opens_at"`
+	ExpiresAt               *time.Time      `json:"expiresAt,omitempty" db:"expires_at"`
+	ClosedAt                *time.Time      `json:"closedAt,omitempty" db:"closed_at"`
+	TakenDownAt             *time.Time      `json:"takenDownAt,omitempty" db:"taken_down_at"`
+	TakedownReason          *string         `json:"takedownReason,omitempty" db:"takedown_reason"`
+	ResponseCount           int             `json:"responseCount" db:"response_count"`
+	Revision                int             `json:"revision" db:"revision"`
+	CreatedAt               time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time       `json:"updatedAt" db:"updated_at"`
+	PublishedAt             *time.Time      `json:"publishedAt,omitempty" db:"published_at"`
+	Questions               []Question      `json:"questions,omitempty"`
+	Role                    string          `json:"role,omitempty"` // The current user's access role, set by GetMySurveys
 }
 
 // LogicRule represents conditional logic for a question
@@ -52,35 +86,46 @@ type LogicRule struct {
 	DestinationQuestionID string `json:"destinationQuestionId"`
 }
 
+// ScreenerRule defines which answers keep a respondent eligible on a screener question
+type ScreenerRule struct {
+	QualifyingOptions []string `json:"qualifyingOptions,omitempty"` // For single/multi/select
+	MinRating         *int     `json:"minRating,omitempty"`         // For rating
+	MaxRating         *int     `json:"maxRating,omitempty"`         // For rating
+}
+
 // Question represents a question in a survey
 type Question struct {
-	ID          uuid.UUID    `json:"id" db:"id"`
-	SurveyID    uuid.UUID    `json:"surveyId" db:"survey_id"`
-	Type        string       `json:"type" db:"type"`
-	Title       string       `json:"title" db:"title"`
-	Description *string      `json:"description,omitempty" db:"description"`
-	Options     []string     `json:"options,omitempty" db:"options"`
-	Required    bool         `json:"required" db:"required"`
-	Points      int          `json:"points" db:"points"`
-	MaxRating   int          `json:"maxRating,omitempty" db:"max_rating"`
-	Logic       []LogicRule  `json:"logic,omitempty" db:"logic"`
-	SortOrder   int          `json:"sortOrder" db:"sort_order"`
-	CreatedAt   time.Time    `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time    `json:"updatedAt" db:"updated_at"`
+	ID          uuid.UUID     `json:"id" db:"id"`
+	SurveyID    uuid.UUID     `json:"surveyId" db:"survey_id"`
+	Type        string        `json:"type" db:"type"`
+	Title       string        `json:"title" db:"title"`
+	Description *string       `json:"description,omitempty" db:"description"`
+	Options     []string      `json:"options,omitempty" db:"options"`
+	Required    bool          `json:"required" db:"required"`
+	Points      int           `json:"points" db:"points"`
+	MaxRating   int           `json:"maxRating,omitempty" db:"max_rating"`
+	Logic       []LogicRule   `json:"logic,omitempty" db:"logic"`
+	IsScreener  bool          `json:"isScreener" db:"is_screener"`
+	Eligibility *ScreenerRule `json:"eligibility,omitempty" db:"eligibility"`
+	SortOrder   int           `json:"sortOrder" db:"sort_order"`
+	CreatedAt   time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updatedAt" db:"updated_at"`
 }
 
 // Response represents a survey response
 type Response struct {
-	ID            uuid.UUID  `json:"id" db:"id"`
-	SurveyID      uuid.UUID  `json:"surveyId" db:"survey_id"`
-	UserID        *uuid.UUID `json:"userId,omitempty" db:"user_id"`
-	AnonymousID   *string    `json:"anonymousId,omitempty" db:"anonymous_id"`
-	Status        string     `json:"status" db:"status"`
-	PointsAwarded int        `json:"pointsAwarded" db:"points_awarded"`
-	StartedAt     time.Time  `json:"startedAt" db:"started_at"`
-	CompletedAt   *time.Time `json:"completedAt,omitempty" db:"completed_at"`
-	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
-	Answers       []Answer   `json:"answers,omitempty"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	SurveyID       uuid.UUID  `json:"surveyId" db:"survey_id"`
+	UserID         *uuid.UUID `json:"userId,omitempty" db:"user_id"`
+	AnonymousID    *string    `json:"anonymousId,omitempty" db:"anonymous_id"`
+	Status         string     `json:"status" db:"status"`
+	PointsAwarded  int        `json:"pointsAwarded" db:"points_awarded"`
+	StartedAt      time.Time  `json:"startedAt" db:"started_at"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+	LastActivityAt time.Time  `json:"lastActivityAt" db:"last_activity_at"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	ResumeToken    *string    `json:"resumeToken,omitempty"` // Only set when the token is issued
+	Answers        []Answer   `json:"answers,omitempty"`
 }
 
 // AnswerValue is a flexible container for different answer types
@@ -117,6 +162,60 @@ type Dataset struct {
 	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
 }
 
+// SurveyQuota represents a response quota for a survey.
+// A quota without QuestionID is the overall quota for the survey.
+type SurveyQuota struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	SurveyID         uuid.UUID  `json:"surveyId" db:"survey_id"`
+	Name             string     `json:"name" db:"name"`
+	QuestionID       *uuid.UUID `json:"questionId,omitempty" db:"question_id"`
+	OptionValue      *string    `json:"optionValue,omitempty" db:"option_value"`
+	Target           int        `json:"target" db:"target"`
+	CurrentCount     int        `json:"currentCount" db:"current_count"`
+	ScreenOutMessage *string    `json:"screenOutMessage,omitempty" db:"screen_out_message"`
+	IsActive         bool       `json:"isActive" db:"is_active"`
+	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// SurveyStats represents response statistics for a survey
+type SurveyStats struct {
+	SurveyID         uuid.UUID `json:"surveyId"`
+	Started          int       `json:"started"`
+	InProgress       int       `json:"inProgress"`
+	Completed        int       `json:"completed"`
+	Abandoned        int       `json:"abandoned"`
+	QuotaFull        int       `json:"quotaFull"`
+	Disqualified     int       `json:"disqualified"`
+	CompletionRate   float64   `json:"completionRate"`
+	ScreenOutRate    float64   `json:"screenOutRate"`
+	DisqualifiedRate float64   `json:"disqualifiedRate"`
+}
+
+// Notification represents a notification for a user
+type Notification struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"userId" db:"user_id"`
+	Type      string     `json:"type" db:"type"`
+	Title     string     `json:"title" db:"title"`
+	Message   *string    `json:"message,omitempty" db:"message"`
+	SurveyID  *uuid.UUID `json:"surveyId,omitempty" db:"survey_id"`
+	IsRead    bool       `json:"isRead" db:"is_read"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// AuditLog represents an audited admin action
+type AuditLog struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	ActorID    *uuid.UUID             `json:"actorId,omitempty" db:"actor_id"`
+	Action     string                 `json:"action" db:"action"`
+	TargetType string                 `json:"targetType" db:"target_type"`
+	TargetID   *uuid.UUID             `json:"targetId,omitempty" db:"target_id"`
+	Details    map[string]interface{} `json:"details,omitempty" db:"details"`
+	IPAddress  *string                `json:"ipAddress,omitempty" db:"ip_address"`
+	CreatedAt  time.Time              `json:"createdAt" db:"created_at"`
+}
+
 // PointsTransaction represents a points transaction
 type PointsTransaction struct {
 	ID          uuid.UUID  `json:"id" db:"id"`
@@ -129,6 +228,9 @@ type PointsTransaction struct {
 	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
 }
 
+// Valid user roles, from least to most privileged
+var ValidRoles = []string{"user", "moderator", "admin"}
+
 // Valid question types
 var ValidQuestionTypes = []string{
 	"single", "multi", "text", "short", "long", "rating", "date", "select", "section",
@@ -138,7 +240,13 @@ var ValidQuestionTypes = []string{
 var ValidVisibilityOptions = []string{"public", "non-public"}
 
 // Valid response statuses
-var ValidResponseStatuses = []string{"in_progress", "completed", "abandoned"}
+var ValidResponseStatuses = []string{"in_progress", "completed", "abandoned", "quota_full", "disqualified"}
+
+// Valid genders for respondent profiles
+var ValidGenders = []string{"male", "female", "non-binary", "other", "prefer_not_to_say"}
+
+// Valid notification types
+var ValidNotificationTypes = []string{"survey_opened", "survey_closed"}
 
 // Valid access types
 var ValidAccessTypes = []string{"free", "paid"}
@@ -147,3 +255,84 @@ var ValidAccessTypes = []string{"free", "paid"}
 var ValidTransactionTypes = []string{
 	"survey_reward", "dataset_purchase", "dataset_sale", "admin_grant", "referral",
 }
+
+// Organization represents a team workspace that owns shared surveys
+type Organization struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty" db:"created_by"`
+	Role      string     `json:"role,omitempty"` // The current user's membership role
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// OrganizationMember represents a user's membership in an organization
+type OrganizationMember struct {
+	OrganizationID uuid.UUID `json:"organizationId" db:"organization_id"`
+	UserID         uuid.UUID `json:"userId" db:"user_id"`
+	Role           string    `json:"role" db:"role"`
+	Email          *string   `json:"email,omitempty"`
+	DisplayName    *string   `json:"displayName,omitempty"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Survey access roles, held by organization members and survey collaborators
+const (
+	AccessRoleOwner   = "owner"
+	AccessRoleEditor  = "editor"
+	AccessRoleAnalyst = "analyst"
+	AccessRoleViewer  = "viewer"
+)
+
+// ValidAccessRoles lists the roles a member can hold on shared surveys
+var ValidAccessRoles = []string{AccessRoleOwner, AccessRoleEditor, AccessRoleAnalyst, AccessRoleViewer}
+
+// ValidCollaboratorRoles lists the roles a collaborator can hold on a single survey
+var ValidCollaboratorRoles = []string{AccessRoleEditor, AccessRoleAnalyst, AccessRoleViewer}
+
+// SurveyCollaborator represents a user invited to work on a single survey
+type SurveyCollaborator struct {
+	SurveyID    uuid.UUID  `json:"surveyId" db:"survey_id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Role        string     `json:"role" db:"role"`
+	Email       *string    `json:"email,omitempty"`
+	DisplayName *string    `json:"displayName,omitempty"`
+	InvitedBy   *uuid.UUID `json:"invitedBy,omitempty" db:"invited_by"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// SurveyInvitation represents a pending or accepted invitation to collaborate on a survey
+type SurveyInvitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	SurveyID   uuid.UUID  `json:"surveyId" db:"survey_id"`
+	Email      string     `json:"email" db:"email"`
+	Role       string     `json:"role" db:"role"`
+	InvitedBy  *uuid.UUID `json:"invitedBy,omitempty" db:"invited_by"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty" db:"accepted_at"`
+	AcceptedBy *uuid.UUID `json:"acceptedBy,omitempty" db:"accepted_by"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// SurveyTemplate is a reusable survey definition that can be instantiated into a new draft
+type SurveyTemplate struct {
+	ID                      uuid.UUID    `json:"id" db:"id"`
+	Slug                    *string      `json:"slug,omitempty" db:"slug"` // Set for curated templates
+	Category                string       `json:"category" db:"category"`
+	Title                   string       `json:"title" db:"title"`
+	Description             string       `json:"description" db:"description"`
+	Theme                   *SurveyTheme `json:"theme,omitempty" db:"theme"`
+	DisqualificationMessage *string      `json:"disqualificationMessage,omitempty" db:"disqualification_message"`
+	Questions               []Question   `json:"questions" db:"questions"`
+	IsPublic                bool         `json:"isPublic" db:"is_public"`
+	IsCurated               bool         `json:"isCurated" db:"is_curated"`
+	CreatedBy               *uuid.UUID   `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt               time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time    `json:"updatedAt" db:"updated_at"`
+}
+
+// TemplateCategory summarizes the public templates of a category
+type TemplateCategory struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "github.com/google/uuid"
+
+// SurveyPages splits questions into pages the way the survey renderer does:
+// each section question starts a new page
+func SurveyPages(questions []Question) [][]Question {
+	var pages [][]Question
+	for _, q := range questions {
+		if q.Type == "section" || len(pages) == 0 {
+			pages = append(pages, nil)
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], q)
+	}
+	return pages
+}
+
+// LogicPath returns the questions shown to a respondent, in order, given the
+// options they chose by question ID. Like the survey renderer, pages follow
+// each other unless a question on the page has a logic rule whose trigger
+// option was chosen; the first such rule jumps to the page containing its
+// destination, or ends the survey. A page is visited at most once.
+func LogicPath(questions []Question, choices map[uuid.UUID]string) []Question {
+	pages := SurveyPages(questions)
+	pageOf := make(map[string]int, len(questions))
+	for i, page := range pages {
+		for _, q := range page {
+			pageOf[q.ID.String()] = i
+		}
+	}
+
+	var path []Question
+	visited := make(map[int]bool)
+	for current := 0; current < len(pages) && !visited[current]; {
+		visited[current] = true
+		path = append(path, pages[current]...)
+
+		next := current + 1
+	page:
+		for _, q := range pages[current] {
+			choice, ok := choices[q.ID]
+			if !ok {
+				continue
+			}
+			for _, rule := range q.Logic {
+				if rule.TriggerOption != choice {
+					continue
+				}
+				if rule.DestinationQuestionID == EndSurveyDestination {
+					return path
+				}
+				if destination, ok := pageOf[rule.DestinationQuestionID]; ok {
+					next = destination
+					break page
+				}
+				// Like the renderer, only the first matching rule of a question counts
+				break
+			}
+		}
+		current = next
+	}
+	return path
+}
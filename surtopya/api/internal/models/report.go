@@ -0,0 +1,248 @@
+package models
+
+import (
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/google/uuid"
+)
+
+// ReportFilter selects the responses included in a survey report
+type ReportFilter struct {
+	From     *time.Time         `json:"from,omitempty"` // Inclusive, on completion (or start) time
+	To       *time.Time         `json:"to,omitempty"`   // Exclusive
+	Statuses []string           `json:"statuses"`       // Empty means all statuses
+	Segment  []SegmentCondition `json:"segment,omitempty"`
+}
+
+// Segment operators
+const (
+	SegmentIn          = "in"           // The answer includes one of Values
+	SegmentNotIn       = "not_in"       // The answer includes none of Values, or the question was skipped
+	SegmentBetween     = "between"      // The rating is within Min and Max, inclusive
+	SegmentAnswered    = "answered"     // The question was answered
+	SegmentNotAnswered = "not_answered" // The question was skipped
+)
+
+// SegmentCondition restricts a report to respondents by their answer to a
+// question, such as "chose Student in Q2". All conditions of a segment must hold.
+type SegmentCondition struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Operator   string    `json:"operator"`
+	Values     []string  `json:"values,omitempty"` // Options, or ratings and dates as text
+	Min        *float64  `json:"min,omitempty"`
+	Max        *float64  `json:"max,omitempty"`
+}
+
+// SurveyReport holds per-question aggregates of a survey's answers
+type SurveyReport struct {
+	SurveyID    uuid.UUID             `json:"surveyId"`
+	Filter      ReportFilter          `json:"filter"`
+	Responses   int                   `json:"responses"`           // Responses matching the filter
+	Confidence  float64               `json:"confidence"`          // Level of the confidence intervals
+	Weighting   *WeightingDiagnostics `json:"weighting,omitempty"` // Set on weighted reports
+	Questions   []QuestionReport      `json:"questions"`
+	GeneratedAt time.Time             `json:"generatedAt"`
+}
+
+// QuestionReport aggregates the answers to one question. Only the field
+// matching the question type is set.
+type QuestionReport struct {
+	QuestionID uuid.UUID      `json:"questionId"`
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Answered   int            `json:"answered"`
+	Skipped    int            `json:"skipped"`
+	Options    []OptionCount  `json:"options,omitempty"` // single, multi, select
+	Rating     *RatingSummary `json:"rating,omitempty"`
+	Dates      *DateHistogram `json:"dates,omitempty"`
+	Words      []WordCount    `json:"words,omitempty"` // text, short, long
+	Codes      []CodeCount    `json:"codes,omitempty"` // text, short, long, when a codebook exists
+}
+
+// OptionCount is how often an option was chosen. Percentage is of the
+// responses that answered the question, so multi-choice percentages can add
+// up to more than 100. CI is the confidence interval of the proportion.
+type OptionCount struct {
+	Option     string          `json:"option"`
+	Count      int             `json:"count"`
+	Percentage float64         `json:"percentage"`
+	CI         *stats.Interval `json:"ci,omitempty"`
+	Weighted   *WeightedShare  `json:"weighted,omitempty"` // Set on weighted reports
+}
+
+// WeightedShare is a weighted estimate of an option's share. Its CI uses the
+// effective sample size of the weights of the responses that answered.
+type WeightedShare struct {
+	Percentage float64         `json:"percentage"`
+	CI         *stats.Interval `json:"ci,omitempty"`
+}
+
+// RatingSummary describes the answers to a rating question
+type RatingSummary struct {
+	Mean         *float64        `json:"mean"`
+	Median       *float64        `json:"median"`
+	StdDev       *float64        `json:"stdDev"`
+	MeanCI       *stats.Interval `json:"meanCI,omitempty"`
+	WeightedMean *stats.Interval `json:"weightedMean,omitempty"` // Set on weighted reports
+	Distribution []RatingCount   `json:"distribution"`
+}
+
+// RatingCount is how often a rating was given
+type RatingCount struct {
+	Rating     int     `json:"rating"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// DateHistogram counts date answers per interval (day, week, month or year)
+type DateHistogram struct {
+	Interval string       `json:"interval"`
+	Earliest *string      `json:"earliest"`
+	Latest   *string      `json:"latest"`
+	Buckets  []DateBucket `json:"buckets"`
+}
+
+// DateBucket is the number of date answers in the interval starting at Start
+type DateBucket struct {
+	Start string `json:"start"` // YYYY-MM-DD
+	Count int    `json:"count"`
+}
+
+// WordCount is how often a word occurs in text answers
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Crosstab cross-tabulates the answers to two questions. Counts[i][j] is the
+// number of responses with row category i and column category j; a
+// multi-choice answer counts once for each chosen option.
+type Crosstab struct {
+	SurveyID          uuid.UUID            `json:"surveyId"`
+	Filter            ReportFilter         `json:"filter"`
+	Row               CrosstabAxis         `json:"row"`
+	Column            CrosstabAxis         `json:"column"`
+	Counts            [][]int              `json:"counts"`
+	RowTotals         []int                `json:"rowTotals"`
+	ColumnTotals      []int                `json:"columnTotals"`
+	Total             int                  `json:"total"`
+	RowPercentages    [][]float64          `json:"rowPercentages"`    // Of the row total
+	ColumnPercentages [][]float64          `json:"columnPercentages"` // Of the column total
+	ChiSquare         *stats.ChiSquareTest `json:"chiSquare"`         // Nil when the test does not apply
+	Notes             []string             `json:"notes"`
+}
+
+// CrosstabAxis is a question used as the rows or columns of a crosstab
+type CrosstabAxis struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	Categories []string  `json:"categories"`
+}
+
+// RatingGroup summarizes the ratings of one segment in a comparison
+type RatingGroup struct {
+	Segment []SegmentCondition `json:"segment"`
+	N       int                `json:"n"`
+	MeanCI  *stats.Interval    `json:"meanCI"`
+}
+
+// SegmentComparison compares the ratings of a question between two segments
+type SegmentComparison struct {
+	SurveyID    uuid.UUID              `json:"surveyId"`
+	QuestionID  uuid.UUID              `json:"questionId"`
+	Filter      ReportFilter           `json:"filter"`
+	A           RatingGroup            `json:"a"`
+	B           RatingGroup            `json:"b"`
+	TTest       *stats.TTest           `json:"tTest"`       // Nil with fewer than two ratings per group
+	MannWhitney *stats.MannWhitneyTest `json:"mannWhitney"` // Nil when a group has no ratings
+}
+
+// ReliabilityReport is the internal consistency of a set of rating questions
+type ReliabilityReport struct {
+	SurveyID    uuid.UUID          `json:"surveyId"`
+	QuestionIDs []uuid.UUID        `json:"questionIds"`
+	Filter      ReportFilter       `json:"filter"`
+	Reliability *stats.Reliability `json:"reliability"` // Over responses that rated every question; nil if too few
+}
+
+// CorrelationMatrix holds the pairwise correlations of rating questions.
+// Correlations[i][j] uses the responses that rated both questions i and j,
+// and is nil when there are too few of them.
+type CorrelationMatrix struct {
+	SurveyID     uuid.UUID              `json:"surveyId"`
+	QuestionIDs  []uuid.UUID            `json:"questionIds"`
+	Filter       ReportFilter           `json:"filter"`
+	Method       string                 `json:"method"`
+	Correlations [][]*stats.Correlation `json:"correlations"`
+}
+
+// Funnel step types besides question types
+const (
+	FunnelStepSubmit = "submit" // Submitting the answered survey
+)
+
+// Funnel shows where respondents stop answering a survey. Each response is
+// followed along its skip-logic path, so questions a respondent was routed
+// past do not count as drop-off.
+type Funnel struct {
+	SurveyID                uuid.UUID    `json:"surveyId"`
+	Filter                  ReportFilter `json:"filter"`
+	Responses               int          `json:"responses"` // Responses started
+	Completed               int          `json:"completed"`
+	ScreenedOut             int          `json:"screenedOut"` // Disqualified or over quota
+	Abandoned               int          `json:"abandoned"`
+	InProgress              int          `json:"inProgress"`
+	CompletionRate          float64      `json:"completionRate"`
+	MedianCompletionSeconds *float64     `json:"medianCompletionSeconds"`
+	Steps                   []FunnelStep `json:"steps"`
+	Notes                   []string     `json:"notes"`
+}
+
+// FunnelStep is a question, section or the final submission in a funnel.
+// A respondent reaches a step when it is on their path and they answered up
+// to it; they drop off at the step following their last answer.
+type FunnelStep struct {
+	QuestionID    *uuid.UUID `json:"questionId"` // Nil for the submit step
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Reached       int        `json:"reached"`
+	Answered      int        `json:"answered"`
+	Bypassed      int        `json:"bypassed"`   // Routed past the step by skip logic
+	DroppedOff    int        `json:"droppedOff"` // Abandoned at this step
+	InProgress    int        `json:"inProgress"` // Still answering, currently at this step
+	DropOffRate   float64    `json:"dropOffRate"`
+	MedianSeconds *float64   `json:"medianSeconds"` // Median time to answer, from the previous answer
+	TimedAnswers  int        `json:"timedAnswers"`
+}
+
+// ResponseEvent announces a completed response to owners watching a survey
+// live, with what it adds to the survey's aggregates
+type ResponseEvent struct {
+	SurveyID    uuid.UUID     `json:"surveyId"`
+	ResponseID  uuid.UUID     `json:"responseId"`
+	CompletedAt time.Time     `json:"completedAt"`
+	Completed   int           `json:"completed"` // Completed responses when the event was sent
+	Deltas      []AnswerDelta `json:"deltas"`
+}
+
+// AnswerDelta is what one answer adds to its question's aggregates: one more
+// answered response, one more count for each chosen option, and the rating
+type AnswerDelta struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Options    []string  `json:"options,omitempty"` // single, multi, select
+	Rating     *int      `json:"rating,omitempty"`
+}
+
+// SurveyInsight is an AI-written summary of a survey's results, cached per
+// survey revision and model
+type SurveyInsight struct {
+	SurveyID  uuid.UUID `json:"surveyId"`
+	Revision  int       `json:"revision"`
+	Model     string    `json:"model"`
+	Responses int       `json:"responses"` // Completed responses summarized
+	Summary   string    `json:"summary"`
+	Stale     bool      `json:"stale"` // Responses have arrived since it was generated
+	CreatedAt time.Time `json:"createdAt"`
+}
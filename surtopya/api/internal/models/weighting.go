@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/google/uuid"
+)
+
+// WeightingScheme holds the population margins a survey's responses are
+// raked to, such as the gender, age band and region shares of a census
+type WeightingScheme struct {
+	SurveyID  uuid.UUID         `json:"surveyId" db:"survey_id"`
+	Margins   []WeightingMargin `json:"margins" db:"margins"`
+	MaxWeight *float64          `json:"maxWeight,omitempty" db:"max_weight"` // Cap as a multiple of the mean weight
+	UpdatedBy *uuid.UUID        `json:"updatedBy,omitempty" db:"updated_by"`
+	CreatedAt time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time         `json:"updatedAt" db:"updated_at"`
+}
+
+// WeightingMargin is the population distribution of the answers to one
+// single-choice or dropdown question
+type WeightingMargin struct {
+	QuestionID uuid.UUID         `json:"questionId"`
+	Targets    []WeightingTarget `json:"targets"`
+}
+
+// WeightingTarget is the population share of an option. The shares of a
+// margin are scaled to sum to 1, so they can be given as percentages.
+type WeightingTarget struct {
+	Option string  `json:"option"`
+	Share  float64 `json:"share"`
+}
+
+// WeightingDiagnostics describes the weights computed for a set of responses
+type WeightingDiagnostics struct {
+	stats.Raking
+	Responses int         `json:"responses"`
+	Margins   []MarginFit `json:"margins"`
+}
+
+// MarginFit compares a margin's target, unweighted and weighted
+// distributions, as percentages of the responses with one of its options.
+// Missing counts responses that skipped the question or chose an option
+// without a target; they are not adjusted for the margin.
+type MarginFit struct {
+	QuestionID uuid.UUID     `json:"questionId"`
+	Title      string        `json:"title"`
+	Missing    int           `json:"missing"`
+	Categories []CategoryFit `json:"categories"`
+}
+
+// CategoryFit is one option of a margin
+type CategoryFit struct {
+	Option    string  `json:"option"`
+	Responses int     `json:"responses"`
+	Target    float64 `json:"target"`
+	Sample    float64 `json:"sample"`
+	Weighted  float64 `json:"weighted"`
+}
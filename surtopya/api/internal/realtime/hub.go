@@ -0,0 +1,190 @@
+// Package realtime streams survey activity to live dashboards. Completed
+// responses are announced by a database trigger with NOTIFY, so every API
+// instance hears about them whichever instance saved the response.
+package realtime
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Channel is the notification channel of the notify_response_completed trigger
+const Channel = "survey_responses"
+
+// Event names sent to subscribers
+const (
+	EventResponse = "response" // A response was completed; Data is a *models.ResponseEvent
+	EventResync   = "resync"   // Notifications may have been missed; reload the aggregates
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped
+const subscriberBuffer = 32
+
+// Event is a message for the subscribers of a survey
+type Event struct {
+	Name string
+	Data interface{}
+}
+
+// notification is the payload of the trigger's NOTIFY
+type notification struct {
+	SurveyID   uuid.UUID `json:"surveyId"`
+	ResponseID uuid.UUID `json:"responseId"`
+}
+
+// Hub fans out response notifications to the subscribers of each survey
+type Hub struct {
+	listener   *pq.Listener
+	reportRepo *repository.ReportRepository
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+}
+
+var hub *Hub
+
+// Start listens for response notifications until the context is cancelled,
+// making the hub available through GetHub
+func Start(ctx context.Context, db *sql.DB, connStr string) error {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Realtime listener: %v", err)
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for response notifications: %w", err)
+	}
+
+	hub = &Hub{
+		listener:    listener,
+		reportRepo:  repository.NewReportRepository(db),
+		subscribers: make(map[uuid.UUID]map[chan Event]struct{}),
+	}
+	go hub.run(ctx)
+	return nil
+}
+
+// GetHub returns the running hub, or nil if it was not started
+func GetHub() *Hub {
+	return hub
+}
+
+// Subscribe returns the events of a survey and a function ending the
+// subscription. The channel is closed if the subscriber falls behind.
+func (h *Hub) Subscribe(surveyID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[surveyID] == nil {
+		h.subscribers[surveyID] = make(map[chan Event]struct{})
+	}
+	h.subscribers[surveyID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(surveyID, ch)
+	}
+}
+
+// remove closes a subscriber's channel if it is still subscribed; h.mu must be held
+func (h *Hub) remove(surveyID uuid.UUID, ch chan Event) {
+	if _, ok := h.subscribers[surveyID][ch]; !ok {
+		return
+	}
+	delete(h.subscribers[surveyID], ch)
+	if len(h.subscribers[surveyID]) == 0 {
+		delete(h.subscribers, surveyID)
+	}
+	close(ch)
+}
+
+func (h *Hub) run(ctx context.Context) {
+	defer h.listener.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case n := <-h.listener.Notify:
+			if n == nil {
+				// The connection was re-established; notifications sent
+				// while it was down are lost
+				h.broadcast(Event{Name: EventResync})
+				continue
+			}
+			h.handle(n.Extra)
+
+		case <-time.After(90 * time.Second):
+			go h.listener.Ping()
+		}
+	}
+}
+
+// handle builds the event for a notification and sends it to the survey's subscribers
+func (h *Hub) handle(payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Printf("Realtime: invalid notification %q: %v", payload, err)
+		return
+	}
+
+	// Only instances with subscribers for the survey load the response
+	h.mu.Lock()
+	watched := len(h.subscribers[n.SurveyID]) > 0
+	h.mu.Unlock()
+	if !watched {
+		return
+	}
+
+	event, err := h.reportRepo.GetResponseEvent(n.ResponseID)
+	if err != nil {
+		log.Printf("Realtime: %v", err)
+		return
+	}
+	if event == nil {
+		return
+	}
+	h.send(n.SurveyID, Event{Name: EventResponse, Data: event})
+}
+
+// send delivers an event to a survey's subscribers, dropping any that are full
+func (h *Hub) send(surveyID uuid.UUID, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[surveyID] {
+		select {
+		case ch <- event:
+		default:
+			h.remove(surveyID, ch)
+		}
+	}
+}
+
+// broadcast delivers an event to every subscriber
+func (h *Hub) broadcast(event Event) {
+	h.mu.Lock()
+	surveyIDs := make([]uuid.UUID, 0, len(h.subscribers))
+	for surveyID := range h.subscribers {
+		surveyIDs = append(surveyIDs, surveyID)
+	}
+	h.mu.Unlock()
+
+	for _, surveyID := range surveyIDs {
+		h.send(surveyID, event)
+	}
+}
@@ -0,0 +1,103 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+// markdownPageBreak is understood by Markdown-to-PDF converters that render HTML
+const markdownPageBreak = `<div style="page-break-before: always;"></div>`
+
+// markdownEscaper escapes characters with a meaning in Markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`#`, `\#`, `<`, `\<`, `>`, `\>`, `|`, `\|`,
+)
+
+func escape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// Markdown renders a survey as a printable Markdown questionnaire
+func Markdown(survey *models.Survey) string {
+	o := newOutline(survey.Questions)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", escape(survey.Title))
+	if survey.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", escape(survey.Description))
+	}
+	b.WriteString("_Questions marked \\* are required._\n\n")
+
+	for i, q := range survey.Questions {
+		if q.Type == "section" {
+			if i > 0 {
+				b.WriteString(markdownPageBreak + "\n\n")
+			}
+			fmt.Fprintf(&b, "## %s\n\n", escape(o.sectionLabel(q)))
+			if d := description(q); d != "" {
+				fmt.Fprintf(&b, "%s\n\n", escape(d))
+			}
+			continue
+		}
+
+		fmt.Fprintf(&b, "**%s. %s**", o.questionLabel(q), escape(q.Title))
+		if q.Required {
+			b.WriteString(" \\*")
+		}
+		b.WriteString("\n\n")
+		if d := description(q); d != "" {
+			fmt.Fprintf(&b, "%s\n\n", escape(d))
+		}
+		if text := instruction(q); text != "" {
+			fmt.Fprintf(&b, "_%s_\n\n", text)
+		}
+
+		writeMarkdownAnswer(&b, q)
+
+		for _, note := range o.logicNotes(q) {
+			fmt.Fprintf(&b, "> ➔ %s\n", escape(note))
+		}
+		if len(q.Logic) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// writeMarkdownAnswer writes the answer area of a question
+func writeMarkdownAnswer(b *strings.Builder, q models.Question) {
+	switch q.Type {
+	case "single", "select":
+		for _, option := range q.Options {
+			fmt.Fprintf(b, "- ◯ %s\n", escape(option))
+		}
+		b.WriteString("\n")
+
+	case "multi":
+		for _, option := range q.Options {
+			fmt.Fprintf(b, "- [ ] %s\n", escape(option))
+		}
+		b.WriteString("\n")
+
+	case "rating":
+		points := make([]string, maxRating(q))
+		for i := range points {
+			points[i] = "◯ " + strconv.Itoa(i+1)
+		}
+		fmt.Fprintf(b, "%s\n\n", strings.Join(points, "&emsp;"))
+
+	case "date":
+		b.WriteString("\\_\\_\\_\\_\\_\\_ / \\_\\_\\_\\_ / \\_\\_\\_\\_\n\n")
+
+	default:
+		for i := 0; i < answerLines(q); i++ {
+			b.WriteString(strings.Repeat("\\_", 60) + "  \n")
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	md := Markdown(sampleSurvey())
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"title", "# Coffee habits\n\nA short survey\n\n"},
+		{"first section without a page break", "_Questions marked \\* are required._\n\n## Section 1: Screening\n\n"},
+		{"required question", "**Q1. Do you drink coffee?** \\*\n\n_Choose one._\n\n- ◯ Yes\n- ◯ No\n\n"},
+		{"routing notes", "> ➔ If \"Yes\", go to Section 2.\n> ➔ If \"No\", go to the end of the survey.\n\n"},
+		{"rating scale", "◯ 1&emsp;◯ 2&emsp;◯ 3&emsp;◯ 4&emsp;◯ 5\n\n"},
+		{"later section with a page break", markdownPageBreak + "\n\n## Section 2\n\n"},
+		{"description and writing lines", "**Q3. Why?**\n\nBe honest\n\n" + strings.Repeat("\\_", 60) + "  \n"},
+		{"escaped markdown", "**Q4. Which \\*brands\\*?**\n\n_Choose all that apply._\n\n- [ ] A\\_1\n- [ ] B\n\n> ➔ If \"A\\_1\", go to Q2.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(md, tt.want) {
+				t.Errorf("Markdown does not contain %q:\n%s", tt.want, md)
+			}
+		})
+	}
+
+	if strings.Count(md, markdownPageBreak) != 1 {
+		t.Errorf("want one page break, got %d", strings.Count(md, markdownPageBreak))
+	}
+	if strings.Contains(md, `If "B"`) {
+		t.Error("routing to a deleted question should not be printed")
+	}
+}
@@ -0,0 +1,136 @@
+// Package render produces printable copies of a survey questionnaire, as PDF
+// and as Markdown, for ethics review submissions and paper fieldwork.
+//
+// Section questions start a new page. Questions are numbered Q1, Q2, ...
+// (sections are not numbered as questions), and logic rules are printed as
+// routing notes such as "If "Yes", go to Q7."
+package render
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+// outline numbers the questions and sections of a survey so logic
+// destinations can be printed as references
+type outline struct {
+	questions     []models.Question
+	numbers       map[string]int // Question ID -> question number
+	sections      map[string]int // Section question ID -> section number
+	sectionTitles map[string]string
+}
+
+func newOutline(questions []models.Question) *outline {
+	o := &outline{
+		questions:     questions,
+		numbers:       make(map[string]int),
+		sections:      make(map[string]int),
+		sectionTitles: make(map[string]string),
+	}
+
+	for _, q := range questions {
+		id := q.ID.String()
+		if q.Type == "section" {
+			o.sections[id] = len(o.sections) + 1
+			o.sectionTitles[id] = q.Title
+			continue
+		}
+		o.numbers[id] = len(o.numbers) + 1
+	}
+
+	return o
+}
+
+// questionLabel returns the printed number of a question, such as "Q3"
+func (o *outline) questionLabel(q models.Question) string {
+	return "Q" + strconv.Itoa(o.numbers[q.ID.String()])
+}
+
+// sectionLabel returns the printed heading of a section
+func (o *outline) sectionLabel(q models.Question) string {
+	n := o.sections[q.ID.String()]
+	if q.Title == "" {
+		return fmt.Sprintf("Section %d", n)
+	}
+	return fmt.Sprintf("Section %d: %s", n, q.Title)
+}
+
+// destination describes where a logic rule leads
+func (o *outline) destination(id string) string {
+	if id == models.EndSurveyDestination {
+		return "the end of the survey"
+	}
+	if n, ok := o.numbers[id]; ok {
+		return "Q" + strconv.Itoa(n)
+	}
+	if n, ok := o.sections[id]; ok {
+		if title := o.sectionTitles[id]; title != "" {
+			return fmt.Sprintf("Section %d (%s)", n, title)
+		}
+		return fmt.Sprintf("Section %d", n)
+	}
+	return ""
+}
+
+// logicNotes returns the routing notes of a question, skipping rules whose
+// destination no longer exists
+func (o *outline) logicNotes(q models.Question) []string {
+	var notes []string
+	for _, rule := range q.Logic {
+		destination := o.destination(rule.DestinationQuestionID)
+		if destination == "" {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("If %q, go to %s.", rule.TriggerOption, destination))
+	}
+	return notes
+}
+
+// RoutingNotes returns the routing notes of a survey question, such as
+// "If "Yes", go to Q7.", numbering questions as the printed survey does
+func RoutingNotes(questions []models.Question, q models.Question) []string {
+	return newOutline(questions).logicNotes(q)
+}
+
+// instruction returns the answering instruction printed under a question
+func instruction(q models.Question) string {
+	switch q.Type {
+	case "single", "select":
+		return "Choose one."
+	case "multi":
+		return "Choose all that apply."
+	case "rating":
+		return "Circle one number."
+	case "date":
+		return "Write the date as YYYY / MM / DD."
+	default:
+		return ""
+	}
+}
+
+// maxRating returns the top of a rating scale, defaulting to 5
+func maxRating(q models.Question) int {
+	if q.MaxRating > 0 {
+		return q.MaxRating
+	}
+	return 5
+}
+
+// answerLines returns the number of writing lines for a text question
+func answerLines(q models.Question) int {
+	switch q.Type {
+	case "text", "long":
+		return 5
+	default:
+		return 1
+	}
+}
+
+func description(q models.Question) string {
+	if q.Description == nil {
+		return ""
+	}
+	return *q.Description
+}
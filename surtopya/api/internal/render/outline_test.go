@@ -0,0 +1,69 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// sampleSurvey has two sections, routing to a section and to the end, and a
+// rule left pointing to a deleted question
+func sampleSurvey() *models.Survey {
+	screening := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	drink := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	rate := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	details := uuid.MustParse("00000000-0000-0000-0000-000000000004")
+	why := uuid.MustParse("00000000-0000-0000-0000-000000000005")
+	brands := uuid.MustParse("00000000-0000-0000-0000-000000000006")
+	honest := "Be honest"
+
+	return &models.Survey{
+		Title:       "Coffee habits",
+		Description: "A short survey",
+		Questions: []models.Question{
+			{ID: screening, Type: "section", Title: "Screening"},
+			{ID: drink, Type: "single", Title: "Do you drink coffee?", Required: true, Options: []string{"Yes", "No"},
+				Logic: []models.LogicRule{
+					{TriggerOption: "Yes", DestinationQuestionID: details.String()},
+					{TriggerOption: "No", DestinationQuestionID: models.EndSurveyDestination},
+				}},
+			{ID: rate, Type: "rating", Title: "How much do you like it?"},
+			{ID: details, Type: "section"},
+			{ID: why, Type: "long", Title: "Why?", Description: &honest},
+			{ID: brands, Type: "multi", Title: "Which *brands*?", Options: []string{"A_1", "B"},
+				Logic: []models.LogicRule{
+					{TriggerOption: "B", DestinationQuestionID: uuid.NewString()},
+					{TriggerOption: "A_1", DestinationQuestionID: rate.String()},
+				}},
+		},
+	}
+}
+
+func TestOutline(t *testing.T) {
+	survey := sampleSurvey()
+	o := newOutline(survey.Questions)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"question number", o.questionLabel(survey.Questions[4]), "Q3"},
+		{"titled section", o.sectionLabel(survey.Questions[0]), "Section 1: Screening"},
+		{"untitled section", o.sectionLabel(survey.Questions[3]), "Section 2"},
+		{"routing to a section and the end", o.logicNotes(survey.Questions[1]),
+			[]string{`If "Yes", go to Section 2.`, `If "No", go to the end of the survey.`}},
+		{"routing to a deleted question is skipped", RoutingNotes(survey.Questions, survey.Questions[5]),
+			[]string{`If "A_1", go to Q2.`}},
+		{"default rating scale", maxRating(survey.Questions[2]), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %#v, want %#v", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// ErrUnsupportedText is returned when a survey has text the built-in
+// Helvetica cannot render, such as Chinese, and no font is configured
+var ErrUnsupportedText = errors.New("survey text needs a font covering its script; set PDF_FONT_PATH to a TrueType font")
+
+// PDFOptions configures PDF rendering
+type PDFOptions struct {
+	// FontPath is a TrueType font used for all text. Without it the built-in
+	// Helvetica is used, which only covers Western European characters;
+	// other text fails with ErrUnsupportedText rather than printing garbage.
+	FontPath string
+}
+
+// Page layout in millimetres
+const (
+	pdfMargin     = 20.0
+	pdfIndent     = 4.0
+	pdfLineHeight = 6.0
+	pdfSmallLine  = 5.0
+	pdfOptionRow  = 7.0
+	pdfWriteRow   = 8.0
+	pdfRatingRow  = 12.0
+)
+
+// pdfRenderer draws a survey onto A4 pages
+type pdfRenderer struct {
+	pdf       *fpdf.Fpdf
+	outline   *outline
+	family    string
+	translate func(string) string
+	utf8      bool
+	missing   bool    // Text outside Helvetica's characters was drawn
+	width     float64 // Content width
+}
+
+// PDF renders a survey as a paginated A4 questionnaire
+func PDF(w io.Writer, survey *models.Survey, opts PDFOptions) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AliasNbPages("")
+	pageWidth, _ := pdf.GetPageSize()
+
+	r := &pdfRenderer{
+		pdf:     pdf,
+		outline: newOutline(survey.Questions),
+		width:   pageWidth - 2*pdfMargin,
+	}
+	if err := r.loadFont(opts.FontPath); err != nil {
+		return err
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(r.family, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		footer := fmt.Sprintf("%s - Page %d of {nb}", survey.Title, pdf.PageNo())
+		pdf.CellFormat(0, 10, r.translate(footer), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	r.header(survey)
+
+	for i, q := range survey.Questions {
+		if q.Type == "section" {
+			// Consecutive sections share a page rather than leaving one blank
+			if i > 0 && survey.Questions[i-1].Type != "section" {
+				pdf.AddPage()
+			}
+			r.section(q)
+			continue
+		}
+		r.question(q)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+	if r.missing {
+		return ErrUnsupportedText
+	}
+	return pdf.Output(w)
+}
+
+// loadFont registers the TrueType font, or falls back to Helvetica
+func (r *pdfRenderer) loadFont(path string) error {
+	if path == "" {
+		r.family = "Helvetica"
+		cp1252 := r.pdf.UnicodeTranslatorFromDescriptor("")
+		r.translate = func(s string) string {
+			// The translator turns characters outside the code page into dots
+			for _, c := range s {
+				if c >= 0x80 && cp1252(string(c)) == "." {
+					r.missing = true
+					break
+				}
+			}
+			return cp1252(s)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF font: %w", err)
+	}
+	r.family = "survey"
+	for _, style := range []string{"", "B", "I"} {
+		r.pdf.AddUTF8FontFromBytes(r.family, style, data)
+	}
+	if err := r.pdf.Error(); err != nil {
+		return fmt.Errorf("failed to load PDF font: %w", err)
+	}
+	r.translate = func(s string) string { return s }
+	r.utf8 = true
+	return nil
+}
+
+func (r *pdfRenderer) header(survey *models.Survey) {
+	r.pdf.SetFont(r.family, "B", 18)
+	r.pdf.MultiCell(r.width, 9, r.translate(survey.Title), "", "L", false)
+	if survey.Description != "" {
+		r.pdf.Ln(2)
+		r.pdf.SetFont(r.family, "", 11)
+		r.pdf.MultiCell(r.width, pdfLineHeight, r.translate(survey.Description), "", "L", false)
+	}
+	r.pdf.Ln(2)
+	r.note("Questions marked * are required.")
+	r.pdf.Ln(4)
+}
+
+func (r *pdfRenderer) section(q models.Question) {
+	r.pdf.SetFont(r.family, "B", 14)
+	r.pdf.MultiCell(r.width, 8, r.translate(r.outline.sectionLabel(q)), "B", "L", false)
+	if d := description(q); d != "" {
+		r.pdf.Ln(1)
+		r.pdf.SetFont(r.family, "", 10)
+		r.pdf.MultiCell(r.width, pdfSmallLine, r.translate(d), "", "L", false)
+	}
+	r.pdf.Ln(4)
+}
+
+func (r *pdfRenderer) question(q models.Question) {
+	heading := r.outline.questionLabel(q) + ". " + q.Title
+	if q.Required {
+		heading += " *"
+	}
+	notes := r.outline.logicNotes(q)
+
+	// Keep a question on one page when it fits on a page at all
+	_, pageHeight := r.pdf.GetPageSize()
+	height := r.height(q, heading, notes)
+	if r.pdf.GetY()+height > pageHeight-pdfMargin && height < pageHeight-2*pdfMargin {
+		r.pdf.AddPage()
+	}
+
+	r.pdf.SetFont(r.family, "B", 11)
+	r.pdf.MultiCell(r.width, pdfLineHeight, r.translate(heading), "", "L", false)
+	if d := description(q); d != "" {
+		r.pdf.SetFont(r.family, "", 10)
+		r.pdf.MultiCell(r.width, pdfSmallLine, r.translate(d), "", "L", false)
+	}
+	if text := instruction(q); text != "" {
+		r.note(text)
+	}
+	r.pdf.Ln(1)
+
+	r.answer(q)
+
+	for _, note := range notes {
+		r.note("» " + note)
+	}
+	r.pdf.Ln(5)
+}
+
+// height estimates the printed height of a question
+func (r *pdfRenderer) height(q models.Question, heading string, notes []string) float64 {
+	r.pdf.SetFont(r.family, "B", 11)
+	height := float64(r.lines(heading)) * pdfLineHeight
+	r.pdf.SetFont(r.family, "", 10)
+	if d := description(q); d != "" {
+		height += float64(r.lines(d)) * pdfSmallLine
+	}
+	if instruction(q) != "" {
+		height += pdfSmallLine
+	}
+
+	switch q.Type {
+	case "single", "select", "multi":
+		height += float64(len(q.Options)) * pdfOptionRow
+	case "rating":
+		height += pdfRatingRow
+	default:
+		height += float64(answerLines(q)) * pdfWriteRow
+	}
+
+	return height + float64(len(notes))*pdfSmallLine + 6
+}
+
+// lines returns the number of lines text wraps to in the current font
+func (r *pdfRenderer) lines(text string) int {
+	if r.utf8 {
+		return len(r.pdf.SplitText(text, r.width))
+	}
+	// Core font text is translated to single-byte code page characters
+	return len(r.pdf.SplitLines([]byte(r.translate(text)), r.width))
+}
+
+// answer draws the answer area of a question
+func (r *pdfRenderer) answer(q models.Question) {
+	left, _, _, _ := r.pdf.GetMargins()
+	x := left + pdfIndent
+
+	switch q.Type {
+	case "single", "select", "multi":
+		r.pdf.SetFont(r.family, "", 11)
+		for _, option := range q.Options {
+			y := r.pdf.GetY()
+			if q.Type == "multi" {
+				r.pdf.Rect(x, y+1.3, 3.6, 3.6, "D")
+			} else {
+				r.pdf.Circle(x+1.8, y+3.1, 1.8, "D")
+			}
+			r.pdf.SetXY(x+7, y+0.5)
+			r.pdf.MultiCell(r.width-pdfIndent-7, pdfLineHeight, r.translate(option), "", "L", false)
+			r.pdf.SetY(r.pdf.GetY() + pdfOptionRow - pdfLineHeight - 0.5)
+		}
+
+	case "rating":
+		points := maxRating(q)
+		spacing := (r.width - pdfIndent) / float64(points)
+		if spacing > 14 {
+			spacing = 14
+		}
+		y := r.pdf.GetY()
+		r.pdf.SetFont(r.family, "", 10)
+		for i := 0; i < points; i++ {
+			cellX := x + float64(i)*spacing
+			r.pdf.Circle(cellX+spacing/2, y+5, 4, "D")
+			r.pdf.SetXY(cellX, y+2.5)
+			r.pdf.CellFormat(spacing, pdfSmallLine, strconv.Itoa(i+1), "", 0, "C", false, 0, "")
+		}
+		r.pdf.SetY(y + pdfRatingRow)
+
+	case "date":
+		r.pdf.SetFont(r.family, "", 11)
+		y := r.pdf.GetY() + pdfWriteRow
+		r.pdf.Line(x, y, x+20, y)
+		r.pdf.Line(x+26, y, x+38, y)
+		r.pdf.Line(x+44, y, x+56, y)
+		r.pdf.SetXY(x+20, y-pdfSmallLine)
+		r.pdf.CellFormat(6, pdfSmallLine, "/", "", 0, "C", false, 0, "")
+		r.pdf.SetXY(x+38, y-pdfSmallLine)
+		r.pdf.CellFormat(6, pdfSmallLine, "/", "", 0, "C", false, 0, "")
+		r.pdf.SetY(y + 1)
+
+	default:
+		r.pdf.SetDrawColor(160, 160, 160)
+		for i := 0; i < answerLines(q); i++ {
+			y := r.pdf.GetY() + pdfWriteRow
+			r.pdf.Line(x, y, left+r.width, y)
+			r.pdf.SetY(y)
+		}
+		r.pdf.SetDrawColor(0, 0, 0)
+		r.pdf.Ln(1)
+	}
+}
+
+// note prints a line of small grey italic text
+func (r *pdfRenderer) note(text string) {
+	r.pdf.SetFont(r.family, "I", 9)
+	r.pdf.SetTextColor(96, 96, 96)
+	r.pdf.MultiCell(r.width, pdfSmallLine, r.translate(text), "", "L", false)
+	r.pdf.SetTextColor(0, 0, 0)
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+)
+
+func TestPDF(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *models.Survey)
+		opts   PDFOptions
+		want   error
+	}{
+		{"latin text", func(s *models.Survey) {}, PDFOptions{}, nil},
+		{"western european accents", func(s *models.Survey) { s.Title = "Café « crème » – 2€" }, PDFOptions{}, nil},
+		{"chinese title", func(s *models.Survey) { s.Title = "咖啡習慣" }, PDFOptions{}, ErrUnsupportedText},
+		{"chinese option", func(s *models.Survey) { s.Questions[1].Options[0] = "是" }, PDFOptions{}, ErrUnsupportedText},
+		{"cyrillic description", func(s *models.Survey) { s.Description = "Опрос" }, PDFOptions{}, ErrUnsupportedText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			survey := sampleSurvey()
+			tt.change(survey)
+
+			var buf bytes.Buffer
+			err := PDF(&buf, survey, tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if buf.Len() != 0 {
+					t.Error("no PDF should be written when text cannot be rendered")
+				}
+				return
+			}
+			if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) || !bytes.Contains(buf.Bytes(), []byte("%%EOF")) {
+				t.Error("output is not a complete PDF")
+			}
+		})
+	}
+}
+
+func TestPDFMissingFont(t *testing.T) {
+	var buf bytes.Buffer
+	err := PDF(&buf, sampleSurvey(), PDFOptions{FontPath: "testdata/missing.ttf"})
+	if err == nil || errors.Is(err, ErrUnsupportedText) {
+		t.Errorf("error = %v, want a font loading error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// AuditRepository handles audit log database operations
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create records an audit log entry
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	return insertAuditLog(r.db, entry)
+}
+
+// insertAuditLog records an audit log entry, in the transaction of the
+// action it audits when given one
+func insertAuditLog(db queryRower, entry *models.AuditLog) error {
+	detailsJSON, err := json.Marshal(entry.Details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_logs (id, actor_id, action, target_type, target_id, details, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err = db.QueryRow(
+		query,
+		entry.ID, entry.ActorID, entry.Action, entry.TargetType,
+		entry.TargetID, detailsJSON, entry.IPAddress,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// GetAll retrieves audit log entries, newest first, optionally filtered by actor or target
+func (r *AuditRepository) GetAll(actorID, targetID *uuid.UUID, limit, offset int) ([]models.AuditLog, error) {
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, details, ip_address, created_at
+		FROM audit_logs
+		WHERE ($1::uuid IS NULL OR actor_id = $1)
+			AND ($2::uuid IS NULL OR target_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, actorID, targetID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		var detailsJSON []byte
+
+		err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType,
+			&entry.TargetID, &detailsJSON, &entry.IPAddress, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+
+		if len(detailsJSON) > 0 {
+			json.Unmarshal(detailsJSON, &entry.Details)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CodingRepository handles codebook and answer coding database operations
+type CodingRepository struct {
+	db *sql.DB
+}
+
+// NewCodingRepository creates a new CodingRepository
+func NewCodingRepository(db *sql.DB) *CodingRepository {
+	return &CodingRepository{db: db}
+}
+
+// ErrDuplicateCodeLabel is returned when a question's codebook already has a code with the label
+var ErrDuplicateCodeLabel = errors.New("code label already exists for the question")
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+const codeColumns = `
+	c.id, c.survey_id, c.question_id, c.label, c.description, c.color, c.position,
+	(SELECT COUNT(*) FROM answer_code_assignments ca WHERE ca.code_id = c.id),
+	c.created_by, c.created_at, c.updated_at
+`
+
+func scanCode(row interface{ Scan(...interface{}) error }) (*models.AnswerCode, error) {
+	var code models.AnswerCode
+	err := row.Scan(
+		&code.ID, &code.SurveyID, &code.QuestionID, &code.Label, &code.Description, &code.Color,
+		&code.Position, &code.Answers, &code.CreatedBy, &code.CreatedAt, &code.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// GetCodes retrieves a survey's codebook, optionally only one question's, in order
+func (r *CodingRepository) GetCodes(surveyID uuid.UUID, questionID *uuid.UUID) ([]models.AnswerCode, error) {
+	query := `
+		SELECT ` + codeColumns + `
+		FROM answer_codes c
+		WHERE c.survey_id = $1 AND ($2::uuid IS NULL OR c.question_id = $2)
+		ORDER BY c.question_id, c.position, c.label
+	`
+
+	rows, err := r.db.Query(query, surveyID, questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answer codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []models.AnswerCode{}
+	for rows.Next() {
+		code, err := scanCode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan answer code: %w", err)
+		}
+		codes = append(codes, *code)
+	}
+
+	return codes, rows.Err()
+}
+
+// GetCode retrieves a code by ID
+func (r *CodingRepository) GetCode(id uuid.UUID) (*models.AnswerCode, error) {
+	code, err := scanCode(r.db.QueryRow(`SELECT `+codeColumns+` FROM answer_codes c WHERE c.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answer code: %w", err)
+	}
+	return code, nil
+}
+
+// CreateCode adds a code to the end of a question's codebook
+func (r *CodingRepository) CreateCode(code *models.AnswerCode) error {
+	code.ID = uuid.New()
+	query := `
+		INSERT INTO answer_codes (id, survey_id, question_id, label, description, color, position, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			(SELECT COALESCE(MAX(position) + 1, 0) FROM answer_codes WHERE question_id = $3), $7)
+		RETURNING position, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		code.ID, code.SurveyID, code.QuestionID, code.Label, code.Description, code.Color, code.CreatedBy,
+	).Scan(&code.Position, &code.CreatedAt, &code.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicateCodeLabel
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create answer code: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCode updates a code's label, description, color and position
+func (r *CodingRepository) UpdateCode(code *models.AnswerCode) error {
+	query := `
+		UPDATE answer_codes
+		SET label = $2, description = $3, color = $4, position = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, code.ID, code.Label, code.Description, code.Color, code.Position).Scan(&code.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicateCodeLabel
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update answer code: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCode deletes a code and its assignments
+func (r *CodingRepository) DeleteCode(id uuid.UUID) error {
+	if _, err := r.db.Exec("DELETE FROM answer_codes WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete answer code: %w", err)
+	}
+	return nil
+}
+
+// AnswerQuery selects the answers listed for coding
+type AnswerQuery struct {
+	CodeID  *uuid.UUID // Only answers tagged with the code
+	Uncoded bool       // Only answers without any code
+	Search  string     // Case-insensitive substring of the text
+	Limit   int
+	Offset  int
+}
+
+// GetAnswers lists the text answers to a question with their codes, oldest first
+func (r *CodingRepository) GetAnswers(questionID uuid.UUID, aq AnswerQuery) ([]models.CodedAnswer, int, error) {
+	where := `
+		a.question_id = $1 AND jsonb_typeof(a.value->'text') = 'string'
+		AND ($2::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM answer_code_assignments ca WHERE ca.answer_id = a.id AND ca.code_id = $2))
+		AND (NOT $3::boolean OR NOT EXISTS (SELECT 1 FROM answer_code_assignments ca WHERE ca.answer_id = a.id))
+		AND ($4::text = '' OR strpos(lower(a.value->>'text'), lower($4::text)) > 0)
+	`
+	args := []interface{}{questionID, aq.CodeID, aq.Uncoded, aq.Search}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM answers a WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count answers: %w", err)
+	}
+
+	query := `
+		SELECT a.id, a.response_id, a.value->>'text', a.created_at,
+			ARRAY(SELECT ca.code_id::text FROM answer_code_assignments ca WHERE ca.answer_id = a.id ORDER BY ca.created_at)
+		FROM answers a
+		WHERE ` + where + `
+		ORDER BY a.created_at, a.id
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.db.Query(query, append(args, aq.Limit, aq.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query answers: %w", err)
+	}
+	defer rows.Close()
+
+	answers := []models.CodedAnswer{}
+	for rows.Next() {
+		var answer models.CodedAnswer
+		var codeIDs []string
+		if err := rows.Scan(&answer.AnswerID, &answer.ResponseID, &answer.Text, &answer.CreatedAt, pq.Array(&codeIDs)); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan answer: %w", err)
+		}
+		answer.CodeIDs = make([]uuid.UUID, 0, len(codeIDs))
+		for _, id := range codeIDs {
+			answer.CodeIDs = append(answer.CodeIDs, uuid.MustParse(id))
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, total, rows.Err()
+}
+
+// GetAnswerTexts returns every text answer to a question by answer ID
+func (r *CodingRepository) GetAnswerTexts(questionID uuid.UUID) ([]uuid.UUID, []string, error) {
+	rows, err := r.db.Query(`
+		SELECT id, value->>'text' FROM answers
+		WHERE question_id = $1 AND jsonb_typeof(value->'text') = 'string' AND btrim(value->>'text') <> ''
+		ORDER BY created_at, id
+	`, questionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query answer texts: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	var texts []string
+	for rows.Next() {
+		var id uuid.UUID
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan answer text: %w", err)
+		}
+		ids = append(ids, id)
+		texts = append(texts, text)
+	}
+
+	return ids, texts, rows.Err()
+}
+
+// Assign tags or untags answers to a question with codes of its codebook.
+// Answers and codes that do not belong to the question are ignored; the
+// number of answers matched is returned.
+func (r *CodingRepository) Assign(questionID uuid.UUID, assignment models.CodeAssignment, userID uuid.UUID) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	answerIDs := uuidStrings(assignment.AnswerIDs)
+	codeIDs := uuidStrings(assignment.CodeIDs)
+
+	var matched int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM answers WHERE question_id = $1 AND id = ANY($2::uuid[])",
+		questionID, pq.Array(answerIDs),
+	).Scan(&matched)
+	if err != nil {
+		return 0, fmt.Errorf("failed to match answers: %w", err)
+	}
+
+	if assignment.Mode == models.CodeAssignRemove || assignment.Mode == models.CodeAssignSet {
+		// Set keeps the requested codes and removes the rest
+		_, err = tx.Exec(`
+			DELETE FROM answer_code_assignments ca
+			USING answers a
+			WHERE a.id = ca.answer_id AND a.question_id = $1 AND a.id = ANY($2::uuid[])
+				AND (ca.code_id = ANY($3::uuid[])) = ($4::text = 'remove')
+		`, questionID, pq.Array(answerIDs), pq.Array(codeIDs), assignment.Mode)
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove answer codes: %w", err)
+		}
+	}
+
+	if assignment.Mode != models.CodeAssignRemove {
+		_, err = tx.Exec(`
+			INSERT INTO answer_code_assignments (answer_id, code_id, assigned_by)
+			SELECT a.id, c.id, $4::uuid
+			FROM answers a
+			JOIN answer_codes c ON c.question_id = a.question_id
+			WHERE a.question_id = $1 AND a.id = ANY($2::uuid[]) AND c.id = ANY($3::uuid[])
+			ON CONFLICT (answer_id, code_id) DO NOTHING
+		`, questionID, pq.Array(answerIDs), pq.Array(codeIDs), userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add answer codes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit answer codes: %w", err)
+	}
+	return matched, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrInvitationInvalid is returned when an invitation token is unknown, expired or already used
+var ErrInvitationInvalid = errors.New("invitation is invalid or has expired")
+
+// ErrInvitationEmailMismatch is returned when an invitation is accepted by a
+// user whose email is not the one it was sent to
+var ErrInvitationEmailMismatch = errors.New("invitation was sent to another email address")
+
+// CollaboratorRepository handles survey collaborator and invitation database operations
+type CollaboratorRepository struct {
+	db *sql.DB
+}
+
+// NewCollaboratorRepository creates a new CollaboratorRepository
+func NewCollaboratorRepository(db *sql.DB) *CollaboratorRepository {
+	return &CollaboratorRepository{db: db}
+}
+
+// GetBySurveyID retrieves the collaborators of a survey
+func (r *CollaboratorRepository) GetBySurveyID(surveyID uuid.UUID) ([]models.SurveyCollaborator, error) {
+	query := `
+		SELECT sc.survey_id, sc.user_id, sc.role, u.email, u.display_name, sc.invited_by, sc.created_at
+		FROM survey_collaborators sc
+		JOIN users u ON u.id = sc.user_id
+		WHERE sc.survey_id = $1
+		ORDER BY sc.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collaborators: %w", err)
+	}
+	defer rows.Close()
+
+	var collaborators []models.SurveyCollaborator
+	for rows.Next() {
+		var sc models.SurveyCollaborator
+		err := rows.Scan(&sc.SurveyID, &sc.UserID, &sc.Role, &sc.Email, &sc.DisplayName, &sc.InvitedBy, &sc.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collaborator: %w", err)
+		}
+		collaborators = append(collaborators, sc)
+	}
+
+	return collaborators, nil
+}
+
+// GetRole returns a collaborator's role on a survey ("" if not a collaborator)
+func (r *CollaboratorRepository) GetRole(surveyID, userID uuid.UUID) (string, error) {
+	var role string
+	err := r.db.QueryRow(
+		"SELECT role FROM survey_collaborators WHERE survey_id = $1 AND user_id = $2",
+		surveyID, userID,
+	).Scan(&role)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get collaborator role: %w", err)
+	}
+
+	return role, nil
+}
+
+// SetRole changes a collaborator's role
+func (r *CollaboratorRepository) SetRole(surveyID, userID uuid.UUID, role string) error {
+	_, err := r.db.Exec(
+		"UPDATE survey_collaborators SET role = $3 WHERE survey_id = $1 AND user_id = $2",
+		surveyID, userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update collaborator: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a collaborator from a survey
+func (r *CollaboratorRepository) Remove(surveyID, userID uuid.UUID) error {
+	_, err := r.db.Exec(
+		"DELETE FROM survey_collaborators WHERE survey_id = $1 AND user_id = $2",
+		surveyID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove collaborator: %w", err)
+	}
+	return nil
+}
+
+// CreateInvitation stores an invitation; only the hash of its token is kept
+func (r *CollaboratorRepository) CreateInvitation(invitation *models.SurveyInvitation, token string) error {
+	query := `
+		INSERT INTO survey_invitations (id, survey_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		invitation.ID, invitation.SurveyID, invitation.Email, invitation.Role,
+		hashToken(token), invitation.InvitedBy, invitation.ExpiresAt,
+	).Scan(&invitation.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingInvitations retrieves the unaccepted, unexpired invitations of a survey
+func (r *CollaboratorRepository) GetPendingInvitations(surveyID uuid.UUID) ([]models.SurveyInvitation, error) {
+	query := `
+		SELECT id, survey_id, email, role, invited_by, expires_at, accepted_at, accepted_by, created_at
+		FROM survey_invitations
+		WHERE survey_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []models.SurveyInvitation
+	for rows.Next() {
+		var inv models.SurveyInvitation
+		err := rows.Scan(
+			&inv.ID, &inv.SurveyID, &inv.Email, &inv.Role, &inv.InvitedBy,
+			&inv.ExpiresAt, &inv.AcceptedAt, &inv.AcceptedBy, &inv.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	return invitations, nil
+}
+
+// DeleteInvitation revokes an invitation of a survey, reporting whether it existed
+func (r *CollaboratorRepository) DeleteInvitation(id, surveyID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(
+		"DELETE FROM survey_invitations WHERE id = $1 AND survey_id = $2",
+		id, surveyID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// AcceptInvitation redeems an invitation token for a user, adding them as a
+// collaborator. An existing collaborator keeps the higher of the two roles.
+// The user's email must be the invited one, ignoring case.
+func (r *CollaboratorRepository) AcceptInvitation(token string, userID uuid.UUID) (*models.SurveyCollaborator, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var inv models.SurveyInvitation
+	var emailMatches bool
+	err = tx.QueryRow(`
+		SELECT i.id, i.survey_id, i.role, i.invited_by, i.expires_at, i.accepted_at,
+			COALESCE(lower(u.email) = lower(i.email), false)
+		FROM survey_invitations i
+		LEFT JOIN users u ON u.id = $2
+		WHERE i.token_hash = $1
+		FOR UPDATE OF i
+	`, hashToken(token), userID).Scan(&inv.ID, &inv.SurveyID, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &emailMatches)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if inv.AcceptedAt != nil || !inv.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvitationInvalid
+	}
+	if !emailMatches {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	collaborator := &models.SurveyCollaborator{SurveyID: inv.SurveyID, UserID: userID, InvitedBy: inv.InvitedBy}
+	err = tx.QueryRow(`
+		INSERT INTO survey_collaborators (survey_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (survey_id, user_id) DO UPDATE SET role = CASE
+			WHEN array_position(ARRAY['editor', 'analyst', 'viewer'], EXCLUDED.role::text)
+				< array_position(ARRAY['editor', 'analyst', 'viewer'], survey_collaborators.role::text)
+			THEN EXCLUDED.role ELSE survey_collaborators.role END
+		RETURNING role, invited_by, created_at
+	`, inv.SurveyID, userID, inv.Role, inv.InvitedBy).Scan(&collaborator.Role, &collaborator.InvitedBy, &collaborator.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add collaborator: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE survey_invitations SET accepted_at = NOW(), accepted_by = $2 WHERE id = $1",
+		inv.ID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return collaborator, nil
+}
@@ -240,3 +240,22 @@ func (r *DatasetRepository) GetBySurveyID(surveyID uuid.UUID) (*models.Dataset,
 
 	return dataset, nil
 }
+
+// SetActive activates or deactivates a dataset, recording the audit entry in
+// the same transaction
+func (r *DatasetRepository) SetActive(id uuid.UUID, isActive bool, entry *models.AuditLog) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE datasets SET is_active = $2 WHERE id = $1", id, isActive); err != nil {
+		return fmt.Errorf("failed to update dataset status: %w", err)
+	}
+	if err := insertAuditLog(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrExportJobLost is returned when a worker updates an export job that was
+// since given to another worker, after its heartbeat went stale
+var ErrExportJobLost = errors.New("export job was claimed by another worker")
+
+// ExportJobRepository handles background export job database operations
+type ExportJobRepository struct {
+	db *sql.DB
+}
+
+// NewExportJobRepository creates a new ExportJobRepository
+func NewExportJobRepository(db *sql.DB) *ExportJobRepository {
+	return &ExportJobRepository{db: db}
+}
+
+const exportJobColumns = `
+	id, survey_id, requested_by, format, filter, status, total_rows, rows_written, attempts,
+	storage_key, file_name, file_size, error, created_at, started_at, heartbeat_at, completed_at, expires_at
+`
+
+// Create queues an export job
+func (r *ExportJobRepository) Create(job *models.ExportJob) error {
+	filterJSON, err := json.Marshal(job.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode export filter: %w", err)
+	}
+
+	query := `
+		INSERT INTO export_jobs (id, survey_id, requested_by, format, filter)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING status, created_at
+	`
+
+	err = r.db.QueryRow(query, job.ID, job.SurveyID, job.RequestedBy, job.Format, filterJSON).Scan(&job.Status, &job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an export job, or nil if it does not exist
+func (r *ExportJobRepository) GetByID(id uuid.UUID) (*models.ExportJob, error) {
+	row := r.db.QueryRow(`SELECT `+exportJobColumns+` FROM export_jobs WHERE id = $1`, id)
+	job, err := scanExportJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	return job, nil
+}
+
+// ListBySurvey returns a survey's most recent export jobs, newest first
+func (r *ExportJobRepository) ListBySurvey(surveyID uuid.UUID, limit int) ([]models.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE survey_id = $1 ORDER BY created_at DESC LIMIT $2`
+	rows, err := r.db.Query(query, surveyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.ExportJob{}
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim starts the oldest queued export job, or a running one whose worker
+// has not sent a heartbeat since staleBefore, and returns it with its
+// attempt number increased; it returns nil if there is none. Jobs are
+// claimed with SKIP LOCKED, so several API instances can run workers.
+func (r *ExportJobRepository) Claim(staleBefore time.Time, maxAttempts int) (*models.ExportJob, error) {
+	query := `
+		UPDATE export_jobs
+		SET status = 'running', attempts = attempts + 1, started_at = NOW(), heartbeat_at = NOW(),
+			rows_written = 0, error = NULL
+		WHERE id = (
+			SELECT id FROM export_jobs
+			WHERE (status = 'queued' OR (status = 'running' AND heartbeat_at < $1))
+				AND attempts < $2
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportJobColumns
+
+	job, err := scanExportJob(r.db.QueryRow(query, staleBefore, maxAttempts))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	return job, nil
+}
+
+// FailAbandoned marks running jobs as failed once their worker has gone
+// silent on the last allowed attempt, and returns how many it marked
+func (r *ExportJobRepository) FailAbandoned(staleBefore time.Time, maxAttempts int) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE export_jobs
+		SET status = 'failed', error = 'The export stopped unexpectedly', completed_at = NOW()
+		WHERE status = 'running' AND heartbeat_at < $1 AND attempts >= $2
+	`, staleBefore, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned export jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SetTotal records the number of responses a running job exports
+func (r *ExportJobRepository) SetTotal(job *models.ExportJob, total int) error {
+	return r.updateRunning(job, "total_rows = $3, heartbeat_at = NOW()", total)
+}
+
+// Progress records the rows a running job has read and refreshes its heartbeat
+func (r *ExportJobRepository) Progress(job *models.ExportJob, rowsWritten int) error {
+	return r.updateRunning(job, "rows_written = $3, heartbeat_at = NOW()", rowsWritten)
+}
+
+// Heartbeat refreshes the heartbeat of a running job, so it is not taken
+// for abandoned while it writes its file
+func (r *ExportJobRepository) Heartbeat(job *models.ExportJob) error {
+	return r.updateRunning(job, "heartbeat_at = NOW()")
+}
+
+// Complete marks a running job as done, with the file it wrote
+func (r *ExportJobRepository) Complete(job *models.ExportJob, storageKey, fileName string, fileSize int64, expiresAt time.Time) error {
+	return r.updateRunning(job,
+		"status = 'completed', storage_key = $3, file_name = $4, file_size = $5, expires_at = $6, completed_at = NOW()",
+		storageKey, fileName, fileSize, expiresAt)
+}
+
+// Fail marks a running job as failed with a message for the user
+func (r *ExportJobRepository) Fail(job *models.ExportJob, message string) error {
+	return r.updateRunning(job, "status = 'failed', error = $3, completed_at = NOW()", message)
+}
+
+// updateRunning updates a job the worker still holds: it is running, and
+// on the attempt the worker claimed
+func (r *ExportJobRepository) updateRunning(job *models.ExportJob, set string, args ...interface{}) error {
+	query := `UPDATE export_jobs SET ` + set + ` WHERE id = $1 AND attempts = $2 AND status = 'running'`
+	result, err := r.db.Exec(query, append([]interface{}{job.ID, job.Attempts}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrExportJobLost
+	}
+	return nil
+}
+
+// ListExpired returns completed jobs whose files are past their retention
+func (r *ExportJobRepository) ListExpired(now time.Time, limit int) ([]models.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE status = 'completed' AND expires_at <= $1 ORDER BY expires_at LIMIT $2`
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ExportJob
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// MarkExpired records that a job's file was deleted
+func (r *ExportJobRepository) MarkExpired(id uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE export_jobs SET status = 'expired', storage_key = NULL WHERE id = $1 AND status = 'completed'`, id)
+	if err != nil {
+		return fmt.Errorf("failed to expire export job: %w", err)
+	}
+	return nil
+}
+
+func scanExportJob(row rowScanner) (*models.ExportJob, error) {
+	var job models.ExportJob
+	var requestedBy uuid.NullUUID
+	var filterJSON []byte
+	var totalRows sql.NullInt64
+	var storageKey, fileName, errorMessage sql.NullString
+	var fileSize sql.NullInt64
+
+	err := row.Scan(
+		&job.ID, &job.SurveyID, &requestedBy, &job.Format, &filterJSON, &job.Status, &totalRows, &job.RowsWritten, &job.Attempts,
+		&storageKey, &fileName, &fileSize, &errorMessage, &job.CreatedAt, &job.StartedAt, &job.HeartbeatAt, &job.CompletedAt, &job.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filterJSON, &job.Filter); err != nil {
+		return nil, fmt.Errorf("failed to decode export filter: %w", err)
+	}
+	if requestedBy.Valid {
+		job.RequestedBy = &requestedBy.UUID
+	}
+	if totalRows.Valid {
+		total := int(totalRows.Int64)
+		job.TotalRows = &total
+	}
+	if storageKey.Valid {
+		job.StorageKey = &storageKey.String
+	}
+	if fileName.Valid {
+		job.FileName = &fileName.String
+	}
+	if fileSize.Valid {
+		job.FileSize = &fileSize.Int64
+	}
+	if errorMessage.Valid {
+		job.Error = &errorMessage.String
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// InsightRepository handles cached survey summary database operations
+type InsightRepository struct {
+	db *sql.DB
+}
+
+// NewInsightRepository creates a new InsightRepository
+func NewInsightRepository(db *sql.DB) *InsightRepository {
+	return &InsightRepository{db: db}
+}
+
+// Get retrieves the summary of a survey revision by a model, or nil if there is none
+func (r *InsightRepository) Get(surveyID uuid.UUID, revision int, model string) (*models.SurveyInsight, error) {
+	query := `
+		SELECT survey_id, revision, model, responses, summary, created_at
+		FROM survey_insights
+		WHERE survey_id = $1 AND revision = $2 AND model = $3
+	`
+
+	var insight models.SurveyInsight
+	err := r.db.QueryRow(query, surveyID, revision, model).Scan(
+		&insight.SurveyID, &insight.Revision, &insight.Model,
+		&insight.Responses, &insight.Summary, &insight.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survey insight: %w", err)
+	}
+
+	return &insight, nil
+}
+
+// Save stores a summary, replacing the one of the same revision and model
+func (r *InsightRepository) Save(insight *models.SurveyInsight) error {
+	query := `
+		INSERT INTO survey_insights (survey_id, revision, model, responses, summary)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (survey_id, revision, model) DO UPDATE
+		SET responses = EXCLUDED.responses, summary = EXCLUDED.summary, created_at = NOW()
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		insight.SurveyID, insight.Revision, insight.Model, insight.Responses, insight.Summary,
+	).Scan(&insight.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save survey insight: %w", err)
+	}
+
+	return nil
+}
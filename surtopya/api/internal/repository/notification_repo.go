@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// NotificationRepository handles notification database operations
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create creates a new notification
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, type, title, message, survey_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		notification.ID, notification.UserID, notification.Type,
+		notification.Title, notification.Message, notification.SurveyID,
+	).Scan(&notification.ID, &notification.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves the notifications of a user, newest first
+func (r *NotificationRepository) GetByUserID(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, type, title, message, survey_id, is_read, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR is_read = false)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message,
+			&n.SurveyID, &n.IsRead, &n.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks a user's notification as read, reporting whether it existed
+func (r *NotificationRepository) MarkRead(id, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE notifications SET is_read = true WHERE id = $1 AND user_id = $2",
+		id, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	return affected > 0, nil
+}
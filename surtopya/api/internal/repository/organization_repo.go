@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// OrganizationRepository handles organization database operations
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository creates a new OrganizationRepository
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create creates a new organization with its creator as the first owner
+func (r *OrganizationRepository) Create(org *models.Organization) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO organizations (id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, org.ID, org.Name, org.CreatedBy).Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	if org.CreatedBy != nil {
+		_, err = tx.Exec(`
+			INSERT INTO organization_members (organization_id, user_id, role)
+			VALUES ($1, $2, $3)
+		`, org.ID, *org.CreatedBy, models.AccessRoleOwner)
+		if err != nil {
+			return fmt.Errorf("failed to add organization owner: %w", err)
+		}
+		org.Role = models.AccessRoleOwner
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves an organization by ID
+func (r *OrganizationRepository) GetByID(id uuid.UUID) (*models.Organization, error) {
+	org := &models.Organization{}
+	err := r.db.QueryRow(`
+		SELECT id, name, created_by, created_at, updated_at
+		FROM organizations WHERE id = $1
+	`, id).Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+// GetByUserID retrieves the organizations a user belongs to, with their role
+func (r *OrganizationRepository) GetByUserID(userID uuid.UUID) ([]models.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.created_by, o.created_at, o.updated_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []models.Organization
+	for rows.Next() {
+		var org models.Organization
+		err := rows.Scan(&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt, &org.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, nil
+}
+
+// Update updates an organization's name
+func (r *OrganizationRepository) Update(org *models.Organization) error {
+	_, err := r.db.Exec("UPDATE organizations SET name = $2 WHERE id = $1", org.ID, org.Name)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes an organization; its surveys become personal to their creators
+func (r *OrganizationRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM organizations WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+// GetMemberRole returns a user's role in an organization ("" if not a member)
+func (r *OrganizationRepository) GetMemberRole(orgID, userID uuid.UUID) (string, error) {
+	var role string
+	err := r.db.QueryRow(
+		"SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2",
+		orgID, userID,
+	).Scan(&role)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get member role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetMembers retrieves the members of an organization
+func (r *OrganizationRepository) GetMembers(orgID uuid.UUID) ([]models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.user_id, m.role, u.email, u.display_name, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.OrganizationMember
+	for rows.Next() {
+		var m models.OrganizationMember
+		err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Role, &m.Email, &m.DisplayName, &m.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// SetMember adds a member to an organization or changes their role
+func (r *OrganizationRepository) SetMember(orgID, userID uuid.UUID, role string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`, orgID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to set member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a member from an organization
+func (r *OrganizationRepository) RemoveMember(orgID, userID uuid.UUID) error {
+	_, err := r.db.Exec(
+		"DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2",
+		orgID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// CountOwners counts the owners of an organization
+func (r *OrganizationRepository) CountOwners(orgID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2",
+		orgID, models.AccessRoleOwner,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count owners: %w", err)
+	}
+	return count, nil
+}
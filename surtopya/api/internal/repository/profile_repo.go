@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// ProfileRepository handles respondent profile database operations
+type ProfileRepository struct {
+	db *sql.DB
+}
+
+// NewProfileRepository creates a new ProfileRepository
+func NewProfileRepository(db *sql.DB) *ProfileRepository {
+	return &ProfileRepository{db: db}
+}
+
+// GetByUserID retrieves the respondent profile of a user
+func (r *ProfileRepository) GetByUserID(userID uuid.UUID) (*models.RespondentProfile, error) {
+	profile := &models.RespondentProfile{}
+	var interestsJSON []byte
+
+	query := `
+		SELECT user_id, birth_year, gender, region, interests, consented,
+			consented_at, created_at, updated_at
+		FROM respondent_profiles WHERE user_id = $1
+	`
+
+	err := r.db.QueryRow(query, userID).Scan(
+		&profile.UserID, &profile.BirthYear, &profile.Gender, &profile.Region,
+		&interestsJSON, &profile.Consented, &profile.ConsentedAt,
+		&profile.CreatedAt, &profile.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	if len(interestsJSON) > 0 {
+		json.Unmarshal(interestsJSON, &profile.Interests)
+	}
+
+	return profile, nil
+}
+
+// Upsert creates or replaces the respondent profile of a user
+func (r *ProfileRepository) Upsert(profile *models.RespondentProfile) error {
+	interests := profile.Interests
+	if interests == nil {
+		interests = []string{}
+	}
+	interestsJSON, err := json.Marshal(interests)
+	if err != nil {
+		return fmt.Errorf("failed to marshal interests: %w", err)
+	}
+
+	query := `
+		INSERT INTO respondent_profiles (
+			user_id, birth_year, gender, region, interests, consented, consented_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			birth_year = $2, gender = $3, region = $4, interests = $5,
+			consented = $6, consented_at = $7
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRow(
+		query,
+		profile.UserID, profile.BirthYear, profile.Gender, profile.Region,
+		interestsJSON, profile.Consented, profile.ConsentedAt,
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the respondent profile of a user (consent withdrawal)
+func (r *ProfileRepository) Delete(userID uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM respondent_profiles WHERE user_id = $1", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	return nil
+}
+
+// MatchesAudience reports whether a user's consented profile matches a target audience
+func (r *ProfileRepository) MatchesAudience(userID uuid.UUID, audience *models.TargetAudience) (bool, error) {
+	if audience == nil {
+		return true, nil
+	}
+
+	audienceJSON, err := marshalTargetAudience(audience)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users u
+			LEFT JOIN respondent_profiles p ON p.user_id = u.id AND p.consented = true
+			WHERE u.id = $1 AND ` + audienceMatch("$2::jsonb", "p") + `
+		)
+	`
+
+	var matches bool
+	if err := r.db.QueryRow(query, userID, audienceJSON).Scan(&matches); err != nil {
+		return false, fmt.Errorf("failed to match audience: %w", err)
+	}
+
+	return matches, nil
+}
+
+// EstimateReach counts the users whose consented profile matches a target audience
+func (r *ProfileRepository) EstimateReach(audience *models.TargetAudience) (int, error) {
+	audienceJSON, err := marshalTargetAudience(audience)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		SELECT COUNT(*) FROM users u
+		LEFT JOIN respondent_profiles p ON p.user_id = u.id AND p.consented = true
+		WHERE $1::jsonb IS NULL OR ` + audienceMatch("$1::jsonb", "p") + `
+	`
+
+	var reach int
+	if err := r.db.QueryRow(query, audienceJSON).Scan(&reach); err != nil {
+		return 0, fmt.Errorf("failed to estimate reach: %w", err)
+	}
+
+	return reach, nil
+}
+
+// audienceMatch returns a SQL condition that is true when the profile row
+// (table alias p) matches the target audience (a JSONB expression).
+// Missing profile attributes never match a restricting criterion.
+func audienceMatch(audience, p string) string {
+	return fmt.Sprintf(`(
+		(%[1]s->>'minAge' IS NULL OR EXTRACT(YEAR FROM NOW())::int - %[2]s.birth_year >= (%[1]s->>'minAge')::int)
+		AND (%[1]s->>'maxAge' IS NULL OR EXTRACT(YEAR FROM NOW())::int - %[2]s.birth_year <= (%[1]s->>'maxAge')::int)
+		AND (COALESCE(jsonb_array_length(%[1]s->'genders'), 0) = 0 OR %[1]s->'genders' ? %[2]s.gender)
+		AND (COALESCE(jsonb_array_length(%[1]s->'regions'), 0) = 0 OR %[1]s->'regions' ? %[2]s.region)
+		AND (COALESCE(jsonb_array_length(%[1]s->'interests'), 0) = 0
+			OR %[1]s->'interests' ?| ARRAY(SELECT jsonb_array_elements_text(COALESCE(%[2]s.interests, '[]'::jsonb))))
+	)`, audience, p)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrQuestionOrderMismatch is returned when a reorder does not list every question of the survey exactly once
+var ErrQuestionOrderMismatch = errors.New("question order must list every question of the survey exactly once")
+
+// QuestionRepository handles edits to individual questions. Every edit runs in
+// a transaction that also advances the survey revision, so question edits take
+// part in the same optimistic concurrency as survey updates.
+type QuestionRepository struct {
+	db *sql.DB
+}
+
+// NewQuestionRepository creates a new QuestionRepository
+func NewQuestionRepository(db *sql.DB) *QuestionRepository {
+	return &QuestionRepository{db: db}
+}
+
+// GetByID retrieves a question of a survey
+func (r *QuestionRepository) GetByID(surveyID, id uuid.UUID) (*models.Question, error) {
+	query := `SELECT ` + questionColumns + ` FROM questions WHERE id = $1 AND survey_id = $2`
+
+	q, err := scanQuestion(r.db.QueryRow(query, id, surveyID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	return q, nil
+}
+
+// CountAnswers counts the answers given to a question
+func (r *QuestionRepository) CountAnswers(id uuid.UUID) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM answers WHERE question_id = $1", id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count answers: %w", err)
+	}
+	return count, nil
+}
+
+// Insert adds a question at a position (clamped to the end of the list),
+// shifting the following questions down. It returns the new survey revision.
+func (r *QuestionRepository) Insert(q *models.Question, position int, expectedRevision int) (int, error) {
+	return r.edit(q.SurveyID, expectedRevision, func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error) {
+		position = clampPosition(position, len(order))
+		q.SortOrder = position
+		if err := upsertQuestion(tx, q); err != nil {
+			return nil, err
+		}
+		return insertAt(order, q.ID, position), nil
+	})
+}
+
+// Update saves the content of an existing question. It returns the new survey revision.
+func (r *QuestionRepository) Update(q *models.Question, expectedRevision int) (int, error) {
+	return r.edit(q.SurveyID, expectedRevision, func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error) {
+		return order, upsertQuestion(tx, q)
+	})
+}
+
+// Delete removes a question and closes the gap in the order. Logic rules of
+// other questions that jump to it are removed too, so respondents are not
+// routed to a question that no longer exists. It returns the new survey revision.
+func (r *QuestionRepository) Delete(surveyID, id uuid.UUID, expectedRevision int) (int, error) {
+	return r.edit(surveyID, expectedRevision, func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error) {
+		if _, err := tx.Exec("DELETE FROM questions WHERE id = $1 AND survey_id = $2", id, surveyID); err != nil {
+			return nil, fmt.Errorf("failed to delete question: %w", err)
+		}
+		_, err := tx.Exec(`
+			UPDATE questions
+			SET logic = COALESCE((
+				SELECT jsonb_agg(rule ORDER BY n)
+				FROM jsonb_array_elements(logic) WITH ORDINALITY AS rules(rule, n)
+				WHERE rule->>'destinationQuestionId' IS DISTINCT FROM $2
+			), '[]')
+			WHERE survey_id = $1 AND jsonb_typeof(logic) = 'array'
+				AND logic @> jsonb_build_array(jsonb_build_object('destinationQuestionId', $2::text))
+		`, surveyID, id.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove logic to deleted question: %w", err)
+		}
+		return removeID(order, id), nil
+	})
+}
+
+// Move moves a question to a position (clamped to the list). It returns the new survey revision.
+func (r *QuestionRepository) Move(surveyID, id uuid.UUID, position int, expectedRevision int) (int, error) {
+	return r.edit(surveyID, expectedRevision, func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error) {
+		order = removeID(order, id)
+		return insertAt(order, id, clampPosition(position, len(order))), nil
+	})
+}
+
+// Reorder sets the order of all questions of a survey. It returns the new survey revision.
+func (r *QuestionRepository) Reorder(surveyID uuid.UUID, ids []uuid.UUID, expectedRevision int) (int, error) {
+	return r.edit(surveyID, expectedRevision, func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error) {
+		if len(ids) != len(order) {
+			return nil, ErrQuestionOrderMismatch
+		}
+		existing := make(map[uuid.UUID]bool, len(order))
+		for _, id := range order {
+			existing[id] = true
+		}
+		for _, id := range ids {
+			if !existing[id] {
+				return nil, ErrQuestionOrderMismatch
+			}
+			delete(existing, id)
+		}
+		return ids, nil
+	})
+}
+
+// edit runs a question edit in a transaction: it advances the survey revision
+// (failing with ErrRevisionConflict if the survey is no longer at
+// expectedRevision), locks the questions, applies the change and renumbers
+// sort_order from the order the change returns.
+func (r *QuestionRepository) edit(surveyID uuid.UUID, expectedRevision int, change func(tx *sql.Tx, order []uuid.UUID) ([]uuid.UUID, error)) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var revision int
+	err = tx.QueryRow(`
+		UPDATE surveys SET revision = revision + 1
+		WHERE id = $1 AND revision = $2
+		RETURNING revision
+	`, surveyID, expectedRevision).Scan(&revision)
+	if err == sql.ErrNoRows {
+		return 0, ErrRevisionConflict
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update survey revision: %w", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT id FROM questions WHERE survey_id = $1
+		ORDER BY sort_order ASC, created_at ASC
+		FOR UPDATE
+	`, surveyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock questions: %w", err)
+	}
+	var order []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan question: %w", err)
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+
+	order, err = change(tx, order)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range order {
+		_, err := tx.Exec(
+			"UPDATE questions SET sort_order = $3 WHERE id = $1 AND survey_id = $2 AND sort_order <> $3",
+			id, surveyID, i,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to renumber questions: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return revision, nil
+}
+
+func clampPosition(position, length int) int {
+	if position < 0 || position > length {
+		return length
+	}
+	return position
+}
+
+func insertAt(order []uuid.UUID, id uuid.UUID, position int) []uuid.UUID {
+	order = append(order, uuid.Nil)
+	copy(order[position+1:], order[position:])
+	order[position] = id
+	return order
+}
+
+func removeID(order []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	for i, existing := range order {
+		if existing == id {
+			return append(order[:i], order[i+1:]...)
+		}
+	}
+	return order
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// QuotaRepository handles survey quota database operations
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+// NewQuotaRepository creates a new QuotaRepository
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// Create creates a new quota
+func (r *QuotaRepository) Create(quota *models.SurveyQuota) error {
+	return insertQuota(r.db, quota)
+}
+
+// insertQuota inserts a quota
+func insertQuota(db queryRower, quota *models.SurveyQuota) error {
+	query := `
+		INSERT INTO survey_quotas (
+			id, survey_id, name, question_id, option_value, target,
+			screen_out_message, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, current_count, created_at, updated_at
+	`
+
+	err := db.QueryRow(
+		query,
+		quota.ID, quota.SurveyID, quota.Name, quota.QuestionID, quota.OptionValue,
+		quota.Target, quota.ScreenOutMessage, quota.IsActive,
+	).Scan(&quota.ID, &quota.CurrentCount, &quota.CreatedAt, &quota.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create quota: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a quota by ID
+func (r *QuotaRepository) GetByID(id uuid.UUID) (*models.SurveyQuota, error) {
+	quota := &models.SurveyQuota{}
+
+	query := `
+		SELECT id, survey_id, name, question_id, option_value, target,
+			current_count, screen_out_message, is_active, created_at, updated_at
+		FROM survey_quotas WHERE id = $1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&quota.ID, &quota.SurveyID, &quota.Name, &quota.QuestionID,
+		&quota.OptionValue, &quota.Target, &quota.CurrentCount,
+		&quota.ScreenOutMessage, &quota.IsActive, &quota.CreatedAt, &quota.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	return quota, nil
+}
+
+// GetBySurveyID retrieves all quotas for a survey
+func (r *QuotaRepository) GetBySurveyID(surveyID uuid.UUID) ([]models.SurveyQuota, error) {
+	query := `
+		SELECT id, survey_id, name, question_id, option_value, target,
+			current_count, screen_out_message, is_active, created_at, updated_at
+		FROM survey_quotas WHERE survey_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quotas: %w", err)
+	}
+	defer rows.Close()
+
+	var quotas []models.SurveyQuota
+	for rows.Next() {
+		var quota models.SurveyQuota
+		err := rows.Scan(
+			&quota.ID, &quota.SurveyID, &quota.Name, &quota.QuestionID,
+			&quota.OptionValue, &quota.Target, &quota.CurrentCount,
+			&quota.ScreenOutMessage, &quota.IsActive, &quota.CreatedAt, &quota.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quota: %w", err)
+		}
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, nil
+}
+
+// Update updates a quota
+func (r *QuotaRepository) Update(quota *models.SurveyQuota) error {
+	query := `
+		UPDATE survey_quotas SET
+			name = $2, question_id = $3, option_value = $4, target = $5,
+			screen_out_message = $6, is_active = $7
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(
+		query,
+		quota.ID, quota.Name, quota.QuestionID, quota.OptionValue,
+		quota.Target, quota.ScreenOutMessage, quota.IsActive,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update quota: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a quota
+func (r *QuotaRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM survey_quotas WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete quota: %w", err)
+	}
+	return nil
+}
+
+// quotaMatches reports whether a set of answers falls into a quota
+func quotaMatches(quota models.SurveyQuota, answers []models.Answer) bool {
+	if quota.QuestionID == nil || quota.OptionValue == nil {
+		return true
+	}
+
+	for _, answer := range answers {
+		if answer.QuestionID != *quota.QuestionID {
+			continue
+		}
+		if answer.Value.Value != nil && *answer.Value.Value == *quota.OptionValue {
+			return true
+		}
+		for _, v := range answer.Value.Values {
+			if v == *quota.OptionValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,1333 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/TimLai666/surtopya-api/internal/textcluster"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ReportRepository computes survey result aggregates over the answers table
+type ReportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new ReportRepository
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// ReportOptions tunes the aggregates of a survey report
+type ReportOptions struct {
+	DateInterval string  // day, week, month or year
+	TopWords     int     // Words listed per text question
+	Confidence   float64 // Level of the confidence intervals, such as 0.95
+}
+
+// reportQuery builds the condition selecting the responses (alias r) that
+// match a report filter, numbering placeholders as arguments are added.
+// Responses are dated by completion, or by start while not completed.
+type reportQuery struct {
+	where string
+	args  []interface{}
+}
+
+func newReportQuery(surveyID uuid.UUID, filter models.ReportFilter) *reportQuery {
+	q := &reportQuery{}
+	conditions := []string{"r.survey_id = " + q.arg(surveyID)}
+	if filter.From != nil {
+		conditions = append(conditions, "COALESCE(r.completed_at, r.started_at) >= "+q.arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "COALESCE(r.completed_at, r.started_at) < "+q.arg(*filter.To))
+	}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "r.status = ANY("+q.arg(pq.Array(filter.Statuses))+"::text[])")
+	}
+	for _, condition := range filter.Segment {
+		conditions = append(conditions, q.segmentCondition(condition))
+	}
+	q.where = strings.Join(conditions, " AND ")
+	return q
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *reportQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// segmentCondition matches responses by their answer to a question
+func (q *reportQuery) segmentCondition(c models.SegmentCondition) string {
+	answer := "SELECT 1 FROM answers sa WHERE sa.response_id = r.id AND sa.question_id = " + q.arg(c.QuestionID)
+
+	switch c.Operator {
+	case models.SegmentAnswered:
+		return "EXISTS (" + answer + ")"
+	case models.SegmentNotAnswered:
+		return "NOT EXISTS (" + answer + ")"
+	case models.SegmentBetween:
+		answer += " AND jsonb_typeof(sa.value->'rating') = 'number'"
+		if c.Min != nil {
+			answer += " AND (sa.value->>'rating')::numeric >= " + q.arg(*c.Min)
+		}
+		if c.Max != nil {
+			answer += " AND (sa.value->>'rating')::numeric <= " + q.arg(*c.Max)
+		}
+		return "EXISTS (" + answer + ")"
+	}
+
+	matches := answer + " AND EXISTS (SELECT 1 FROM " + answerValues("sa") + " AS sv(v) WHERE sv.v = ANY(" + q.arg(pq.Array(c.Values)) + "::text[]))"
+	if c.Operator == models.SegmentNotIn {
+		return "NOT EXISTS (" + matches + ")"
+	}
+	return "EXISTS (" + matches + ")"
+}
+
+// answerValues is a subquery listing the values of the answer with the given
+// alias as text: the chosen options, or the rating, date or text
+func answerValues(alias string) string {
+	return strings.ReplaceAll(`(
+		SELECT ANSWER.value->>'value' WHERE jsonb_typeof(ANSWER.value->'value') = 'string'
+		UNION ALL
+		SELECT jsonb_array_elements_text(ANSWER.value->'values') WHERE jsonb_typeof(ANSWER.value->'values') = 'array'
+		UNION ALL
+		SELECT ANSWER.value->>'rating' WHERE jsonb_typeof(ANSWER.value->'rating') = 'number'
+		UNION ALL
+		SELECT ANSWER.value->>'date' WHERE jsonb_typeof(ANSWER.value->'date') = 'string'
+		UNION ALL
+		SELECT ANSWER.value->>'text' WHERE jsonb_typeof(ANSWER.value->'text') = 'string'
+	)`, "ANSWER", alias)
+}
+
+// GetReport aggregates the answers to each question of a survey over the
+// responses matching the filter
+func (r *ReportRepository) GetReport(survey *models.Survey, filter models.ReportFilter, opts ReportOptions) (*models.SurveyReport, error) {
+	report := &models.SurveyReport{
+		SurveyID:    survey.ID,
+		Filter:      filter,
+		Confidence:  opts.Confidence,
+		Questions:   []models.QuestionReport{},
+		GeneratedAt: time.Now(),
+	}
+	if report.Filter.Statuses == nil {
+		report.Filter.Statuses = []string{}
+	}
+
+	q := newReportQuery(survey.ID, filter)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM responses r WHERE `+q.where, q.args...).Scan(&report.Responses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count report responses: %w", err)
+	}
+
+	answered, err := r.answeredCounts(survey.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var choiceIDs, ratingIDs, dateIDs, textIDs []string
+	for _, q := range survey.Questions {
+		switch q.Type {
+		case "single", "multi", "select":
+			choiceIDs = append(choiceIDs, q.ID.String())
+		case "rating":
+			ratingIDs = append(ratingIDs, q.ID.String())
+		case "date":
+			dateIDs = append(dateIDs, q.ID.String())
+		case "text", "short", "long":
+			textIDs = append(textIDs, q.ID.String())
+		}
+	}
+
+	choices, err := r.choiceCounts(survey.ID, filter, choiceIDs)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := r.ratingSummaries(survey.ID, filter, ratingIDs)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := r.dateHistograms(survey.ID, filter, dateIDs, opts.DateInterval)
+	if err != nil {
+		return nil, err
+	}
+	words, err := r.wordCounts(survey.ID, filter, textIDs, opts.TopWords)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := r.codeCounts(survey.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range survey.Questions {
+		if q.Type == "section" {
+			continue
+		}
+
+		qr := models.QuestionReport{
+			QuestionID: q.ID,
+			Type:       q.Type,
+			Title:      q.Title,
+			Answered:   answered[q.ID],
+			Skipped:    report.Responses - answered[q.ID],
+		}
+		if qr.Skipped < 0 {
+			qr.Skipped = 0
+		}
+
+		switch q.Type {
+		case "single", "multi", "select":
+			qr.Options = optionCounts(q.Options, choices[q.ID], qr.Answered, opts.Confidence)
+		case "rating":
+			qr.Rating = ratingSummary(q, ratings[q.ID], qr.Answered, opts.Confidence)
+		case "date":
+			qr.Dates = dates[q.ID]
+			if qr.Dates == nil {
+				qr.Dates = &models.DateHistogram{Interval: opts.DateInterval, Buckets: []models.DateBucket{}}
+			}
+		case "text", "short", "long":
+			qr.Words = words[q.ID]
+			if qr.Words == nil {
+				qr.Words = []models.WordCount{}
+			}
+			for _, cc := range codes[q.ID] {
+				cc.Percentage = percentage(cc.Count, qr.Answered)
+				qr.Codes = append(qr.Codes, cc)
+			}
+		}
+
+		report.Questions = append(report.Questions, qr)
+	}
+
+	return report, nil
+}
+
+// answeredCounts returns the number of matching responses that answered each question
+func (r *ReportRepository) answeredCounts(surveyID uuid.UUID, filter models.ReportFilter) (map[uuid.UUID]int, error) {
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT a.question_id, COUNT(*)
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		WHERE ` + q.where + `
+		GROUP BY a.question_id
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answered counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan answered count: %w", err)
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
+// choiceCounts counts the chosen options of single, select and multi questions
+func (r *ReportRepository) choiceCounts(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string) (map[uuid.UUID]map[string]int, error) {
+	counts := make(map[uuid.UUID]map[string]int)
+	if len(questionIDs) == 0 {
+		return counts, nil
+	}
+
+	q := newReportQuery(surveyID, filter)
+	ids := q.arg(pq.Array(questionIDs))
+	query := `
+		SELECT a.question_id, o.option, COUNT(*)
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		CROSS JOIN LATERAL (
+			SELECT a.value->>'value' AS option
+			WHERE jsonb_typeof(a.value->'value') = 'string'
+			UNION ALL
+			SELECT jsonb_array_elements_text(a.value->'values')
+			WHERE jsonb_typeof(a.value->'values') = 'array'
+		) o
+		WHERE ` + q.where + ` AND a.question_id = ANY(` + ids + `::uuid[])
+		GROUP BY a.question_id, o.option
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query option counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var option string
+		var count int
+		if err := rows.Scan(&id, &option, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan option count: %w", err)
+		}
+		if counts[id] == nil {
+			counts[id] = make(map[string]int)
+		}
+		counts[id][option] = count
+	}
+	return counts, rows.Err()
+}
+
+// ratingStats are the SQL aggregates of a rating question
+type ratingStats struct {
+	mean, median, stdDev *float64
+	counts               map[int]int
+}
+
+// ratingSummaries computes the mean, median, standard deviation and
+// distribution of rating answers
+func (r *ReportRepository) ratingSummaries(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string) (map[uuid.UUID]*ratingStats, error) {
+	summaries := make(map[uuid.UUID]*ratingStats)
+	if len(questionIDs) == 0 {
+		return summaries, nil
+	}
+
+	q := newReportQuery(surveyID, filter)
+	ids := q.arg(pq.Array(questionIDs))
+	ratings := `
+		SELECT a.question_id, round((a.value->>'rating')::numeric)::int AS rating
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		WHERE ` + q.where + ` AND a.question_id = ANY(` + ids + `::uuid[])
+			AND jsonb_typeof(a.value->'rating') = 'number'
+	`
+
+	query := `
+		WITH ratings AS (` + ratings + `)
+		SELECT question_id,
+			AVG(rating)::float8,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY rating),
+			stddev_samp(rating)::float8
+		FROM ratings
+		GROUP BY question_id
+	`
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating summaries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		s := &ratingStats{counts: make(map[int]int)}
+		if err := rows.Scan(&id, &s.mean, &s.median, &s.stdDev); err != nil {
+			return nil, fmt.Errorf("failed to scan rating summary: %w", err)
+		}
+		summaries[id] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rating summaries: %w", err)
+	}
+
+	query = `
+		WITH ratings AS (` + ratings + `)
+		SELECT question_id, rating, COUNT(*)
+		FROM ratings
+		GROUP BY question_id, rating
+	`
+	rows, err = r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating distribution: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var rating, count int
+		if err := rows.Scan(&id, &rating, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan rating distribution: %w", err)
+		}
+		if s := summaries[id]; s != nil {
+			s.counts[rating] = count
+		}
+	}
+	return summaries, rows.Err()
+}
+
+// dateHistograms counts date answers per interval. Answers that are not
+// valid YYYY-MM-DD dates, such as 2023-02-31, are left out; they are parsed
+// here rather than cast in SQL, where one bad answer would fail the report.
+func (r *ReportRepository) dateHistograms(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string, interval string) (map[uuid.UUID]*models.DateHistogram, error) {
+	histograms := make(map[uuid.UUID]*models.DateHistogram)
+	if len(questionIDs) == 0 {
+		return histograms, nil
+	}
+
+	q := newReportQuery(surveyID, filter)
+	ids := q.arg(pq.Array(questionIDs))
+	query := `
+		SELECT a.question_id, a.value->>'date', COUNT(*)
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		WHERE ` + q.where + ` AND a.question_id = ANY(` + ids + `::uuid[])
+			AND jsonb_typeof(a.value->'date') = 'string'
+		GROUP BY a.question_id, a.value->>'date'
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query date histograms: %w", err)
+	}
+	defer rows.Close()
+
+	type span struct{ earliest, latest time.Time }
+	counts := make(map[uuid.UUID]map[time.Time]int)
+	ranges := make(map[uuid.UUID]*span)
+	for rows.Next() {
+		var id uuid.UUID
+		var value string
+		var count int
+		if err := rows.Scan(&id, &value, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan date histogram: %w", err)
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			continue
+		}
+
+		if counts[id] == nil {
+			counts[id] = make(map[time.Time]int)
+			ranges[id] = &span{earliest: date, latest: date}
+		}
+		counts[id][dateBucket(date, interval)] += count
+		if s := ranges[id]; date.Before(s.earliest) {
+			s.earliest = date
+		} else if date.After(s.latest) {
+			s.latest = date
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read date histograms: %w", err)
+	}
+
+	for id, buckets := range counts {
+		earliest := ranges[id].earliest.Format("2006-01-02")
+		latest := ranges[id].latest.Format("2006-01-02")
+		h := &models.DateHistogram{Interval: interval, Earliest: &earliest, Latest: &latest}
+		starts := make([]time.Time, 0, len(buckets))
+		for start := range buckets {
+			starts = append(starts, start)
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+		for _, start := range starts {
+			h.Buckets = append(h.Buckets, models.DateBucket{Start: start.Format("2006-01-02"), Count: buckets[start]})
+		}
+		histograms[id] = h
+	}
+	return histograms, nil
+}
+
+// dateBucket returns the start of the interval containing date, with weeks
+// starting on Monday as date_trunc does
+func dateBucket(date time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case "month":
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(date.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date
+}
+
+// wordCounts returns the most frequent terms of text answers. Answers are
+// split with textcluster.Tokenize, so Chinese and Japanese text, which has
+// no spaces, is counted by character bigrams rather than as whole answers.
+func (r *ReportRepository) wordCounts(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string, limit int) (map[uuid.UUID][]models.WordCount, error) {
+	words := make(map[uuid.UUID][]models.WordCount)
+	if len(questionIDs) == 0 || limit <= 0 {
+		return words, nil
+	}
+
+	q := newReportQuery(surveyID, filter)
+	ids := q.arg(pq.Array(questionIDs))
+	query := `
+		SELECT a.question_id, a.value->>'text', COUNT(*)
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		WHERE ` + q.where + ` AND a.question_id = ANY(` + ids + `::uuid[])
+			AND jsonb_typeof(a.value->'text') = 'string'
+		GROUP BY a.question_id, a.value->>'text'
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query word counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]map[string]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var text string
+		var count int
+		if err := rows.Scan(&id, &text, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan word count: %w", err)
+		}
+		if counts[id] == nil {
+			counts[id] = make(map[string]int)
+		}
+		for _, word := range textcluster.Tokenize(text) {
+			counts[id][word] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word counts: %w", err)
+	}
+
+	for id, questionCounts := range counts {
+		ranked := make([]models.WordCount, 0, len(questionCounts))
+		for word, count := range questionCounts {
+			ranked = append(ranked, models.WordCount{Word: word, Count: count})
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].Count != ranked[j].Count {
+				return ranked[i].Count > ranked[j].Count
+			}
+			return ranked[i].Word < ranked[j].Word
+		})
+		if len(ranked) > limit {
+			ranked = ranked[:limit]
+		}
+		words[id] = ranked
+	}
+	return words, nil
+}
+
+// codeCounts returns, for each question with a codebook, how many matching
+// answers carry each of its codes, in codebook order
+func (r *ReportRepository) codeCounts(surveyID uuid.UUID, filter models.ReportFilter) (map[uuid.UUID][]models.CodeCount, error) {
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT c.question_id, c.id, c.label, COUNT(r.id)
+		FROM answer_codes c
+		LEFT JOIN answer_code_assignments ca ON ca.code_id = c.id
+		LEFT JOIN answers a ON a.id = ca.answer_id
+		LEFT JOIN responses r ON r.id = a.response_id AND ` + q.where + `
+		WHERE c.survey_id = $1
+		GROUP BY c.question_id, c.id, c.label, c.position
+		ORDER BY c.question_id, c.position, c.label
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code counts: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make(map[uuid.UUID][]models.CodeCount)
+	for rows.Next() {
+		var questionID uuid.UUID
+		var cc models.CodeCount
+		if err := rows.Scan(&questionID, &cc.CodeID, &cc.Label, &cc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan code count: %w", err)
+		}
+		codes[questionID] = append(codes[questionID], cc)
+	}
+	return codes, rows.Err()
+}
+
+// optionCounts lists the question's options in order, followed by answered
+// values that are no longer options
+func optionCounts(options []string, counts map[string]int, answered int, confidence float64) []models.OptionCount {
+	result := []models.OptionCount{}
+	listed := make(map[string]bool)
+	add := func(option string) {
+		listed[option] = true
+		ci, _ := stats.ProportionCI(counts[option], answered, confidence)
+		result = append(result, models.OptionCount{
+			Option:     option,
+			Count:      counts[option],
+			Percentage: percentage(counts[option], answered),
+			CI:         ci,
+		})
+	}
+
+	for _, option := range options {
+		if !listed[option] {
+			add(option)
+		}
+	}
+	var others []string
+	for option := range counts {
+		if !listed[option] {
+			others = append(others, option)
+		}
+	}
+	sort.Strings(others)
+	for _, option := range others {
+		add(option)
+	}
+	return result
+}
+
+// ratingSummary fills the distribution from 1 to the question's maximum rating
+func ratingSummary(q models.Question, rs *ratingStats, answered int, confidence float64) *models.RatingSummary {
+	if rs == nil {
+		rs = &ratingStats{counts: map[int]int{}}
+	}
+	summary := &models.RatingSummary{
+		Mean:         rs.mean,
+		Median:       rs.median,
+		StdDev:       rs.stdDev,
+		Distribution: []models.RatingCount{},
+	}
+
+	n := 0
+	for _, count := range rs.counts {
+		n += count
+	}
+	if rs.mean != nil && rs.stdDev != nil {
+		summary.MeanCI, _ = stats.MeanCIFromSummary(*rs.mean, *rs.stdDev, n, confidence)
+	}
+
+	maxRating := q.MaxRating
+	if maxRating <= 0 {
+		maxRating = 5
+	}
+	for rating := range rs.counts {
+		if rating > maxRating {
+			maxRating = rating
+		}
+	}
+	for rating := 1; rating <= maxRating; rating++ {
+		summary.Distribution = append(summary.Distribution, models.RatingCount{
+			Rating:     rating,
+			Count:      rs.counts[rating],
+			Percentage: percentage(rs.counts[rating], answered),
+		})
+	}
+	return summary
+}
+
+// percentage returns count as a percentage of total, rounded to one decimal
+func percentage(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(int(float64(count)*1000/float64(total)+0.5)) / 10
+}
+
+// GetCrosstab cross-tabulates the answers to two questions over the responses
+// matching the filter. Only responses that answered both questions count.
+func (r *ReportRepository) GetCrosstab(survey *models.Survey, row, column models.Question, filter models.ReportFilter) (*models.Crosstab, error) {
+	q := newReportQuery(survey.ID, filter)
+	rowID := q.arg(row.ID)
+	columnID := q.arg(column.ID)
+	query := `
+		SELECT rv.v, cv.v, COUNT(*)
+		FROM responses r
+		JOIN answers ra ON ra.response_id = r.id AND ra.question_id = ` + rowID + `
+		CROSS JOIN LATERAL ` + answerValues("ra") + ` AS rv(v)
+		JOIN answers ca ON ca.response_id = r.id AND ca.question_id = ` + columnID + `
+		CROSS JOIN LATERAL ` + answerValues("ca") + ` AS cv(v)
+		WHERE ` + q.where + `
+		GROUP BY rv.v, cv.v
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crosstab: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[[2]string]int)
+	rowValues := make(map[string]bool)
+	columnValues := make(map[string]bool)
+	for rows.Next() {
+		var rowValue, columnValue string
+		var count int
+		if err := rows.Scan(&rowValue, &columnValue, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan crosstab: %w", err)
+		}
+		counts[[2]string{rowValue, columnValue}] = count
+		rowValues[rowValue] = true
+		columnValues[columnValue] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read crosstab: %w", err)
+	}
+
+	crosstab := &models.Crosstab{
+		SurveyID: survey.ID,
+		Filter:   filter,
+		Row:      crosstabAxis(row, rowValues),
+		Column:   crosstabAxis(column, columnValues),
+		Notes:    []string{},
+	}
+	if crosstab.Filter.Statuses == nil {
+		crosstab.Filter.Statuses = []string{}
+	}
+
+	table := make([][]float64, len(crosstab.Row.Categories))
+	crosstab.ColumnTotals = make([]int, len(crosstab.Column.Categories))
+	for i, rowCategory := range crosstab.Row.Categories {
+		cells := make([]int, len(crosstab.Column.Categories))
+		table[i] = make([]float64, len(cells))
+		total := 0
+		for j, columnCategory := range crosstab.Column.Categories {
+			cells[j] = counts[[2]string{rowCategory, columnCategory}]
+			table[i][j] = float64(cells[j])
+			total += cells[j]
+			crosstab.ColumnTotals[j] += cells[j]
+		}
+		crosstab.Counts = append(crosstab.Counts, cells)
+		crosstab.RowTotals = append(crosstab.RowTotals, total)
+		crosstab.Total += total
+	}
+
+	for i, cells := range crosstab.Counts {
+		rowPercentages := make([]float64, len(cells))
+		columnPercentages := make([]float64, len(cells))
+		for j, count := range cells {
+			rowPercentages[j] = percentage(count, crosstab.RowTotals[i])
+			columnPercentages[j] = percentage(count, crosstab.ColumnTotals[j])
+		}
+		crosstab.RowPercentages = append(crosstab.RowPercentages, rowPercentages)
+		crosstab.ColumnPercentages = append(crosstab.ColumnPercentages, columnPercentages)
+	}
+
+	switch {
+	case row.Type == "multi" || column.Type == "multi":
+		crosstab.Notes = append(crosstab.Notes, "The chi-square test was not computed because multi-choice answers count a respondent in several cells.")
+	default:
+		test, err := stats.ChiSquareIndependence(table)
+		if err != nil {
+			crosstab.Notes = append(crosstab.Notes, "The chi-square test needs at least two answered categories on each axis.")
+			break
+		}
+		crosstab.ChiSquare = test
+		if test.LowExpectedCells > 0 {
+			crosstab.Notes = append(crosstab.Notes, fmt.Sprintf("%d cells have an expected count below 5, so the chi-square p-value may be unreliable.", test.LowExpectedCells))
+		}
+	}
+
+	return crosstab, nil
+}
+
+// crosstabAxis lists the categories of a question: its options or ratings in
+// order, followed by answered values that are no longer among them
+func crosstabAxis(q models.Question, answered map[string]bool) models.CrosstabAxis {
+	axis := models.CrosstabAxis{QuestionID: q.ID, Type: q.Type, Title: q.Title, Categories: []string{}}
+	listed := make(map[string]bool)
+	add := func(category string) {
+		if !listed[category] {
+			listed[category] = true
+			axis.Categories = append(axis.Categories, category)
+		}
+	}
+
+	if q.Type == "rating" {
+		maxRating := q.MaxRating
+		if maxRating <= 0 {
+			maxRating = 5
+		}
+		for rating := 1; rating <= maxRating; rating++ {
+			add(strconv.Itoa(rating))
+		}
+	} else {
+		for _, option := range q.Options {
+			add(option)
+		}
+	}
+
+	var others []string
+	for value := range answered {
+		if !listed[value] {
+			others = append(others, value)
+		}
+	}
+	sort.Strings(others)
+	for _, value := range others {
+		add(value)
+	}
+	return axis
+}
+
+// GetRatingMatrix returns the ratings given to the questions by the responses
+// matching the filter: one row per response that rated any of them, one
+// column per question, with NaN where a question was not rated
+func (r *ReportRepository) GetRatingMatrix(surveyID uuid.UUID, questionIDs []uuid.UUID, filter models.ReportFilter) ([][]float64, error) {
+	columns := make(map[uuid.UUID]int, len(questionIDs))
+	ids := make([]string, len(questionIDs))
+	for i, id := range questionIDs {
+		columns[id] = i
+		ids[i] = id.String()
+	}
+
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT a.response_id, a.question_id, (a.value->>'rating')::float8
+		FROM answers a
+		JOIN responses r ON r.id = a.response_id
+		WHERE ` + q.where + ` AND a.question_id = ANY(` + q.arg(pq.Array(ids)) + `::uuid[])
+			AND jsonb_typeof(a.value->'rating') = 'number'
+		ORDER BY a.response_id
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var matrix [][]float64
+	var current uuid.UUID
+	for rows.Next() {
+		var responseID, questionID uuid.UUID
+		var rating float64
+		if err := rows.Scan(&responseID, &questionID, &rating); err != nil {
+			return nil, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		if len(matrix) == 0 || responseID != current {
+			row := make([]float64, len(questionIDs))
+			for i := range row {
+				row[i] = math.NaN()
+			}
+			matrix = append(matrix, row)
+			current = responseID
+		}
+		matrix[len(matrix)-1][columns[questionID]] = rating
+	}
+	return matrix, rows.Err()
+}
+
+// funnelResponse is a response with its answers in the order they were saved
+type funnelResponse struct {
+	status      string
+	startedAt   time.Time
+	completedAt *time.Time
+	answers     []funnelAnswer
+}
+
+type funnelAnswer struct {
+	questionID uuid.UUID
+	value      models.AnswerValue
+	savedAt    time.Time
+}
+
+// GetFunnel follows the responses matching the filter along their skip-logic
+// paths to find where respondents stop, timing each question from the
+// previous saved answer
+func (r *ReportRepository) GetFunnel(survey *models.Survey, filter models.ReportFilter) (*models.Funnel, error) {
+	q := newReportQuery(survey.ID, filter)
+	query := `
+		SELECT r.id, r.status, r.started_at, r.completed_at, a.question_id, a.value, a.created_at
+		FROM responses r
+		LEFT JOIN answers a ON a.response_id = r.id
+		WHERE ` + q.where + `
+		ORDER BY r.id, a.created_at
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query funnel answers: %w", err)
+	}
+	defer rows.Close()
+
+	var responses []funnelResponse
+	var current uuid.UUID
+	for rows.Next() {
+		var responseID uuid.UUID
+		var response funnelResponse
+		var questionID uuid.NullUUID
+		var valueJSON []byte
+		var savedAt sql.NullTime
+		if err := rows.Scan(&responseID, &response.status, &response.startedAt, &response.completedAt, &questionID, &valueJSON, &savedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan funnel answer: %w", err)
+		}
+
+		if len(responses) == 0 || responseID != current {
+			responses = append(responses, response)
+			current = responseID
+		}
+		if questionID.Valid {
+			answer := funnelAnswer{questionID: questionID.UUID, savedAt: savedAt.Time}
+			json.Unmarshal(valueJSON, &answer.value)
+			last := &responses[len(responses)-1]
+			last.answers = append(last.answers, answer)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read funnel answers: %w", err)
+	}
+
+	return buildFunnel(survey, filter, responses), nil
+}
+
+// buildFunnel counts, for each step, the responses that reached it, answered
+// it, were routed past it, or stopped at it
+func buildFunnel(survey *models.Survey, filter models.ReportFilter, responses []funnelResponse) *models.Funnel {
+	funnel := &models.Funnel{
+		SurveyID:  survey.ID,
+		Filter:    filter,
+		Responses: len(responses),
+		Notes:     []string{},
+	}
+	if funnel.Filter.Statuses == nil {
+		funnel.Filter.Statuses = []string{}
+	}
+
+	// One step per survey question, then the submit step
+	submit := len(survey.Questions)
+	steps := make([]models.FunnelStep, submit+1)
+	position := make(map[uuid.UUID]int, len(survey.Questions))
+	for i, q := range survey.Questions {
+		id := q.ID
+		steps[i] = models.FunnelStep{QuestionID: &id, Type: q.Type, Title: q.Title}
+		position[q.ID] = i
+	}
+	steps[submit] = models.FunnelStep{Type: models.FunnelStepSubmit, Title: "Submit"}
+
+	durations := make([][]float64, len(survey.Questions))
+	var completionTimes []float64
+	batched := 0
+
+	for _, response := range responses {
+		answered := make(map[uuid.UUID]bool, len(response.answers))
+		choices := make(map[uuid.UUID]string)
+		for _, answer := range response.answers {
+			answered[answer.questionID] = true
+			if answer.value.Value != nil {
+				choices[answer.questionID] = *answer.value.Value
+			}
+			if i, ok := position[answer.questionID]; ok {
+				steps[i].Answered++
+			}
+		}
+		batched += timeAnswers(response, position, durations)
+
+		path := models.LogicPath(survey.Questions, choices)
+		last := -1
+		for i, q := range path {
+			if answered[q.ID] {
+				last = i
+			}
+		}
+
+		// through is the index on the path, or len(path) for the submit step,
+		// of the last step the respondent reached. Section headers have no
+		// answer, so a respondent stopping after one stops at its first question.
+		through := last + 1
+		for through < len(path) && path[through].Type == "section" {
+			through++
+		}
+		switch response.status {
+		case "completed":
+			funnel.Completed++
+			through = len(path)
+			if response.completedAt != nil {
+				completionTimes = append(completionTimes, response.completedAt.Sub(response.startedAt).Seconds())
+			}
+		case "disqualified", "quota_full":
+			funnel.ScreenedOut++
+			through = last
+		case "abandoned":
+			funnel.Abandoned++
+		default:
+			funnel.InProgress++
+		}
+
+		stepAt := func(i int) int {
+			if i >= len(path) {
+				return submit
+			}
+			return position[path[i].ID]
+		}
+		reached := make(map[int]bool)
+		furthest := -1
+		for i := 0; i <= through; i++ {
+			step := stepAt(i)
+			reached[step] = true
+			steps[step].Reached++
+			if step != submit && step > furthest {
+				furthest = step
+			}
+		}
+
+		switch response.status {
+		case "abandoned":
+			steps[stepAt(through)].DroppedOff++
+		case "in_progress":
+			steps[stepAt(through)].InProgress++
+		case "completed":
+			// Questions left off a completed path, even after its last step, were skipped by logic
+			furthest = submit
+		}
+		for step := 0; step < furthest; step++ {
+			if !reached[step] {
+				steps[step].Bypassed++
+			}
+		}
+	}
+
+	for i := range steps {
+		if steps[i].Reached > 0 {
+			steps[i].DropOffRate = float64(steps[i].DroppedOff) / float64(steps[i].Reached)
+		}
+		if i < submit && len(durations[i]) > 0 {
+			median := stats.Median(durations[i])
+			steps[i].MedianSeconds = &median
+			steps[i].TimedAnswers = len(durations[i])
+		}
+	}
+	funnel.Steps = steps
+
+	if funnel.Responses > 0 {
+		funnel.CompletionRate = float64(funnel.Completed) / float64(funnel.Responses)
+	}
+	if len(completionTimes) > 0 {
+		median := stats.Median(completionTimes)
+		funnel.MedianCompletionSeconds = &median
+	}
+
+	if batched > 0 {
+		funnel.Notes = append(funnel.Notes, fmt.Sprintf("%d answers were saved together with others in one request, so they have no per-question timing.", batched))
+	}
+	if funnel.InProgress > 0 {
+		funnel.Notes = append(funnel.Notes, fmt.Sprintf("Responses still in progress (%d) are counted at their current step, not as drop-off.", funnel.InProgress))
+	}
+
+	return funnel
+}
+
+// timeAnswers records how long each answer took since the previous one was
+// saved (or the response started). Answers saved together in one request
+// cannot be timed individually; their number is returned.
+func timeAnswers(response funnelResponse, position map[uuid.UUID]int, durations [][]float64) int {
+	batched := 0
+	previous := response.startedAt
+	for i := 0; i < len(response.answers); {
+		j := i
+		for j+1 < len(response.answers) && response.answers[j+1].savedAt.Equal(response.answers[i].savedAt) {
+			j++
+		}
+
+		answer := response.answers[i]
+		if j > i {
+			batched += j - i + 1
+		} else if step, ok := position[answer.questionID]; ok {
+			if seconds := answer.savedAt.Sub(previous).Seconds(); seconds >= 0 {
+				durations[step] = append(durations[step], seconds)
+			}
+		}
+
+		previous = answer.savedAt
+		i = j + 1
+	}
+	return batched
+}
+
+// CountCompleted returns the number of completed responses to a survey
+func (r *ReportRepository) CountCompleted(surveyID uuid.UUID) (int, error) {
+	var completed int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM responses WHERE survey_id = $1 AND status = 'completed'",
+		surveyID,
+	).Scan(&completed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count completed responses: %w", err)
+	}
+	return completed, nil
+}
+
+// GetResponseEvent builds the live event for a completed response, or
+// returns nil if the response is not completed
+func (r *ReportRepository) GetResponseEvent(responseID uuid.UUID) (*models.ResponseEvent, error) {
+	event := &models.ResponseEvent{ResponseID: responseID, Deltas: []models.AnswerDelta{}}
+	err := r.db.QueryRow(`
+		SELECT r.survey_id, r.completed_at,
+			(SELECT COUNT(*) FROM responses c WHERE c.survey_id = r.survey_id AND c.status = 'completed')
+		FROM responses r
+		WHERE r.id = $1 AND r.status = 'completed' AND r.completed_at IS NOT NULL
+	`, responseID).Scan(&event.SurveyID, &event.CompletedAt, &event.Completed)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completed response: %w", err)
+	}
+
+	rows, err := r.db.Query("SELECT question_id, value FROM answers WHERE response_id = $1", responseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response answers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var delta models.AnswerDelta
+		var valueJSON []byte
+		if err := rows.Scan(&delta.QuestionID, &valueJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan response answer: %w", err)
+		}
+
+		var value models.AnswerValue
+		json.Unmarshal(valueJSON, &value)
+		switch {
+		case value.Value != nil:
+			delta.Options = []string{*value.Value}
+		case len(value.Values) > 0:
+			delta.Options = value.Values
+		}
+		delta.Rating = value.Rating
+		event.Deltas = append(event.Deltas, delta)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response answers: %w", err)
+	}
+
+	return event, nil
+}
+
+// SampleTextAnswers returns up to perQuestion text answers to each question
+// from the responses matching the filter. The sample is stable: the same
+// answers are picked each time, whatever order they were saved in.
+func (r *ReportRepository) SampleTextAnswers(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string, perQuestion int) (map[uuid.UUID][]string, error) {
+	samples := make(map[uuid.UUID][]string)
+	if len(questionIDs) == 0 || perQuestion <= 0 {
+		return samples, nil
+	}
+
+	q := newReportQuery(surveyID, filter)
+	ids := q.arg(pq.Array(questionIDs))
+	limit := q.arg(perQuestion)
+	query := `
+		SELECT question_id, text FROM (
+			SELECT a.question_id, a.value->>'text' AS text,
+				ROW_NUMBER() OVER (PARTITION BY a.question_id ORDER BY md5(a.response_id::text || a.question_id::text)) AS rank
+			FROM answers a
+			JOIN responses r ON r.id = a.response_id
+			WHERE ` + q.where + ` AND a.question_id = ANY(` + ids + `::uuid[])
+				AND jsonb_typeof(a.value->'text') = 'string'
+				AND btrim(a.value->>'text') <> ''
+		) sampled
+		WHERE rank <= ` + limit + `
+		ORDER BY question_id, rank
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample text answers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, fmt.Errorf("failed to scan text answer: %w", err)
+		}
+		samples[id] = append(samples[id], text)
+	}
+	return samples, rows.Err()
+}
+
+// GetResponseRecords returns the responses matching the filter with their
+// answers and answer codes, in the order they were completed (or started)
+func (r *ReportRepository) GetResponseRecords(surveyID uuid.UUID, filter models.ReportFilter) ([]models.ResponseRecord, error) {
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT r.id, r.status, r.started_at, r.completed_at, a.question_id, a.value,
+			ARRAY(SELECT ca.code_id::text FROM answer_code_assignments ca WHERE ca.answer_id = a.id)
+		FROM responses r
+		LEFT JOIN answers a ON a.response_id = r.id
+		WHERE ` + q.where + `
+		ORDER BY COALESCE(r.completed_at, r.started_at), r.id
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query response records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanResponseRecords(rows)
+}
+
+// GetResponseRecordsPage returns up to limit of the responses matching the
+// filter, with their answers, in the order of GetResponseRecords. It starts
+// after the given response, or at the first response if after is nil, so a
+// large survey can be read one page at a time.
+func (r *ReportRepository) GetResponseRecordsPage(surveyID uuid.UUID, filter models.ReportFilter, after *models.ResponseRecord, limit int) ([]models.ResponseRecord, error) {
+	q := newReportQuery(surveyID, filter)
+	where := q.where
+	if after != nil {
+		at := after.StartedAt
+		if after.CompletedAt != nil {
+			at = *after.CompletedAt
+		}
+		where += " AND (COALESCE(r.completed_at, r.started_at), r.id) > (" + q.arg(at) + ", " + q.arg(after.ID) + ")"
+	}
+	query := `
+		WITH page AS (
+			SELECT r.id, r.status, r.started_at, r.completed_at
+			FROM responses r
+			WHERE ` + where + `
+			ORDER BY COALESCE(r.completed_at, r.started_at), r.id
+			LIMIT ` + q.arg(limit) + `
+		)
+		SELECT p.id, p.status, p.started_at, p.completed_at, a.question_id, a.value,
+			ARRAY(SELECT ca.code_id::text FROM answer_code_assignments ca WHERE ca.answer_id = a.id)
+		FROM page p
+		LEFT JOIN answers a ON a.response_id = p.id
+		ORDER BY COALESCE(p.completed_at, p.started_at), p.id
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query response records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanResponseRecords(rows)
+}
+
+// scanResponseRecords reads rows of a response's fields and one of its
+// answers, ordered by response, into records
+func scanResponseRecords(rows *sql.Rows) ([]models.ResponseRecord, error) {
+	var records []models.ResponseRecord
+	for rows.Next() {
+		var record models.ResponseRecord
+		var questionID uuid.NullUUID
+		var valueJSON []byte
+		var codeIDs []string
+		if err := rows.Scan(&record.ID, &record.Status, &record.StartedAt, &record.CompletedAt, &questionID, &valueJSON, pq.Array(&codeIDs)); err != nil {
+			return nil, fmt.Errorf("failed to scan response record: %w", err)
+		}
+
+		if len(records) == 0 || records[len(records)-1].ID != record.ID {
+			record.Answers = make(map[uuid.UUID]models.AnswerValue)
+			record.Codes = make(map[uuid.UUID][]uuid.UUID)
+			records = append(records, record)
+		}
+		if !questionID.Valid {
+			continue
+		}
+
+		last := &records[len(records)-1]
+		var value models.AnswerValue
+		json.Unmarshal(valueJSON, &value)
+		last.Answers[questionID.UUID] = value
+		for _, id := range codeIDs {
+			last.Codes[questionID.UUID] = append(last.Codes[questionID.UUID], uuid.MustParse(id))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response records: %w", err)
+	}
+
+	return records, nil
+}
+
+// CountResponses counts the responses matching the filter
+func (r *ReportRepository) CountResponses(surveyID uuid.UUID, filter models.ReportFilter) (int, error) {
+	q := newReportQuery(surveyID, filter)
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM responses r WHERE "+q.where, q.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count responses: %w", err)
+	}
+	return count, nil
+}
+
+// GetAnsweredValues returns the options chosen in the responses matching the
+// filter for the given choice questions, by question ID, in the order they
+// were first chosen. It gives the same categories as export.AnsweredValues
+// without loading the responses.
+func (r *ReportRepository) GetAnsweredValues(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string) (map[uuid.UUID][]string, error) {
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT a.question_id, av.v
+		FROM responses r
+		JOIN answers a ON a.response_id = r.id AND a.question_id = ANY(` + q.arg(pq.Array(questionIDs)) + `::uuid[])
+		CROSS JOIN LATERAL ` + answerValues("a") + ` AS av(v)
+		WHERE ` + q.where + `
+		GROUP BY a.question_id, av.v
+		ORDER BY MIN(COALESCE(r.completed_at, r.started_at)), av.v
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answered values: %w", err)
+	}
+	defer rows.Close()
+
+	answered := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var questionID uuid.UUID
+		var value string
+		if err := rows.Scan(&questionID, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan answered value: %w", err)
+		}
+		answered[questionID] = append(answered[questionID], value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read answered values: %w", err)
+	}
+
+	return answered, nil
+}
+
+// GetChoiceAnswers returns the IDs of the responses matching the filter, in
+// the order they were completed (or started), and the option each chose for
+// the given single-choice questions
+func (r *ReportRepository) GetChoiceAnswers(surveyID uuid.UUID, filter models.ReportFilter, questionIDs []string) ([]uuid.UUID, map[uuid.UUID]map[uuid.UUID]string, error) {
+	q := newReportQuery(surveyID, filter)
+	query := `
+		SELECT r.id, a.question_id, a.value->>'value'
+		FROM responses r
+		LEFT JOIN answers a ON a.response_id = r.id
+			AND a.question_id = ANY(` + q.arg(pq.Array(questionIDs)) + `::uuid[])
+			AND jsonb_typeof(a.value->'value') = 'string'
+		WHERE ` + q.where + `
+		ORDER BY COALESCE(r.completed_at, r.started_at), r.id
+	`
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query choice answers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	choices := make(map[uuid.UUID]map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var questionID uuid.NullUUID
+		var value sql.NullString
+		if err := rows.Scan(&id, &questionID, &value); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan choice answer: %w", err)
+		}
+		if len(ids) == 0 || ids[len(ids)-1] != id {
+			ids = append(ids, id)
+			choices[id] = make(map[uuid.UUID]string)
+		}
+		if questionID.Valid && value.Valid {
+			choices[id][questionID.UUID] = value.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read choice answers: %w", err)
+	}
+
+	return ids, choices, nil
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +13,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrResponseNotInProgress is returned when completing a response that was already finalized
+var ErrResponseNotInProgress = errors.New("response is not in progress")
+
 // ResponseRepository handles response database operations
 type ResponseRepository struct {
 	db *sql.DB
@@ -20,20 +26,27 @@ func NewResponseRepository(db *sql.DB) *ResponseRepository {
 	return &ResponseRepository{db: db}
 }
 
-// Create creates a new response
+// Create creates a new response, storing only the hash of its resume token
 func (r *ResponseRepository) Create(response *models.Response) error {
+	var tokenHash *string
+	if response.ResumeToken != nil {
+		hash := hashToken(*response.ResumeToken)
+		tokenHash = &hash
+	}
+
 	query := `
 		INSERT INTO responses (
-			id, survey_id, user_id, anonymous_id, status, points_awarded, started_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at
+			id, survey_id, user_id, anonymous_id, status, points_awarded, started_at,
+			resume_token_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, last_activity_at, created_at
 	`
 
 	err := r.db.QueryRow(
 		query,
 		response.ID, response.SurveyID, response.UserID, response.AnonymousID,
-		response.Status, response.PointsAwarded, response.StartedAt,
-	).Scan(&response.ID, &response.CreatedAt)
+		response.Status, response.PointsAwarded, response.StartedAt, tokenHash,
+	).Scan(&response.ID, &response.LastActivityAt, &response.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create response: %w", err)
@@ -42,22 +55,52 @@ func (r *ResponseRepository) Create(response *models.Response) error {
 	return nil
 }
 
+// responseColumns lists the response columns read by scanResponse
+const responseColumns = `
+	id, survey_id, user_id, anonymous_id, status, points_awarded,
+	started_at, completed_at, last_activity_at, created_at
+`
+
+// scanResponse reads a response selected with responseColumns
+func scanResponse(row rowScanner) (*models.Response, error) {
+	response := &models.Response{}
+	err := row.Scan(
+		&response.ID, &response.SurveyID, &response.UserID, &response.AnonymousID,
+		&response.Status, &response.PointsAwarded, &response.StartedAt,
+		&response.CompletedAt, &response.LastActivityAt, &response.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 // GetByID retrieves a response by ID
 func (r *ResponseRepository) GetByID(id uuid.UUID) (*models.Response, error) {
-	response := &models.Response{}
+	query := `SELECT ` + responseColumns + ` FROM responses WHERE id = $1`
+	return r.getOne(query, id)
+}
+
+// GetByResumeToken retrieves a response by the resume token issued when it was started
+func (r *ResponseRepository) GetByResumeToken(token string) (*models.Response, error) {
+	query := `SELECT ` + responseColumns + ` FROM responses WHERE resume_token_hash = $1`
+	return r.getOne(query, hashToken(token))
+}
 
+// GetInProgressByUser retrieves a user's most recent in-progress response to a survey
+func (r *ResponseRepository) GetInProgressByUser(surveyID, userID uuid.UUID) (*models.Response, error) {
 	query := `
-		SELECT id, survey_id, user_id, anonymous_id, status, points_awarded,
-			started_at, completed_at, created_at
-		FROM responses WHERE id = $1
+		SELECT ` + responseColumns + ` FROM responses
+		WHERE survey_id = $1 AND user_id = $2 AND status = 'in_progress'
+		ORDER BY last_activity_at DESC
+		LIMIT 1
 	`
+	return r.getOne(query, surveyID, userID)
+}
 
-	err := r.db.QueryRow(query, id).Scan(
-		&response.ID, &response.SurveyID, &response.UserID, &response.AnonymousID,
-		&response.Status, &response.PointsAwarded, &response.StartedAt,
-		&response.CompletedAt, &response.CreatedAt,
-	)
-
+// getOne loads a single response with its answers
+func (r *ResponseRepository) getOne(query string, args ...interface{}) (*models.Response, error) {
+	response, err := scanResponse(r.db.QueryRow(query, args...))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -66,7 +109,7 @@ func (r *ResponseRepository) GetByID(id uuid.UUID) (*models.Response, error) {
 	}
 
 	// Load answers
-	answers, err := r.GetAnswers(id)
+	answers, err := r.GetAnswers(response.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +121,7 @@ func (r *ResponseRepository) GetByID(id uuid.UUID) (*models.Response, error) {
 // GetBySurveyID retrieves all responses for a survey
 func (r *ResponseRepository) GetBySurveyID(surveyID uuid.UUID) ([]models.Response, error) {
 	query := `
-		SELECT id, survey_id, user_id, anonymous_id, status, points_awarded,
-			started_at, completed_at, created_at
+		SELECT ` + responseColumns + `
 		FROM responses WHERE survey_id = $1
 		ORDER BY created_at DESC
 	`
@@ -92,37 +134,313 @@ func (r *ResponseRepository) GetBySurveyID(surveyID uuid.UUID) ([]models.Respons
 
 	var responses []models.Response
 	for rows.Next() {
-		var response models.Response
-		err := rows.Scan(
-			&response.ID, &response.SurveyID, &response.UserID, &response.AnonymousID,
-			&response.Status, &response.PointsAwarded, &response.StartedAt,
-			&response.CompletedAt, &response.CreatedAt,
-		)
+		response, err := scanResponse(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan response: %w", err)
 		}
-		responses = append(responses, response)
+		responses = append(responses, *response)
 	}
 
 	return responses, nil
 }
 
-// Complete marks a response as completed
-func (r *ResponseRepository) Complete(id uuid.UUID, pointsAwarded int) error {
+// SetResumeToken replaces the resume token of a response
+func (r *ResponseRepository) SetResumeToken(id uuid.UUID, token string) error {
+	_, err := r.db.Exec(
+		"UPDATE responses SET resume_token_hash = $2 WHERE id = $1",
+		id, hashToken(token),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set resume token: %w", err)
+	}
+	return nil
+}
+
+// MatchesResumeToken reports whether a token is the current resume token of a response
+func (r *ResponseRepository) MatchesResumeToken(id uuid.UUID, token string) (bool, error) {
+	var matches bool
+	err := r.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM responses WHERE id = $1 AND resume_token_hash = $2)",
+		id, hashToken(token),
+	).Scan(&matches)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify resume token: %w", err)
+	}
+	return matches, nil
+}
+
+// Reactivate moves an abandoned response back to in_progress
+func (r *ResponseRepository) Reactivate(id uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE responses SET status = 'in_progress', last_activity_at = NOW()
+		WHERE id = $1 AND status = 'abandoned'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate response: %w", err)
+	}
+	return nil
+}
+
+// MarkAbandoned marks in-progress responses with no activity since the cutoff as abandoned
+func (r *ResponseRepository) MarkAbandoned(inactiveSince time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE responses SET status = 'abandoned'
+		WHERE status = 'in_progress' AND last_activity_at < $1
+	`, inactiveSince)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark abandoned responses: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// touch records respondent activity on a response
+func (r *ResponseRepository) touch(id uuid.UUID) error {
+	_, err := r.db.Exec("UPDATE responses SET last_activity_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to update response activity: %w", err)
+	}
+	return nil
+}
+
+// hashToken returns the hex SHA-256 of a secret token; only hashes are stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompletionResult describes the outcome of completing a response
+type CompletionResult struct {
+	Status           string
+	PointsAwarded    int
+	ScreenOutMessage *string
+	SurveyClosed     bool
+}
+
+// DefaultScreenOutMessage is shown when a full quota has no message of its own
+const DefaultScreenOutMessage = "Thank you for your interest. This survey has already reached its quota for your group."
+
+// Complete marks a response as completed, evaluating the survey's quotas,
+// incrementing the survey response count and closing the survey when its
+// overall quota is filled, all in a single transaction.
+// If a matching quota is already full the response is marked quota_full instead.
+func (r *ResponseRepository) Complete(id uuid.UUID, pointsAwarded int) (*CompletionResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the response so concurrent submits cannot both count it
+	var surveyID uuid.UUID
+	var status string
+	err = tx.QueryRow(
+		"SELECT survey_id, status FROM responses WHERE id = $1 FOR UPDATE",
+		id,
+	).Scan(&surveyID, &status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock response: %w", err)
+	}
+	if status != "in_progress" {
+		return nil, ErrResponseNotInProgress
+	}
+
+	answers, err := scanAnswers(tx.Query(`
+		SELECT id, response_id, question_id, value, created_at
+		FROM answers WHERE response_id = $1
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the survey's quotas in a stable order to avoid deadlocks
+	rows, err := tx.Query(`
+		SELECT id, survey_id, name, question_id, option_value, target,
+			current_count, screen_out_message, is_active, created_at, updated_at
+		FROM survey_quotas WHERE survey_id = $1 AND is_active = true
+		ORDER BY id
+		FOR UPDATE
+	`, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quotas: %w", err)
+	}
+	var matched []models.SurveyQuota
+	for rows.Next() {
+		var quota models.SurveyQuota
+		err := rows.Scan(
+			&quota.ID, &quota.SurveyID, &quota.Name, &quota.QuestionID,
+			&quota.OptionValue, &quota.Target, &quota.CurrentCount,
+			&quota.ScreenOutMessage, &quota.IsActive, &quota.CreatedAt, &quota.UpdatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan quota: %w", err)
+		}
+		if quotaMatches(quota, answers) {
+			matched = append(matched, quota)
+		}
+	}
+	rows.Close()
+
 	now := time.Now()
-	query := `
+
+	// Screen out if any matching quota is already full
+	for _, quota := range matched {
+		if quota.CurrentCount < quota.Target {
+			continue
+		}
+
+		_, err = tx.Exec(`
+			UPDATE responses SET status = 'quota_full', completed_at = $2, points_awarded = 0
+			WHERE id = $1
+		`, id, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to screen out response: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		message := quota.ScreenOutMessage
+		if message == nil || *message == "" {
+			defaultMessage := DefaultScreenOutMessage
+			message = &defaultMessage
+		}
+		return &CompletionResult{Status: "quota_full", ScreenOutMessage: message}, nil
+	}
+
+	result := &CompletionResult{Status: "completed", PointsAwarded: pointsAwarded}
+
+	for _, quota := range matched {
+		_, err = tx.Exec(
+			"UPDATE survey_quotas SET current_count = current_count + 1 WHERE id = $1",
+			quota.ID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to increment quota: %w", err)
+		}
+		if quota.QuestionID == nil && quota.CurrentCount+1 >= quota.Target {
+			result.SurveyClosed = true
+		}
+	}
+
+	_, err = tx.Exec(`
 		UPDATE responses SET status = 'completed', completed_at = $2, points_awarded = $3
 		WHERE id = $1
+	`, id, now, pointsAwarded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete response: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE surveys SET response_count = response_count + 1 WHERE id = $1",
+		surveyID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to increment response count: %w", err)
+	}
+
+	// Close the survey once its overall quota is filled and notify the owner
+	if result.SurveyClosed {
+		var ownerID uuid.UUID
+		var title string
+		err = tx.QueryRow(`
+			UPDATE surveys SET is_published = false, closed_at = $2, revision = revision + 1
+			WHERE id = $1
+			RETURNING user_id, title
+		`, surveyID, now).Scan(&ownerID, &title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to close survey: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO notifications (id, user_id, type, title, message, survey_id)
+			VALUES ($1, $2, 'survey_closed', $3, $4, $5)
+		`, uuid.New(), ownerID, fmt.Sprintf("Survey \"%s\" has closed", title),
+			"Your survey filled its overall quota and is no longer accepting new responses.", surveyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notification: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// Disqualify marks an in-progress response as disqualified by a screener question
+func (r *ResponseRepository) Disqualify(id uuid.UUID, pointsAwarded int) error {
+	query := `
+		UPDATE responses SET status = 'disqualified', completed_at = $2, points_awarded = $3
+		WHERE id = $1 AND status = 'in_progress'
 	`
 
-	_, err := r.db.Exec(query, id, now, pointsAwarded)
+	result, err := r.db.Exec(query, id, time.Now(), pointsAwarded)
 	if err != nil {
-		return fmt.Errorf("failed to complete response: %w", err)
+		return fmt.Errorf("failed to disqualify response: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to disqualify response: %w", err)
+	}
+	if affected == 0 {
+		return ErrResponseNotInProgress
 	}
 
 	return nil
 }
 
+// GetStats retrieves response status counts for a survey
+func (r *ResponseRepository) GetStats(surveyID uuid.UUID) (*models.SurveyStats, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM responses WHERE survey_id = $1
+		GROUP BY status
+	`
+
+	rows, err := r.db.Query(query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query response stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &models.SurveyStats{SurveyID: surveyID}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan response stats: %w", err)
+		}
+
+		stats.Started += count
+		switch status {
+		case "in_progress":
+			stats.InProgress = count
+		case "completed":
+			stats.Completed = count
+		case "abandoned":
+			stats.Abandoned = count
+		case "quota_full":
+			stats.QuotaFull = count
+		case "disqualified":
+			stats.Disqualified = count
+		}
+	}
+
+	if stats.Started > 0 {
+		started := float64(stats.Started)
+		stats.CompletionRate = float64(stats.Completed) / started
+		stats.ScreenOutRate = float64(stats.Disqualified+stats.QuotaFull) / started
+		stats.DisqualifiedRate = float64(stats.Disqualified) / started
+	}
+
+	return stats, nil
+}
+
 // SaveAnswer saves an answer to a question
 func (r *ResponseRepository) SaveAnswer(answer *models.Answer) error {
 	valueJSON, err := json.Marshal(answer.Value)
@@ -147,7 +465,7 @@ func (r *ResponseRepository) SaveAnswer(answer *models.Answer) error {
 		return fmt.Errorf("failed to save answer: %w", err)
 	}
 
-	return nil
+	return r.touch(answer.ResponseID)
 }
 
 // GetAnswers retrieves all answers for a response
@@ -157,7 +475,11 @@ func (r *ResponseRepository) GetAnswers(responseID uuid.UUID) ([]models.Answer,
 		FROM answers WHERE response_id = $1
 	`
 
-	rows, err := r.db.Query(query, responseID)
+	return scanAnswers(r.db.Query(query, responseID))
+}
+
+// scanAnswers reads answer rows returned by a query
+func scanAnswers(rows *sql.Rows, err error) ([]models.Answer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to query answers: %w", err)
 	}
@@ -186,7 +508,8 @@ func (r *ResponseRepository) GetAnswers(responseID uuid.UUID) ([]models.Answer,
 	return answers, nil
 }
 
-// SaveAllAnswers saves multiple answers in a transaction
+// SaveAllAnswers saves multiple answers in a transaction. Answers already
+// saved one at a time, as in a resumed response, are overwritten.
 func (r *ResponseRepository) SaveAllAnswers(responseID uuid.UUID, answers []models.Answer) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -200,13 +523,20 @@ func (r *ResponseRepository) SaveAllAnswers(responseID uuid.UUID, answers []mode
 		query := `
 			INSERT INTO answers (id, response_id, question_id, value)
 			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (response_id, question_id)
+			DO UPDATE SET value = EXCLUDED.value
 		`
 
 		_, err = tx.Exec(query, answer.ID, responseID, answer.QuestionID, valueJSON)
 		if err != nil {
-			return fmt.Errorf("failed to insert answer: %w", err)
+			return fmt.Errorf("failed to save answer: %w", err)
 		}
 	}
 
+	_, err = tx.Exec("UPDATE responses SET last_activity_at = NOW() WHERE id = $1", responseID)
+	if err != nil {
+		return fmt.Errorf("failed to update response activity: %w", err)
+	}
+
 	return tx.Commit()
 }
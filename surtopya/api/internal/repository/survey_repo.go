@@ -3,10 +3,12 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/TimLai666/surtopya-api/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // SurveyRepository handles survey database operations
@@ -21,25 +23,73 @@ func NewSurveyRepository(db *sql.DB) *SurveyRepository {
 
 // Create creates a new survey
 func (r *SurveyRepository) Create(survey *models.Survey) error {
+	return createSurvey(r.db, survey)
+}
+
+// CreateWithQuotas creates a survey with its questions and quotas in one
+// transaction, so a failure leaves no partial survey behind
+func (r *SurveyRepository) CreateWithQuotas(survey *models.Survey, quotas []models.SurveyQuota) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createSurvey(tx, survey); err != nil {
+		return err
+	}
+	for i := range survey.Questions {
+		survey.Questions[i].SurveyID = survey.ID
+		survey.Questions[i].SortOrder = i
+		if err := upsertQuestion(tx, &survey.Questions[i]); err != nil {
+			return err
+		}
+	}
+	for i := range quotas {
+		quotas[i].SurveyID = survey.ID
+		if err := insertQuota(tx, &quotas[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// queryRower runs a query returning one row, on the database or in a transaction
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// createSurvey inserts a survey
+func createSurvey(db queryRower, survey *models.Survey) error {
 	themeJSON, err := json.Marshal(survey.Theme)
 	if err != nil {
 		return fmt.Errorf("failed to marshal theme: %w", err)
 	}
 
+	audienceJSON, err := marshalTargetAudience(survey.TargetAudience)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO surveys (
 			id, user_id, title, description, visibility, is_published,
-			include_in_datasets, published_count, theme, points_reward, expires_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, created_at, updated_at
+			include_in_datasets, published_count, theme, points_reward, expires_at,
+			consolation_points, disqualification_message, target_audience, opens_at,
+			organization_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, revision, created_at, updated_at
 	`
 
-	err = r.db.QueryRow(
+	err = db.QueryRow(
 		query,
 		survey.ID, survey.UserID, survey.Title, survey.Description,
 		survey.Visibility, survey.IsPublished, survey.IncludeInDatasets,
 		survey.PublishedCount, themeJSON, survey.PointsReward, survey.ExpiresAt,
-	).Scan(&survey.ID, &survey.CreatedAt, &survey.UpdatedAt)
+		survey.ConsolationPoints, survey.DisqualificationMessage, audienceJSON,
+		survey.OpensAt, survey.OrganizationID,
+	).Scan(&survey.ID, &survey.Revision, &survey.CreatedAt, &survey.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create survey: %w", err)
@@ -48,31 +98,39 @@ func (r *SurveyRepository) Create(survey *models.Survey) error {
 	return nil
 }
 
-// GetByID retrieves a survey by ID
-func (r *SurveyRepository) GetByID(id uuid.UUID) (*models.Survey, error) {
-	survey := &models.Survey{}
-	var themeJSON []byte
+// surveyColumns lists the survey columns read by scanSurvey (table alias s)
+const surveyColumns = `
+	s.id, s.user_id, s.title, s.description, s.visibility, s.is_published,
+	s.include_in_datasets, s.published_count, s.theme, s.points_reward,
+	s.expires_at, s.response_count, s.created_at, s.updated_at, s.published_at,
+	s.consolation_points, s.disqualification_message, s.target_audience,
+	s.opens_at, s.closed_at, s.taken_down_at, s.takedown_reason,
+	s.organization_id, s.revision
+`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	query := `
-		SELECT id, user_id, title, description, visibility, is_published,
-			include_in_datasets, published_count, theme, points_reward,
-			expires_at, response_count, created_at, updated_at, published_at
-		FROM surveys WHERE id = $1
-	`
+// scanSurvey reads a survey selected with surveyColumns, followed by any extra columns
+func scanSurvey(row rowScanner, extra ...interface{}) (*models.Survey, error) {
+	survey := &models.Survey{}
+	var themeJSON, audienceJSON []byte
 
-	err := r.db.QueryRow(query, id).Scan(
+	dest := []interface{}{
 		&survey.ID, &survey.UserID, &survey.Title, &survey.Description,
 		&survey.Visibility, &survey.IsPublished, &survey.IncludeInDatasets,
 		&survey.PublishedCount, &themeJSON, &survey.PointsReward,
 		&survey.ExpiresAt, &survey.ResponseCount, &survey.CreatedAt,
 		&survey.UpdatedAt, &survey.PublishedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
+		&survey.ConsolationPoints, &survey.DisqualificationMessage, &audienceJSON,
+		&survey.OpensAt, &survey.ClosedAt, &survey.TakenDownAt, &survey.TakedownReason,
+		&survey.OrganizationID, &survey.Revision,
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get survey: %w", err)
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
 
 	if len(themeJSON) > 0 {
@@ -82,6 +140,48 @@ func (r *SurveyRepository) GetByID(id uuid.UUID) (*models.Survey, error) {
 		}
 	}
 
+	if len(audienceJSON) > 0 {
+		survey.TargetAudience = &models.TargetAudience{}
+		if err := json.Unmarshal(audienceJSON, survey.TargetAudience); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal target audience: %w", err)
+		}
+	}
+
+	return survey, nil
+}
+
+// querySurveys runs a query selecting surveyColumns and scans every row
+func (r *SurveyRepository) querySurveys(query string, args ...interface{}) ([]models.Survey, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query surveys: %w", err)
+	}
+	defer rows.Close()
+
+	var surveys []models.Survey
+	for rows.Next() {
+		survey, err := scanSurvey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan survey: %w", err)
+		}
+		surveys = append(surveys, *survey)
+	}
+
+	return surveys, nil
+}
+
+// GetByID retrieves a survey by ID
+func (r *SurveyRepository) GetByID(id uuid.UUID) (*models.Survey, error) {
+	query := `SELECT ` + surveyColumns + ` FROM surveys s WHERE s.id = $1`
+
+	survey, err := scanSurvey(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survey: %w", err)
+	}
+
 	// Load questions
 	questions, err := r.GetQuestions(id)
 	if err != nil {
@@ -95,11 +195,40 @@ func (r *SurveyRepository) GetByID(id uuid.UUID) (*models.Survey, error) {
 // GetByUserID retrieves all surveys for a user
 func (r *SurveyRepository) GetByUserID(userID uuid.UUID) ([]models.Survey, error) {
 	query := `
-		SELECT id, user_id, title, description, visibility, is_published,
-			include_in_datasets, published_count, theme, points_reward,
-			expires_at, response_count, created_at, updated_at, published_at
-		FROM surveys WHERE user_id = $1
-		ORDER BY updated_at DESC
+		SELECT ` + surveyColumns + `
+		FROM surveys s WHERE s.user_id = $1
+		ORDER BY s.updated_at DESC
+	`
+
+	return r.querySurveys(query, userID)
+}
+
+// accessRoleJoin resolves the best access role of user $1 on survey s: owner
+// for its creator, otherwise the higher of their organization and collaborator
+// roles. Surveys the user cannot access produce no row.
+const accessRoleJoin = `
+	CROSS JOIN LATERAL (
+		SELECT r.role FROM (
+			SELECT 'owner' AS role WHERE s.user_id = $1
+			UNION ALL
+			SELECT m.role FROM organization_members m
+			WHERE m.organization_id = s.organization_id AND m.user_id = $1
+			UNION ALL
+			SELECT sc.role FROM survey_collaborators sc
+			WHERE sc.survey_id = s.id AND sc.user_id = $1
+		) r
+		ORDER BY array_position(ARRAY['owner', 'editor', 'analyst', 'viewer'], r.role::text)
+		LIMIT 1
+	) access
+`
+
+// GetAccessibleByUserID retrieves the surveys a user owns or can access through
+// an organization or as a collaborator, with the user's access role set on each survey
+func (r *SurveyRepository) GetAccessibleByUserID(userID uuid.UUID) ([]models.Survey, error) {
+	query := `
+		SELECT ` + surveyColumns + `, access.role
+		FROM surveys s ` + accessRoleJoin + `
+		ORDER BY s.updated_at DESC
 	`
 
 	rows, err := r.db.Query(query, userID)
@@ -110,98 +239,139 @@ func (r *SurveyRepository) GetByUserID(userID uuid.UUID) ([]models.Survey, error
 
 	var surveys []models.Survey
 	for rows.Next() {
-		var survey models.Survey
-		var themeJSON []byte
-
-		err := rows.Scan(
-			&survey.ID, &survey.UserID, &survey.Title, &survey.Description,
-			&survey.Visibility, &survey.IsPublished, &survey.IncludeInDatasets,
-			&survey.PublishedCount, &themeJSON, &survey.PointsReward,
-			&survey.ExpiresAt, &survey.ResponseCount, &survey.CreatedAt,
-			&survey.UpdatedAt, &survey.PublishedAt,
-		)
+		var role string
+		survey, err := scanSurvey(rows, &role)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan survey: %w", err)
 		}
+		survey.Role = role
+		surveys = append(surveys, *survey)
+	}
 
-		if len(themeJSON) > 0 {
-			survey.Theme = &models.SurveyTheme{}
-			json.Unmarshal(themeJSON, survey.Theme)
-		}
+	return surveys, nil
+}
 
-		surveys = append(surveys, survey)
+// GetByOrganizationID retrieves all surveys of an organization
+func (r *SurveyRepository) GetByOrganizationID(orgID uuid.UUID) ([]models.Survey, error) {
+	query := `
+		SELECT ` + surveyColumns + `
+		FROM surveys s WHERE s.organization_id = $1
+		ORDER BY s.updated_at DESC
+	`
+
+	return r.querySurveys(query, orgID)
+}
+
+// GetAccessRole returns the access role a user holds on a survey ("" if none)
+func (r *SurveyRepository) GetAccessRole(survey *models.Survey, userID uuid.UUID) (string, error) {
+	if survey.UserID == userID {
+		return models.AccessRoleOwner, nil
 	}
 
-	return surveys, nil
+	var role string
+	query := `SELECT access.role FROM surveys s ` + accessRoleJoin + ` WHERE s.id = $2`
+	err := r.db.QueryRow(query, userID, survey.ID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get access role: %w", err)
+	}
+
+	return role, nil
 }
 
-// GetPublicSurveys retrieves all public published surveys
-func (r *SurveyRepository) GetPublicSurveys(limit, offset int) ([]models.Survey, error) {
+// GetPublicSurveys retrieves all public published surveys visible to a user.
+// Targeted surveys are only included when the user's consented profile matches
+// their audience; anonymous visitors (nil userID) only see untargeted surveys.
+// Surveys past their expiry are excluded even before the scheduler closes them.
+func (r *SurveyRepository) GetPublicSurveys(userID *uuid.UUID, limit, offset int) ([]models.Survey, error) {
 	query := `
-		SELECT id, user_id, title, description, visibility, is_published,
-			include_in_datasets, published_count, theme, points_reward,
-			expires_at, response_count, created_at, updated_at, published_at
-		FROM surveys
-		WHERE visibility = 'public' AND is_published = true
-		ORDER BY published_at DESC
+		SELECT ` + surveyColumns + `
+		FROM surveys s
+		LEFT JOIN respondent_profiles p ON p.user_id = $3 AND p.consented = true
+		WHERE s.visibility = 'public' AND s.is_published = true
+			AND (s.expires_at IS NULL OR s.expires_at > NOW())
+			AND (s.target_audience IS NULL OR ` + audienceMatch("s.target_audience", "p") + `)
+		ORDER BY s.published_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.Query(query, limit, offset)
+	return r.querySurveys(query, limit, offset, userID)
+}
+
+// ErrRevisionConflict is returned when a survey was modified after it was read
+var ErrRevisionConflict = errors.New("survey has been modified by another request")
+
+// Update updates a survey if it is still at survey.Revision, then advances the
+// revision. It returns ErrRevisionConflict if the survey changed in between.
+func (r *SurveyRepository) Update(survey *models.Survey) error {
+	return updateSurvey(r.db, survey)
+}
+
+// ErrQuestionsAnswered is returned when a saved question list leaves out
+// questions that have answers, which would delete the answers with them
+var ErrQuestionsAnswered = errors.New("questions left out of the list have answers")
+
+// UpdateWithQuestions updates a survey like Update and, unless questions is
+// nil, saves its question list like SaveQuestions, in one transaction, so a
+// failed question save does not leave a new revision with the old questions.
+// Questions with answers are only dropped from the list when force is set.
+func (r *SurveyRepository) UpdateWithQuestions(survey *models.Survey, questions []models.Question, force bool) error {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query public surveys: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
-
-	var surveys []models.Survey
-	for rows.Next() {
-		var survey models.Survey
-		var themeJSON []byte
-
-		err := rows.Scan(
-			&survey.ID, &survey.UserID, &survey.Title, &survey.Description,
-			&survey.Visibility, &survey.IsPublished, &survey.IncludeInDatasets,
-			&survey.PublishedCount, &themeJSON, &survey.PointsReward,
-			&survey.ExpiresAt, &survey.ResponseCount, &survey.CreatedAt,
-			&survey.UpdatedAt, &survey.PublishedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan survey: %w", err)
-		}
+	defer tx.Rollback()
 
-		if len(themeJSON) > 0 {
-			survey.Theme = &models.SurveyTheme{}
-			json.Unmarshal(themeJSON, survey.Theme)
+	if err := updateSurvey(tx, survey); err != nil {
+		return err
+	}
+	if questions != nil {
+		if err := saveQuestions(tx, survey.ID, questions, force); err != nil {
+			return err
 		}
-
-		surveys = append(surveys, survey)
 	}
 
-	return surveys, nil
+	return tx.Commit()
 }
 
-// Update updates a survey
-func (r *SurveyRepository) Update(survey *models.Survey) error {
+// updateSurvey saves a survey at survey.Revision and advances its revision
+func updateSurvey(db queryRower, survey *models.Survey) error {
 	themeJSON, err := json.Marshal(survey.Theme)
 	if err != nil {
 		return fmt.Errorf("failed to marshal theme: %w", err)
 	}
 
+	audienceJSON, err := marshalTargetAudience(survey.TargetAudience)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE surveys SET
 			title = $2, description = $3, visibility = $4, is_published = $5,
 			include_in_datasets = $6, published_count = $7, theme = $8,
-			points_reward = $9, expires_at = $10, published_at = $11
-		WHERE id = $1
+			points_reward = $9, expires_at = $10, published_at = $11,
+			consolation_points = $12, disqualification_message = $13,
+			target_audience = $14, opens_at = $15, closed_at = $16,
+			organization_id = $17, revision = revision + 1
+		WHERE id = $1 AND revision = $18
+		RETURNING revision, updated_at
 	`
 
-	_, err = r.db.Exec(
+	err = db.QueryRow(
 		query,
 		survey.ID, survey.Title, survey.Description, survey.Visibility,
 		survey.IsPublished, survey.IncludeInDatasets, survey.PublishedCount,
 		themeJSON, survey.PointsReward, survey.ExpiresAt, survey.PublishedAt,
-	)
+		survey.ConsolationPoints, survey.DisqualificationMessage, audienceJSON,
+		survey.OpensAt, survey.ClosedAt, survey.OrganizationID, survey.Revision,
+	).Scan(&survey.Revision, &survey.UpdatedAt)
 
+	if err == sql.ErrNoRows {
+		return ErrRevisionConflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update survey: %w", err)
 	}
@@ -218,11 +388,45 @@ func (r *SurveyRepository) Delete(id uuid.UUID) error {
 	return nil
 }
 
+// questionColumns lists the question columns read by scanQuestion
+const questionColumns = `
+	id, survey_id, type, title, description, options, required,
+	points, max_rating, logic, sort_order, created_at, updated_at,
+	is_screener, eligibility
+`
+
+// scanQuestion reads a question selected with questionColumns
+func scanQuestion(row rowScanner) (*models.Question, error) {
+	var q models.Question
+	var optionsJSON, logicJSON, eligibilityJSON []byte
+
+	err := row.Scan(
+		&q.ID, &q.SurveyID, &q.Type, &q.Title, &q.Description,
+		&optionsJSON, &q.Required, &q.Points, &q.MaxRating,
+		&logicJSON, &q.SortOrder, &q.CreatedAt, &q.UpdatedAt,
+		&q.IsScreener, &eligibilityJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsJSON) > 0 {
+		json.Unmarshal(optionsJSON, &q.Options)
+	}
+	if len(logicJSON) > 0 {
+		json.Unmarshal(logicJSON, &q.Logic)
+	}
+	if len(eligibilityJSON) > 0 {
+		json.Unmarshal(eligibilityJSON, &q.Eligibility)
+	}
+
+	return &q, nil
+}
+
 // GetQuestions retrieves all questions for a survey
 func (r *SurveyRepository) GetQuestions(surveyID uuid.UUID) ([]models.Question, error) {
 	query := `
-		SELECT id, survey_id, type, title, description, options, required,
-			points, max_rating, logic, sort_order, created_at, updated_at
+		SELECT ` + questionColumns + `
 		FROM questions WHERE survey_id = $1
 		ORDER BY sort_order ASC
 	`
@@ -235,32 +439,19 @@ func (r *SurveyRepository) GetQuestions(surveyID uuid.UUID) ([]models.Question,
 
 	var questions []models.Question
 	for rows.Next() {
-		var q models.Question
-		var optionsJSON, logicJSON []byte
-
-		err := rows.Scan(
-			&q.ID, &q.SurveyID, &q.Type, &q.Title, &q.Description,
-			&optionsJSON, &q.Required, &q.Points, &q.MaxRating,
-			&logicJSON, &q.SortOrder, &q.CreatedAt, &q.UpdatedAt,
-		)
+		q, err := scanQuestion(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan question: %w", err)
 		}
-
-		if len(optionsJSON) > 0 {
-			json.Unmarshal(optionsJSON, &q.Options)
-		}
-		if len(logicJSON) > 0 {
-			json.Unmarshal(logicJSON, &q.Logic)
-		}
-
-		questions = append(questions, q)
+		questions = append(questions, *q)
 	}
 
 	return questions, nil
 }
 
-// SaveQuestions saves questions for a survey (replaces all)
+// SaveQuestions saves the full question list of a survey in order. Questions
+// keep their IDs (and answers) when resent; questions left out are deleted,
+// unless they have answers (ErrQuestionsAnswered).
 func (r *SurveyRepository) SaveQuestions(surveyID uuid.UUID, questions []models.Question) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -268,35 +459,94 @@ func (r *SurveyRepository) SaveQuestions(surveyID uuid.UUID, questions []models.
 	}
 	defer tx.Rollback()
 
-	// Delete existing questions
-	_, err = tx.Exec("DELETE FROM questions WHERE survey_id = $1", surveyID)
-	if err != nil {
-		return fmt.Errorf("failed to delete existing questions: %w", err)
+	if err := saveQuestions(tx, surveyID, questions, false); err != nil {
+		return err
 	}
 
-	// Insert new questions
+	return tx.Commit()
+}
+
+// saveQuestions replaces the question list of a survey in a transaction.
+// Without force it fails with ErrQuestionsAnswered rather than delete answers.
+func saveQuestions(tx *sql.Tx, surveyID uuid.UUID, questions []models.Question, force bool) error {
+	ids := make([]string, len(questions))
 	for i, q := range questions {
-		optionsJSON, _ := json.Marshal(q.Options)
-		logicJSON, _ := json.Marshal(q.Logic)
-
-		query := `
-			INSERT INTO questions (
-				id, survey_id, type, title, description, options, required,
-				points, max_rating, logic, sort_order
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		`
-
-		_, err = tx.Exec(
-			query,
-			q.ID, surveyID, q.Type, q.Title, q.Description,
-			optionsJSON, q.Required, q.Points, q.MaxRating, logicJSON, i,
-		)
+		ids[i] = q.ID.String()
+	}
+
+	if !force {
+		var answers int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM answers a
+			JOIN questions q ON q.id = a.question_id
+			WHERE q.survey_id = $1 AND NOT (q.id::text = ANY($2))
+		`, surveyID, pq.Array(ids)).Scan(&answers)
 		if err != nil {
-			return fmt.Errorf("failed to insert question: %w", err)
+			return fmt.Errorf("failed to count answers: %w", err)
+		}
+		if answers > 0 {
+			return ErrQuestionsAnswered
 		}
 	}
 
-	return tx.Commit()
+	// Delete questions that are no longer in the list
+	_, err := tx.Exec(
+		"DELETE FROM questions WHERE survey_id = $1 AND NOT (id::text = ANY($2))",
+		surveyID, pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete removed questions: %w", err)
+	}
+
+	for i := range questions {
+		questions[i].SurveyID = surveyID
+		questions[i].SortOrder = i
+		if err := upsertQuestion(tx, &questions[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertQuestion inserts a question or updates it in place, keeping its ID.
+// A question ID that belongs to another survey is rejected.
+func upsertQuestion(tx *sql.Tx, q *models.Question) error {
+	optionsJSON, _ := json.Marshal(q.Options)
+	logicJSON, _ := json.Marshal(q.Logic)
+	var eligibilityJSON []byte
+	if q.Eligibility != nil {
+		eligibilityJSON, _ = json.Marshal(q.Eligibility)
+	}
+
+	query := `
+		INSERT INTO questions (
+			id, survey_id, type, title, description, options, required,
+			points, max_rating, logic, sort_order, is_screener, eligibility
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			type = EXCLUDED.type, title = EXCLUDED.title, description = EXCLUDED.description,
+			options = EXCLUDED.options, required = EXCLUDED.required, points = EXCLUDED.points,
+			max_rating = EXCLUDED.max_rating, logic = EXCLUDED.logic, sort_order = EXCLUDED.sort_order,
+			is_screener = EXCLUDED.is_screener, eligibility = EXCLUDED.eligibility
+		WHERE questions.survey_id = EXCLUDED.survey_id
+		RETURNING created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		query,
+		q.ID, q.SurveyID, q.Type, q.Title, q.Description,
+		optionsJSON, q.Required, q.Points, q.MaxRating, logicJSON, q.SortOrder,
+		q.IsScreener, eligibilityJSON,
+	).Scan(&q.CreatedAt, &q.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("question %s belongs to another survey", q.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save question: %w", err)
+	}
+
+	return nil
 }
 
 // IncrementResponseCount increments the response count for a survey
@@ -310,3 +560,146 @@ func (r *SurveyRepository) IncrementResponseCount(surveyID uuid.UUID) error {
 	}
 	return nil
 }
+
+// marshalTargetAudience encodes a target audience, keeping nil as SQL NULL
+func marshalTargetAudience(audience *models.TargetAudience) ([]byte, error) {
+	if audience == nil {
+		return nil, nil
+	}
+	audienceJSON, err := json.Marshal(audience)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal target audience: %w", err)
+	}
+	return audienceJSON, nil
+}
+
+// Takedown unpublishes a survey and blocks its owner from republishing it.
+// Takedown and Restore record the audit entry in the same transaction.
+func (r *SurveyRepository) Takedown(id uuid.UUID, reason string, entry *models.AuditLog) error {
+	return r.moderate(entry, "failed to take down survey", `
+		UPDATE surveys SET is_published = false, opens_at = NULL,
+			taken_down_at = NOW(), takedown_reason = $2, revision = revision + 1
+		WHERE id = $1
+	`, id, reason)
+}
+
+// Restore lifts a takedown so the owner can publish the survey again
+func (r *SurveyRepository) Restore(id uuid.UUID, entry *models.AuditLog) error {
+	return r.moderate(entry, "failed to restore survey",
+		"UPDATE surveys SET taken_down_at = NULL, takedown_reason = NULL WHERE id = $1",
+		id,
+	)
+}
+
+// moderate runs a moderation update with its audit entry in one transaction
+func (r *SurveyRepository) moderate(entry *models.AuditLog, failure, query string, args ...interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("%s: %w", failure, err)
+	}
+	if err := insertAuditLog(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ScheduledSurvey identifies a survey whose publish state was flipped by the scheduler
+type ScheduledSurvey struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Title  string
+}
+
+// OpenScheduled publishes every survey whose scheduled opening time has passed
+func (r *SurveyRepository) OpenScheduled() ([]ScheduledSurvey, error) {
+	query := `
+		UPDATE surveys SET
+			is_published = true, published_at = NOW(),
+			published_count = published_count + 1,
+			opens_at = NULL, closed_at = NULL, revision = revision + 1
+		WHERE opens_at <= NOW() AND is_published = false
+			AND taken_down_at IS NULL
+			AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING id, user_id, title
+	`
+
+	return r.queryScheduled(query)
+}
+
+// CloseExpired unpublishes every published survey whose expiry has passed
+func (r *SurveyRepository) CloseExpired() ([]ScheduledSurvey, error) {
+	query := `
+		UPDATE surveys SET is_published = false, closed_at = NOW(), revision = revision + 1
+		WHERE is_published = true AND expires_at <= NOW()
+		RETURNING id, user_id, title
+	`
+
+	return r.queryScheduled(query)
+}
+
+func (r *SurveyRepository) queryScheduled(query string) ([]ScheduledSurvey, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled surveys: %w", err)
+	}
+	defer rows.Close()
+
+	var surveys []ScheduledSurvey
+	for rows.Next() {
+		var survey ScheduledSurvey
+		if err := rows.Scan(&survey.ID, &survey.UserID, &survey.Title); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled survey: %w", err)
+		}
+		surveys = append(surveys, survey)
+	}
+
+	return surveys, nil
+}
+
+// SaveRevision stores a snapshot of the survey at its current revision
+func (r *SurveyRepository) SaveRevision(survey *models.Survey, userID *uuid.UUID) error {
+	snapshot, err := json.Marshal(survey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal survey snapshot: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO survey_revisions (survey_id, revision, snapshot, user_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (survey_id, revision) DO UPDATE SET snapshot = EXCLUDED.snapshot, user_id = EXCLUDED.user_id
+	`, survey.ID, survey.Revision, snapshot, userID)
+	if err != nil {
+		return fmt.Errorf("failed to save survey revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevision retrieves the snapshot of a survey at a revision (nil if not stored)
+func (r *SurveyRepository) GetRevision(surveyID uuid.UUID, revision int) (*models.Survey, error) {
+	var snapshot []byte
+	err := r.db.QueryRow(
+		"SELECT snapshot FROM survey_revisions WHERE survey_id = $1 AND revision = $2",
+		surveyID, revision,
+	).Scan(&snapshot)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survey revision: %w", err)
+	}
+
+	survey := &models.Survey{}
+	if err := json.Unmarshal(snapshot, survey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal survey snapshot: %w", err)
+	}
+
+	return survey, nil
+}
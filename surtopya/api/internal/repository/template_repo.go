@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// TemplateRepository handles survey template database operations
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository creates a new TemplateRepository
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+const templateColumns = `
+	id, slug, category, title, COALESCE(description, ''), theme,
+	disqualification_message, questions, is_public, is_curated,
+	created_by, created_at, updated_at
+`
+
+func scanTemplate(row rowScanner) (*models.SurveyTemplate, error) {
+	t := &models.SurveyTemplate{}
+	var themeJSON, questionsJSON []byte
+
+	err := row.Scan(
+		&t.ID, &t.Slug, &t.Category, &t.Title, &t.Description, &themeJSON,
+		&t.DisqualificationMessage, &questionsJSON, &t.IsPublic, &t.IsCurated,
+		&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(themeJSON) > 0 {
+		t.Theme = &models.SurveyTheme{}
+		if err := json.Unmarshal(themeJSON, t.Theme); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal theme: %w", err)
+		}
+	}
+	if err := json.Unmarshal(questionsJSON, &t.Questions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template questions: %w", err)
+	}
+
+	return t, nil
+}
+
+func marshalTemplate(t *models.SurveyTemplate) (themeJSON, questionsJSON []byte, err error) {
+	if t.Theme != nil {
+		if themeJSON, err = json.Marshal(t.Theme); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal theme: %w", err)
+		}
+	}
+	questions := t.Questions
+	if questions == nil {
+		questions = []models.Question{}
+	}
+	if questionsJSON, err = json.Marshal(questions); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal template questions: %w", err)
+	}
+	return themeJSON, questionsJSON, nil
+}
+
+// Create creates a new template
+func (r *TemplateRepository) Create(t *models.SurveyTemplate) error {
+	themeJSON, questionsJSON, err := marshalTemplate(t)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO survey_templates (
+			id, slug, category, title, description, theme, disqualification_message,
+			questions, is_public, is_curated, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at
+	`,
+		t.ID, t.Slug, t.Category, t.Title, t.Description, themeJSON, t.DisqualificationMessage,
+		questionsJSON, t.IsPublic, t.IsCurated, t.CreatedBy,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertCurated creates or refreshes a curated template identified by its slug
+func (r *TemplateRepository) UpsertCurated(t *models.SurveyTemplate) error {
+	themeJSON, questionsJSON, err := marshalTemplate(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO survey_templates (
+			id, slug, category, title, description, theme, disqualification_message,
+			questions, is_public, is_curated
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true, true)
+		ON CONFLICT (slug) DO UPDATE SET
+			category = EXCLUDED.category, title = EXCLUDED.title,
+			description = EXCLUDED.description, theme = EXCLUDED.theme,
+			disqualification_message = EXCLUDED.disqualification_message,
+			questions = EXCLUDED.questions, is_public = true, is_curated = true
+	`,
+		t.ID, t.Slug, t.Category, t.Title, t.Description, themeJSON,
+		t.DisqualificationMessage, questionsJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert template: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a template by ID
+func (r *TemplateRepository) GetByID(id uuid.UUID) (*models.SurveyTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM survey_templates WHERE id = $1`
+
+	t, err := scanTemplate(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetVisible retrieves the public templates, plus the user's own templates when
+// userID is set, optionally filtered by category. Curated templates come first.
+func (r *TemplateRepository) GetVisible(userID *uuid.UUID, category string, limit, offset int) ([]models.SurveyTemplate, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM survey_templates
+		WHERE (is_public = true OR created_by = $1)
+			AND ($2 = '' OR category = $2)
+		ORDER BY is_curated DESC, title ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, userID, category, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.SurveyTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, nil
+}
+
+// GetCategories retrieves the categories of public templates with their counts
+func (r *TemplateRepository) GetCategories() ([]models.TemplateCategory, error) {
+	rows, err := r.db.Query(`
+		SELECT category, COUNT(*) FROM survey_templates
+		WHERE is_public = true
+		GROUP BY category ORDER BY category ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query template categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []models.TemplateCategory
+	for rows.Next() {
+		var tc models.TemplateCategory
+		if err := rows.Scan(&tc.Category, &tc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan template category: %w", err)
+		}
+		categories = append(categories, tc)
+	}
+
+	return categories, nil
+}
+
+// Delete deletes a template
+func (r *TemplateRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM survey_templates WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrNegativeBalance is returned when points would leave a balance negative
+var ErrNegativeBalance = errors.New("points balance cannot become negative")
+
+// UserRepository handles user database operations
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `
+	id, logto_user_id, email, display_name, avatar_url, points_balance,
+	is_pro, role, created_at, updated_at
+`
+
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID, &user.LogtoUserID, &user.Email, &user.DisplayName,
+		&user.AvatarURL, &user.PointsBalance, &user.IsPro, &user.Role,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Search searches users by email, display name or Logto user ID
+func (r *UserRepository) Search(searchQuery string, limit, offset int) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email ILIKE $1 OR display_name ILIKE $1 OR logto_user_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, "%"+searchQuery+"%", searchQuery, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	return users, nil
+}
+
+// UpdateRole changes the role of a user, recording the audit entry in the
+// same transaction
+func (r *UserRepository) UpdateRole(id uuid.UUID, role string, entry *models.AuditLog) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE users SET role = $2 WHERE id = $1", id, role); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if err := insertAuditLog(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GrantPoints adds points to a user's balance and records an admin_grant
+// transaction, with the audit entry (given the transaction ID) in the same
+// transaction. It returns ErrNegativeBalance if the balance would go below 0.
+func (r *UserRepository) GrantPoints(id uuid.UUID, amount int, description string, entry *models.AuditLog) (*models.PointsTransaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Checked in the update itself, so concurrent debits cannot overdraw
+	result, err := tx.Exec(
+		"UPDATE users SET points_balance = points_balance + $2 WHERE id = $1 AND points_balance + $2 >= 0",
+		id, amount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update points balance: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, ErrNegativeBalance
+	}
+
+	transaction := &models.PointsTransaction{
+		ID:          uuid.New(),
+		UserID:      id,
+		Amount:      amount,
+		Type:        "admin_grant",
+		Description: &description,
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO points_transactions (id, user_id, amount, type, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, transaction.ID, transaction.UserID, transaction.Amount, transaction.Type,
+		transaction.Description).Scan(&transaction.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record points transaction: %w", err)
+	}
+
+	if entry.Details == nil {
+		entry.Details = map[string]interface{}{}
+	}
+	entry.Details["transactionId"] = transaction.ID
+	if err := insertAuditLog(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// GetByEmail retrieves a user by email address (case-insensitive)
+func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+
+	user, err := scanUser(r.db.QueryRow(query, email))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
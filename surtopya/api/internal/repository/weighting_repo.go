@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// WeightingRepository handles survey weighting scheme database operations
+type WeightingRepository struct {
+	db *sql.DB
+}
+
+// NewWeightingRepository creates a new WeightingRepository
+func NewWeightingRepository(db *sql.DB) *WeightingRepository {
+	return &WeightingRepository{db: db}
+}
+
+// Get retrieves a survey's weighting scheme, or nil if it has none
+func (r *WeightingRepository) Get(surveyID uuid.UUID) (*models.WeightingScheme, error) {
+	query := `
+		SELECT survey_id, margins, max_weight, updated_by, created_at, updated_at
+		FROM survey_weighting
+		WHERE survey_id = $1
+	`
+
+	var scheme models.WeightingScheme
+	var marginsJSON []byte
+	var maxWeight sql.NullFloat64
+	var updatedBy uuid.NullUUID
+	err := r.db.QueryRow(query, surveyID).Scan(
+		&scheme.SurveyID, &marginsJSON, &maxWeight, &updatedBy, &scheme.CreatedAt, &scheme.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weighting scheme: %w", err)
+	}
+
+	if err := json.Unmarshal(marginsJSON, &scheme.Margins); err != nil {
+		return nil, fmt.Errorf("failed to decode weighting margins: %w", err)
+	}
+	if maxWeight.Valid {
+		scheme.MaxWeight = &maxWeight.Float64
+	}
+	if updatedBy.Valid {
+		scheme.UpdatedBy = &updatedBy.UUID
+	}
+
+	return &scheme, nil
+}
+
+// Save stores a survey's weighting scheme, replacing any previous one
+func (r *WeightingRepository) Save(scheme *models.WeightingScheme) error {
+	marginsJSON, err := json.Marshal(scheme.Margins)
+	if err != nil {
+		return fmt.Errorf("failed to encode weighting margins: %w", err)
+	}
+
+	query := `
+		INSERT INTO survey_weighting (survey_id, margins, max_weight, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (survey_id) DO UPDATE
+		SET margins = EXCLUDED.margins, max_weight = EXCLUDED.max_weight, updated_by = EXCLUDED.updated_by
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRow(
+		query, scheme.SurveyID, marginsJSON, scheme.MaxWeight, scheme.UpdatedBy,
+	).Scan(&scheme.CreatedAt, &scheme.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save weighting scheme: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a survey's weighting scheme
+func (r *WeightingRepository) Delete(surveyID uuid.UUID) error {
+	if _, err := r.db.Exec("DELETE FROM survey_weighting WHERE survey_id = $1", surveyID); err != nil {
+		return fmt.Errorf("failed to delete weighting scheme: %w", err)
+	}
+	return nil
+}
@@ -36,12 +36,19 @@ func SetupRouter() *gin.Engine {
 			surveys.DELETE("/:id", middleware.RequireAuth(), surveyHandler.DeleteSurvey)
 			surveys.POST("/:id/publish", middleware.RequireAuth(), surveyHandler.PublishSurvey)
 			surveys.POST("/:id/unpublish", middleware.RequireAuth(), surveyHandler.UnpublishSurvey)
+			surveys.GET("/:id/reach", middleware.RequireAuth(), surveyHandler.GetEstimatedReach)
+			surveys.POST("/:id/duplicate", middleware.RequireAuth(), surveyHandler.DuplicateSurvey)
+			surveys.GET("/:id/export", middleware.RequireAuth(), surveyHandler.ExportSurvey)
+			surveys.GET("/:id/print", middleware.RequireAuth(), surveyHandler.PrintSurvey)
+			surveys.POST("/import", middleware.RequireAuth(), surveyHandler.ImportSurvey)
+			surveys.POST("/import/:format", middleware.RequireAuth(), surveyHandler.ConvertSurvey)
 		}
 
 		// Response routes
 		responseHandler := handlers.NewResponseHandler()
 		responses := api.Group("/responses")
 		{
+			responses.POST("/resume", responseHandler.ResumeResponse)
 			responses.GET("/:id", responseHandler.GetResponse)
 			responses.POST("/:id/answers", responseHandler.SubmitAnswer)
 			responses.POST("/:id/submit", responseHandler.SubmitAllAnswers)
@@ -50,6 +57,108 @@ func SetupRouter() *gin.Engine {
 		// Survey response routes (nested under surveys)
 		api.POST("/surveys/:id/responses/start", responseHandler.StartResponse)
 		api.GET("/surveys/:id/responses", middleware.RequireAuth(), responseHandler.GetSurveyResponses)
+		api.GET("/surveys/:id/stats", middleware.RequireAuth(), responseHandler.GetSurveyStats)
+
+		// Survey report routes (nested under surveys)
+		reportHandler := handlers.NewReportHandler()
+		api.GET("/surveys/:id/report", middleware.RequireAuth(), reportHandler.GetSurveyReport)
+		api.GET("/surveys/:id/crosstab", middleware.RequireAuth(), reportHandler.GetCrosstab)
+		api.GET("/surveys/:id/funnel", middleware.RequireAuth(), reportHandler.GetFunnel)
+		api.GET("/surveys/:id/responses/stream", middleware.RequireAuth(), reportHandler.StreamResponses)
+		api.GET("/surveys/:id/responses/export", middleware.RequireAuth(), reportHandler.ExportResponses)
+		api.POST("/surveys/:id/export-jobs", middleware.RequireAuth(), reportHandler.CreateExportJob)
+		api.GET("/surveys/:id/export-jobs", middleware.RequireAuth(), reportHandler.GetExportJobs)
+		api.GET("/export-jobs/:jobId", middleware.RequireAuth(), reportHandler.GetExportJob)
+		api.GET("/export-jobs/:jobId/download", reportHandler.DownloadExport)
+		api.GET("/surveys/:id/summary", middleware.RequireAuth(), reportHandler.GetSummary)
+		api.POST("/surveys/:id/summary", middleware.RequireAuth(), reportHandler.GenerateSummary)
+		api.GET("/surveys/:id/compare", middleware.RequireAuth(), reportHandler.CompareSegments)
+		api.GET("/surveys/:id/reliability", middleware.RequireAuth(), reportHandler.GetReliability)
+		api.GET("/surveys/:id/correlations", middleware.RequireAuth(), reportHandler.GetCorrelations)
+		api.GET("/surveys/:id/weighting", middleware.RequireAuth(), reportHandler.GetWeighting)
+		api.PUT("/surveys/:id/weighting", middleware.RequireAuth(), reportHandler.SaveWeighting)
+		api.DELETE("/surveys/:id/weighting", middleware.RequireAuth(), reportHandler.DeleteWeighting)
+		api.GET("/surveys/:id/weighting/diagnostics", middleware.RequireAuth(), reportHandler.GetWeightingDiagnostics)
+
+		// Survey quota routes (nested under surveys)
+		quotaHandler := handlers.NewQuotaHandler()
+		api.GET("/surveys/:id/quotas", middleware.RequireAuth(), quotaHandler.GetQuotas)
+		api.POST("/surveys/:id/quotas", middleware.RequireAuth(), quotaHandler.CreateQuota)
+		api.PUT("/surveys/:id/quotas/:quotaId", middleware.RequireAuth(), quotaHandler.UpdateQuota)
+		api.DELETE("/surveys/:id/quotas/:quotaId", middleware.RequireAuth(), quotaHandler.DeleteQuota)
+
+		// Survey question routes (nested under surveys)
+		questionHandler := handlers.NewQuestionHandler()
+		api.POST("/surveys/:id/questions", middleware.RequireAuth(), questionHandler.AddQuestion)
+		api.PUT("/surveys/:id/questions/order", middleware.RequireAuth(), questionHandler.ReorderQuestions)
+		api.PATCH("/surveys/:id/questions/:questionId", middleware.RequireAuth(), questionHandler.PatchQuestion)
+		api.DELETE("/surveys/:id/questions/:questionId", middleware.RequireAuth(), questionHandler.DeleteQuestion)
+		api.POST("/surveys/:id/questions/:questionId/move", middleware.RequireAuth(), questionHandler.MoveQuestion)
+
+		// Open-text answer coding routes (nested under surveys)
+		codingHandler := handlers.NewCodingHandler()
+		api.GET("/surveys/:id/codes", middleware.RequireAuth(), codingHandler.GetCodes)
+		api.POST("/surveys/:id/codes", middleware.RequireAuth(), codingHandler.CreateCode)
+		api.PUT("/surveys/:id/codes/:codeId", middleware.RequireAuth(), codingHandler.UpdateCode)
+		api.DELETE("/surveys/:id/codes/:codeId", middleware.RequireAuth(), codingHandler.DeleteCode)
+		api.GET("/surveys/:id/questions/:questionId/answers", middleware.RequireAuth(), codingHandler.GetAnswers)
+		api.POST("/surveys/:id/questions/:questionId/codes", middleware.RequireAuth(), codingHandler.AssignCodes)
+		api.GET("/surveys/:id/questions/:questionId/code-suggestions", middleware.RequireAuth(), codingHandler.SuggestCodes)
+
+		// Survey collaborator and invitation routes (nested under surveys)
+		collaboratorHandler := handlers.NewCollaboratorHandler()
+		api.GET("/surveys/:id/collaborators", middleware.RequireAuth(), collaboratorHandler.GetCollaborators)
+		api.PUT("/surveys/:id/collaborators/:userId", middleware.RequireAuth(), collaboratorHandler.UpdateCollaborator)
+		api.DELETE("/surveys/:id/collaborators/:userId", middleware.RequireAuth(), collaboratorHandler.RemoveCollaborator)
+		api.GET("/surveys/:id/invitations", middleware.RequireAuth(), collaboratorHandler.GetInvitations)
+		api.POST("/surveys/:id/invitations", middleware.RequireAuth(), collaboratorHandler.CreateInvitation)
+		api.DELETE("/surveys/:id/invitations/:invitationId", middleware.RequireAuth(), collaboratorHandler.RevokeInvitation)
+		api.POST("/invitations/accept", middleware.RequireAuth(), collaboratorHandler.AcceptInvitation)
+
+		// Respondent profile routes
+		profileHandler := handlers.NewProfileHandler()
+		profile := api.Group("/profile", middleware.RequireAuth())
+		{
+			profile.GET("", profileHandler.GetProfile)
+			profile.PUT("", profileHandler.UpdateProfile)
+			profile.DELETE("", profileHandler.DeleteProfile)
+		}
+
+		// Notification routes
+		notificationHandler := handlers.NewNotificationHandler()
+		notifications := api.Group("/notifications", middleware.RequireAuth())
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.POST("/:id/read", notificationHandler.MarkNotificationRead)
+		}
+
+		// Organization (team workspace) routes
+		orgHandler := handlers.NewOrganizationHandler()
+		organizations := api.Group("/organizations", middleware.RequireAuth())
+		{
+			organizations.POST("", orgHandler.CreateOrganization)
+			organizations.GET("", orgHandler.GetMyOrganizations)
+			organizations.GET("/:id", orgHandler.GetOrganization)
+			organizations.PUT("/:id", orgHandler.UpdateOrganization)
+			organizations.DELETE("/:id", orgHandler.DeleteOrganization)
+			organizations.GET("/:id/surveys", orgHandler.GetOrganizationSurveys)
+			organizations.GET("/:id/members", orgHandler.GetMembers)
+			organizations.POST("/:id/members", orgHandler.AddMember)
+			organizations.PUT("/:id/members/:userId", orgHandler.UpdateMember)
+			organizations.DELETE("/:id/members/:userId", orgHandler.RemoveMember)
+		}
+
+		// Survey template library routes
+		templateHandler := handlers.NewTemplateHandler()
+		templates := api.Group("/templates")
+		{
+			templates.GET("", templateHandler.GetTemplates)
+			templates.GET("/categories", templateHandler.GetCategories)
+			templates.GET("/:id", templateHandler.GetTemplate)
+			templates.POST("/:id/instantiate", middleware.RequireAuth(), templateHandler.InstantiateTemplate)
+			templates.DELETE("/:id", middleware.RequireAuth(), templateHandler.DeleteTemplate)
+		}
+		api.POST("/surveys/:id/template", middleware.RequireAuth(), templateHandler.SaveAsTemplate)
 
 		// Dataset routes
 		datasetHandler := handlers.NewDatasetHandler()
@@ -60,6 +169,21 @@ func SetupRouter() *gin.Engine {
 			datasets.GET("/:id", datasetHandler.GetDataset)
 			datasets.POST("/:id/download", datasetHandler.DownloadDataset)
 		}
+
+		// Admin routes (moderators and admins only; every action is audited)
+		adminHandler := handlers.NewAdminHandler()
+		admin := api.Group("/admin", middleware.RequireAuth(), middleware.RequireRole("moderator"))
+		{
+			admin.GET("/users", adminHandler.SearchUsers)
+			admin.GET("/users/:id", adminHandler.GetUser)
+			admin.PUT("/users/:id/role", middleware.RequireRole("admin"), adminHandler.UpdateUserRole)
+			admin.POST("/users/:id/points", middleware.RequireRole("admin"), adminHandler.GrantPoints)
+			admin.POST("/surveys/:id/takedown", adminHandler.TakedownSurvey)
+			admin.POST("/surveys/:id/restore", adminHandler.RestoreSurvey)
+			admin.POST("/datasets/:id/deactivate", adminHandler.DeactivateDataset)
+			admin.POST("/datasets/:id/activate", adminHandler.ActivateDataset)
+			admin.GET("/audit-logs", middleware.RequireRole("admin"), adminHandler.GetAuditLogs)
+		}
 	}
 
 	return r
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Config holds scheduler configuration
+type Config struct {
+	Interval     time.Duration
+	AbandonAfter time.Duration
+}
+
+// LoadConfigFromEnv loads scheduler config from environment variables
+func LoadConfigFromEnv() Config {
+	return Config{
+		Interval:     getDuration("SCHEDULER_INTERVAL", time.Minute),
+		AbandonAfter: getDuration("RESPONSE_ABANDON_AFTER", 24*time.Hour),
+	}
+}
+
+// Scheduler runs periodic background jobs against the database
+type Scheduler struct {
+	cfg              Config
+	surveyRepo       *repository.SurveyRepository
+	responseRepo     *repository.ResponseRepository
+	notificationRepo *repository.NotificationRepository
+}
+
+// New creates a new Scheduler
+func New(db *sql.DB, cfg Config) *Scheduler {
+	return &Scheduler{
+		cfg:              cfg,
+		surveyRepo:       repository.NewSurveyRepository(db),
+		responseRepo:     repository.NewResponseRepository(db),
+		notificationRepo: repository.NewNotificationRepository(db),
+	}
+}
+
+// Run executes the jobs every interval until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	s.runOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce()
+		}
+	}
+}
+
+func (s *Scheduler) runOnce() {
+	if err := s.openScheduledSurveys(); err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+	if err := s.closeExpiredSurveys(); err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+	if err := s.sweepAbandonedResponses(); err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+}
+
+// openScheduledSurveys publishes surveys whose opening time has passed
+func (s *Scheduler) openScheduledSurveys() error {
+	opened, err := s.surveyRepo.OpenScheduled()
+	if err != nil {
+		return err
+	}
+
+	for _, survey := range opened {
+		s.notify(survey, "survey_opened", fmt.Sprintf("Survey \"%s\" is now open", survey.Title),
+			"Your survey was published at its scheduled opening time.")
+	}
+
+	return nil
+}
+
+// closeExpiredSurveys unpublishes surveys whose expiry has passed and notifies their owners
+func (s *Scheduler) closeExpiredSurveys() error {
+	closed, err := s.surveyRepo.CloseExpired()
+	if err != nil {
+		return err
+	}
+
+	for _, survey := range closed {
+		s.notify(survey, "survey_closed", fmt.Sprintf("Survey \"%s\" has closed", survey.Title),
+			"Your survey reached its closing time and is no longer accepting new responses.")
+	}
+
+	return nil
+}
+
+// sweepAbandonedResponses marks in-progress responses idle for longer than AbandonAfter as abandoned
+func (s *Scheduler) sweepAbandonedResponses() error {
+	count, err := s.responseRepo.MarkAbandoned(time.Now().Add(-s.cfg.AbandonAfter))
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Printf("Scheduler: marked %d stale responses as abandoned", count)
+	}
+
+	return nil
+}
+
+func (s *Scheduler) notify(survey repository.ScheduledSurvey, notificationType, title, message string) {
+	surveyID := survey.ID
+	notification := &models.Notification{
+		ID:       uuid.New(),
+		UserID:   survey.UserID,
+		Type:     notificationType,
+		Title:    title,
+		Message:  &message,
+		SurveyID: &surveyID,
+	}
+
+	if err := s.notificationRepo.Create(notification); err != nil {
+		log.Printf("Scheduler: %v", err)
+	}
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,83 @@
+package stats
+
+import "math"
+
+// ChiSquareTest is the result of Pearson's chi-square test of independence
+type ChiSquareTest struct {
+	Statistic        float64 `json:"statistic"`
+	DF               int     `json:"df"`
+	PValue           float64 `json:"pValue"`
+	CramersV         float64 `json:"cramersV"`
+	N                float64 `json:"n"`
+	MinExpected      float64 `json:"minExpected"`
+	LowExpectedCells int     `json:"lowExpectedCells"` // Cells with an expected count below 5, where the test is unreliable
+}
+
+// ChiSquareIndependence tests whether the rows and columns of a contingency
+// table are independent. Empty rows and columns are left out; at least two
+// rows and two columns must remain.
+func ChiSquareIndependence(table [][]float64) (*ChiSquareTest, error) {
+	table = nonEmpty(table)
+	if len(table) < 2 || len(table[0]) < 2 {
+		return nil, ErrInsufficientData
+	}
+
+	rows, cols := len(table), len(table[0])
+	rowTotals := make([]float64, rows)
+	colTotals := make([]float64, cols)
+	var n float64
+	for i, row := range table {
+		for j, count := range row {
+			rowTotals[i] += count
+			colTotals[j] += count
+			n += count
+		}
+	}
+
+	result := &ChiSquareTest{DF: (rows - 1) * (cols - 1), N: n, MinExpected: math.Inf(1)}
+	for i, row := range table {
+		for j, count := range row {
+			expected := rowTotals[i] * colTotals[j] / n
+			result.Statistic += (count - expected) * (count - expected) / expected
+			result.MinExpected = math.Min(result.MinExpected, expected)
+			if expected < 5 {
+				result.LowExpectedCells++
+			}
+		}
+	}
+
+	result.PValue = ChiSquareSF(result.Statistic, float64(result.DF))
+	result.CramersV = math.Sqrt(result.Statistic / (n * float64(min(rows, cols)-1)))
+	return result, nil
+}
+
+// nonEmpty returns the table without rows and columns that sum to zero
+func nonEmpty(table [][]float64) [][]float64 {
+	if len(table) == 0 {
+		return nil
+	}
+
+	keepCols := make([]int, 0, len(table[0]))
+	for j := range table[0] {
+		for _, row := range table {
+			if row[j] != 0 {
+				keepCols = append(keepCols, j)
+				break
+			}
+		}
+	}
+
+	var result [][]float64
+	for _, row := range table {
+		kept := make([]float64, len(keepCols))
+		var total float64
+		for k, j := range keepCols {
+			kept[k] = row[j]
+			total += row[j]
+		}
+		if total != 0 {
+			result = append(result, kept)
+		}
+	}
+	return result
+}
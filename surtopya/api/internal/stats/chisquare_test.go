@@ -0,0 +1,60 @@
+package stats
+
+import "testing"
+
+func TestChiSquareIndependence(t *testing.T) {
+	// chisq.test(M) for the party identification table in R's ?chisq.test:
+	// X-squared = 30.07, df = 2, p-value = 2.954e-07
+	table := [][]float64{
+		{762, 327, 468},
+		{484, 239, 477},
+	}
+	result, err := ChiSquareIndependence(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "statistic", result.Statistic, 30.07014909575467, 1e-9)
+	if result.DF != 2 {
+		t.Errorf("df = %d, want 2", result.DF)
+	}
+	assertClose(t, "p-value", result.PValue, 2.953589183211757e-07, 1e-13)
+	assertClose(t, "Cramer's V", result.CramersV, 0.1044358023564678, 1e-12)
+	assertClose(t, "n", result.N, 2757, 0)
+	if result.LowExpectedCells != 0 {
+		t.Errorf("low expected cells = %d, want 0", result.LowExpectedCells)
+	}
+}
+
+func TestChiSquareIndependenceDropsEmpty(t *testing.T) {
+	// An empty row and column do not change the test
+	table := [][]float64{
+		{762, 0, 327, 468},
+		{0, 0, 0, 0},
+		{484, 0, 239, 477},
+	}
+	result, err := ChiSquareIndependence(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "statistic", result.Statistic, 30.07014909575467, 1e-9)
+	if result.DF != 2 {
+		t.Errorf("df = %d, want 2", result.DF)
+	}
+}
+
+func TestChiSquareIndependenceErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		table [][]float64
+	}{
+		{"empty", nil},
+		{"one row", [][]float64{{1, 2, 3}}},
+		{"one non-empty column", [][]float64{{4, 0}, {5, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ChiSquareIndependence(tt.table)
+			assertErr(t, err, ErrInsufficientData)
+		})
+	}
+}
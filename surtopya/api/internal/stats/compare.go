@@ -0,0 +1,92 @@
+package stats
+
+import "math"
+
+// TTest is the result of Welch's two-sample t-test, which does not assume
+// equal variances
+type TTest struct {
+	Statistic  float64 `json:"statistic"`
+	DF         float64 `json:"df"`
+	PValue     float64 `json:"pValue"` // Two-sided
+	MeanA      float64 `json:"meanA"`
+	MeanB      float64 `json:"meanB"`
+	Difference float64 `json:"difference"` // MeanA - MeanB
+	CohensD    float64 `json:"cohensD"`    // Using the pooled standard deviation
+	NA         int     `json:"nA"`
+	NB         int     `json:"nB"`
+}
+
+// WelchTTest compares the means of two independent samples
+func WelchTTest(a, b []float64) (*TTest, error) {
+	if len(a) < 2 || len(b) < 2 {
+		return nil, ErrInsufficientData
+	}
+
+	na, nb := float64(len(a)), float64(len(b))
+	va, vb := variance(a), variance(b)
+	seA, seB := va/na, vb/nb
+	if seA+seB == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	result := &TTest{MeanA: mean(a), MeanB: mean(b), NA: len(a), NB: len(b)}
+	result.Difference = result.MeanA - result.MeanB
+	result.Statistic = result.Difference / math.Sqrt(seA+seB)
+	result.DF = (seA + seB) * (seA + seB) / (seA*seA/(na-1) + seB*seB/(nb-1))
+	result.PValue = math.Min(1, 2*StudentTSF(math.Abs(result.Statistic), result.DF))
+
+	pooled := math.Sqrt(((na-1)*va + (nb-1)*vb) / (na + nb - 2))
+	if pooled > 0 {
+		result.CohensD = result.Difference / pooled
+	}
+	return result, nil
+}
+
+// MannWhitneyTest is the result of the Mann–Whitney U test
+type MannWhitneyTest struct {
+	U          float64 `json:"u"` // U statistic of sample A
+	Z          float64 `json:"z"`
+	PValue     float64 `json:"pValue"`     // Two-sided
+	EffectSize float64 `json:"effectSize"` // r = |z| / sqrt(N)
+	NA         int     `json:"nA"`
+	NB         int     `json:"nB"`
+}
+
+// MannWhitneyU compares the distributions of two independent samples by
+// ranks, using the normal approximation with tie and continuity corrections
+func MannWhitneyU(a, b []float64) (*MannWhitneyTest, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	combined := append(append([]float64{}, a...), b...)
+	r, ties := ranks(combined)
+	var rankSumA float64
+	for i := range a {
+		rankSumA += r[i]
+	}
+
+	na, nb := float64(len(a)), float64(len(b))
+	n := na + nb
+	u := rankSumA - na*(na+1)/2
+	mu := na * nb / 2
+	sigma := math.Sqrt(na * nb / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return nil, ErrInsufficientData
+	}
+
+	// Continuity correction: move |U - mu| half a step towards zero
+	z := math.Max(0, math.Abs(u-mu)-0.5) / sigma
+	if u < mu {
+		z = -z
+	}
+
+	return &MannWhitneyTest{
+		U:          u,
+		Z:          z,
+		PValue:     math.Min(1, 2*NormalSF(math.Abs(z))),
+		EffectSize: math.Abs(z) / math.Sqrt(n),
+		NA:         len(a),
+		NB:         len(b),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package stats
+
+import "testing"
+
+func TestWelchTTest(t *testing.T) {
+	// t.test(extra ~ group, data = sleep) in R:
+	// t = -1.8608, df = 17.776, p-value = 0.07939
+	result, err := WelchTTest(sleepGroup1, sleepGroup2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "statistic", result.Statistic, -1.860813467486853, 1e-9)
+	assertClose(t, "df", result.DF, 17.7764735161785, 1e-9)
+	assertClose(t, "p-value", result.PValue, 0.07939414018735509, 1e-8)
+	assertClose(t, "meanA", result.MeanA, 0.75, 1e-12)
+	assertClose(t, "meanB", result.MeanB, 2.33, 1e-12)
+	assertClose(t, "difference", result.Difference, -1.58, 1e-12)
+	if result.NA != 10 || result.NB != 10 {
+		t.Errorf("n = %d, %d, want 10, 10", result.NA, result.NB)
+	}
+}
+
+func TestWelchTTestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+	}{
+		{"one value in a", []float64{1}, []float64{1, 2, 3}},
+		{"empty b", []float64{1, 2}, nil},
+		{"zero variance", []float64{2, 2, 2}, []float64{5, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WelchTTest(tt.a, tt.b)
+			assertErr(t, err, ErrInsufficientData)
+		})
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	// wilcox.test(extra ~ group, data = sleep, exact = FALSE) in R, which has
+	// ties and uses the continuity correction: W = 25.5, p-value = 0.06933
+	result, err := MannWhitneyU(sleepGroup1, sleepGroup2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "U", result.U, 25.5, 0)
+	assertClose(t, "z", result.Z, -1.816279061913682, 1e-9)
+	assertClose(t, "p-value", result.PValue, 0.06932757543362662, 1e-9)
+	assertClose(t, "effect size", result.EffectSize, 0.4061323448548542, 1e-9)
+}
+
+func TestMannWhitneyUErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+	}{
+		{"empty a", nil, []float64{1, 2}},
+		{"empty b", []float64{1, 2}, nil},
+		{"all tied", []float64{3, 3}, []float64{3, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MannWhitneyU(tt.a, tt.b)
+			assertErr(t, err, ErrInsufficientData)
+		})
+	}
+}
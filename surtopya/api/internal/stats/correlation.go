@@ -0,0 +1,72 @@
+package stats
+
+import "math"
+
+// Correlation methods
+const (
+	MethodPearson  = "pearson"
+	MethodSpearman = "spearman"
+)
+
+// Correlation is a correlation coefficient with its significance
+type Correlation struct {
+	Method string  `json:"method"`
+	R      float64 `json:"r"`
+	PValue float64 `json:"pValue"` // Two-sided, from the t distribution with n - 2 degrees of freedom
+	N      int     `json:"n"`
+}
+
+// Pearson returns the Pearson product-moment correlation of paired values
+func Pearson(x, y []float64) (*Correlation, error) {
+	r, err := pearson(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return correlation(MethodPearson, r, len(x)), nil
+}
+
+// Spearman returns Spearman's rank correlation of paired values, the Pearson
+// correlation of their ranks
+func Spearman(x, y []float64) (*Correlation, error) {
+	if len(x) != len(y) {
+		return nil, ErrInsufficientData
+	}
+	rx, _ := ranks(x)
+	ry, _ := ranks(y)
+	r, err := pearson(rx, ry)
+	if err != nil {
+		return nil, err
+	}
+	return correlation(MethodSpearman, r, len(x)), nil
+}
+
+func pearson(x, y []float64) (float64, error) {
+	if len(x) != len(y) || len(x) < 3 {
+		return 0, ErrInsufficientData
+	}
+
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, ErrInsufficientData
+	}
+	return sxy / math.Sqrt(sxx*syy), nil
+}
+
+func correlation(method string, r float64, n int) *Correlation {
+	result := &Correlation{Method: method, R: r, N: n}
+	df := float64(n - 2)
+	if math.Abs(r) >= 1 {
+		result.PValue = 0
+		return result
+	}
+	t := r * math.Sqrt(df/(1-r*r))
+	result.PValue = math.Min(1, 2*StudentTSF(math.Abs(t), df))
+	return result
+}
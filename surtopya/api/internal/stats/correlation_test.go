@@ -0,0 +1,63 @@
+package stats
+
+import "testing"
+
+func TestCorrelations(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  func(x, y []float64) (*Correlation, error)
+		x, y    []float64
+		r, pVal float64
+	}{
+		// cor.test(anscombe$x1, anscombe$y1) in R: r = 0.8164, p-value = 0.00217
+		{"pearson", Pearson, anscombeX1, anscombeY1, 0.81642051634484, 0.002169628873078746},
+		// cor(anscombe$x1, anscombe$y1, method = "spearman") in R; the p-value
+		// is the t approximation, as cor.test(..., exact = FALSE) gives
+		{"spearman", Spearman, anscombeX1, anscombeY1, 0.8181818181818182, 0.002083144840478684},
+		// With ties the ranks are averaged, as cor(..., method = "spearman") does
+		{"spearman ties", Spearman,
+			[]float64{1, 2, 2, 3, 4, 4, 5}, []float64{2, 1, 3, 3, 5, 4, 4},
+			0.8333333333333334, 0.01986805152337940},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(tt.x, tt.y)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "r", result.R, tt.r, 1e-12)
+			assertClose(t, "p-value", result.PValue, tt.pVal, 1e-8)
+			if result.N != len(tt.x) {
+				t.Errorf("n = %d, want %d", result.N, len(tt.x))
+			}
+		})
+	}
+}
+
+func TestPerfectCorrelation(t *testing.T) {
+	result, err := Pearson([]float64{1, 2, 3, 4}, []float64{8, 6, 4, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "r", result.R, -1, 1e-12)
+	assertClose(t, "p-value", result.PValue, 0, 0)
+}
+
+func TestCorrelationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+	}{
+		{"mismatched lengths", []float64{1, 2, 3, 4}, []float64{1, 2, 3}},
+		{"two pairs", []float64{1, 2}, []float64{2, 1}},
+		{"zero variance", []float64{5, 5, 5, 5}, []float64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Pearson(tt.x, tt.y)
+			assertErr(t, err, ErrInsufficientData)
+			_, err = Spearman(tt.x, tt.y)
+			assertErr(t, err, ErrInsufficientData)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package stats
+
+import "testing"
+
+func TestNormalQuantile(t *testing.T) {
+	// qnorm(p) in R
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0.5, 0},
+		{0.975, 1.959963984540054},
+		{0.995, 2.575829303548901},
+		{0.05, -1.644853626951473},
+		{0.9, 1.281551565544601},
+	}
+	for _, tt := range tests {
+		assertClose(t, "NormalQuantile", NormalQuantile(tt.p), tt.want, 1e-9)
+	}
+}
+
+func TestNormalSF(t *testing.T) {
+	// pnorm(z, lower.tail = FALSE) in R
+	tests := []struct {
+		z    float64
+		want float64
+	}{
+		{0, 0.5},
+		{1.959963984540054, 0.025},
+		{-1, 0.8413447460685429},
+		{3, 0.001349898031630095},
+	}
+	for _, tt := range tests {
+		assertClose(t, "NormalSF", NormalSF(tt.z), tt.want, 1e-12)
+	}
+}
+
+func TestStudentTQuantile(t *testing.T) {
+	// qt(p, df) in R
+	tests := []struct {
+		p, df float64
+		want  float64
+	}{
+		{0.975, 1, 12.70620473617471},
+		{0.975, 9, 2.262157162740992},
+		{0.975, 30, 2.042272456301238},
+		{0.95, 5, 2.015048372669157},
+		{0.025, 9, -2.262157162740992},
+		{0.5, 4, 0},
+	}
+	for _, tt := range tests {
+		assertClose(t, "StudentTQuantile", StudentTQuantile(tt.p, tt.df), tt.want, 1e-8)
+	}
+}
+
+func TestStudentTSF(t *testing.T) {
+	// pt(t, df, lower.tail = FALSE) in R
+	tests := []struct {
+		t, df float64
+		want  float64
+	}{
+		{0, 7, 0.5},
+		{2.262157162740992, 9, 0.025},
+		{12.70620473617471, 1, 0.025},
+		{-2.015048372669157, 5, 0.95},
+	}
+	for _, tt := range tests {
+		assertClose(t, "StudentTSF", StudentTSF(tt.t, tt.df), tt.want, 1e-10)
+	}
+}
+
+func TestChiSquareSF(t *testing.T) {
+	// pchisq(x, df, lower.tail = FALSE) in R
+	tests := []struct {
+		x, df float64
+		want  float64
+	}{
+		{0, 3, 1},
+		{3.841458820694124, 1, 0.05},
+		{5.991464547107979, 2, 0.05},
+		{13.27670413598762, 4, 0.01},
+		{18.30703805327515, 10, 0.05},
+		{30.07014909575467, 2, 2.953589183211757e-07},
+	}
+	for _, tt := range tests {
+		assertClose(t, "ChiSquareSF", ChiSquareSF(tt.x, tt.df), tt.want, 1e-10)
+	}
+}
@@ -0,0 +1,103 @@
+package stats
+
+import "math"
+
+// Interval is a point estimate with a confidence interval
+type Interval struct {
+	Estimate   float64 `json:"estimate"`
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ProportionCI returns the Wilson score interval of a proportion, which stays
+// within [0, 1] and behaves well for small samples and extreme proportions
+func ProportionCI(successes, n int, confidence float64) (*Interval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence
+	}
+	if n == 0 || successes < 0 || successes > n {
+		return nil, ErrInsufficientData
+	}
+
+	return WeightedProportionCI(float64(successes)/float64(n), float64(n), confidence)
+}
+
+// MeanCI returns the t-based confidence interval of a mean
+func MeanCI(values []float64, confidence float64) (*Interval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence
+	}
+	if len(values) < 2 {
+		return nil, ErrInsufficientData
+	}
+
+	return MeanCIFromSummary(mean(values), math.Sqrt(variance(values)), len(values), confidence)
+}
+
+// MeanCIFromSummary returns the t-based confidence interval of a mean from
+// the sample mean, sample standard deviation and size
+func MeanCIFromSummary(m, sd float64, n int, confidence float64) (*Interval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence
+	}
+	if n < 2 {
+		return nil, ErrInsufficientData
+	}
+
+	margin := StudentTQuantile(1-(1-confidence)/2, float64(n-1)) * sd / math.Sqrt(float64(n))
+	return &Interval{Estimate: m, Lower: m - margin, Upper: m + margin, Confidence: confidence}, nil
+}
+
+// WeightedProportionCI returns the Wilson score interval of a weighted
+// proportion, taking the sample size as the Kish effective size of the weights
+func WeightedProportionCI(p, effectiveN, confidence float64) (*Interval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence
+	}
+	if effectiveN <= 0 || p < 0 || p > 1 {
+		return nil, ErrInsufficientData
+	}
+
+	z := NormalQuantile(1 - (1-confidence)/2)
+	z2n := z * z / effectiveN
+	center := (p + z2n/2) / (1 + z2n)
+	margin := z * math.Sqrt(p*(1-p)/effectiveN+z2n/(4*effectiveN)) / (1 + z2n)
+
+	return &Interval{
+		Estimate:   p,
+		Lower:      math.Max(0, center-margin),
+		Upper:      math.Min(1, center+margin),
+		Confidence: confidence,
+	}, nil
+}
+
+// WeightedMeanCI returns the t-based confidence interval of a weighted mean,
+// with the Kish effective sample size in place of n
+func WeightedMeanCI(values, weights []float64, confidence float64) (*Interval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, ErrInvalidConfidence
+	}
+	if len(values) != len(weights) {
+		return nil, ErrInsufficientData
+	}
+
+	var sum, weighted float64
+	for i, v := range values {
+		sum += weights[i]
+		weighted += weights[i] * v
+	}
+	n := EffectiveN(weights)
+	if sum == 0 || n <= 1 {
+		return nil, ErrInsufficientData
+	}
+	m := weighted / sum
+
+	var squares float64
+	for i, v := range values {
+		squares += weights[i] * (v - m) * (v - m)
+	}
+	sd := math.Sqrt(squares / sum * n / (n - 1))
+	margin := StudentTQuantile(1-(1-confidence)/2, n-1) * sd / math.Sqrt(n)
+	return &Interval{Estimate: m, Lower: m - margin, Upper: m + margin, Confidence: confidence}, nil
+}
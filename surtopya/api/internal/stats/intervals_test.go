@@ -0,0 +1,78 @@
+package stats
+
+import "testing"
+
+func TestProportionCI(t *testing.T) {
+	// prop.test(x, n, correct = FALSE)$conf.int in R, the Wilson interval
+	tests := []struct {
+		name         string
+		successes, n int
+		lower, upper float64
+	}{
+		{"15 of 50", 15, 50, 0.1910355350088095, 0.437503504644534},
+		{"none", 0, 10, 0, 0.2775327998628892},
+		{"all", 10, 10, 0.7224672001371107, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci, err := ProportionCI(tt.successes, tt.n, 0.95)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "estimate", ci.Estimate, float64(tt.successes)/float64(tt.n), 1e-12)
+			assertClose(t, "lower", ci.Lower, tt.lower, 1e-9)
+			assertClose(t, "upper", ci.Upper, tt.upper, 1e-9)
+		})
+	}
+}
+
+func TestProportionCIErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		successes, n int
+		confidence   float64
+		want         error
+	}{
+		{"empty", 0, 0, 0.95, ErrInsufficientData},
+		{"more successes than n", 11, 10, 0.95, ErrInsufficientData},
+		{"negative successes", -1, 10, 0.95, ErrInsufficientData},
+		{"confidence 1", 5, 10, 1, ErrInvalidConfidence},
+		{"confidence 0", 5, 10, 0, ErrInvalidConfidence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ProportionCI(tt.successes, tt.n, tt.confidence)
+			assertErr(t, err, tt.want)
+		})
+	}
+}
+
+func TestMeanCI(t *testing.T) {
+	// t.test(sleep$extra[sleep$group == 1])$conf.int in R
+	ci, err := MeanCI(sleepGroup1, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "estimate", ci.Estimate, 0.75, 1e-12)
+	assertClose(t, "lower", ci.Lower, -0.5297804134938648, 1e-8)
+	assertClose(t, "upper", ci.Upper, 2.029780413493865, 1e-8)
+}
+
+func TestMeanCIErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		confidence float64
+		want       error
+	}{
+		{"empty", nil, 0.95, ErrInsufficientData},
+		{"one value", []float64{3}, 0.95, ErrInsufficientData},
+		{"invalid confidence", []float64{1, 2, 3}, 1.5, ErrInvalidConfidence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MeanCI(tt.values, tt.confidence)
+			assertErr(t, err, tt.want)
+		})
+	}
+}
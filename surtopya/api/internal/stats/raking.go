@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrUnreachableTarget is returned when a category with a positive target
+// share has no units to carry its weight
+var ErrUnreachableTarget = errors.New("a category with a target share has no units")
+
+// Raking defaults
+const (
+	DefaultRakeIterations = 100
+	DefaultRakeTolerance  = 1e-6
+)
+
+// RakeOptions tunes raking. Zero values use the defaults.
+type RakeOptions struct {
+	MaxIterations int
+	Tolerance     float64 // Largest allowed gap between a weighted and a target share
+	MaxWeight     float64 // Caps weights at this multiple of the mean weight, 0 for no cap
+}
+
+// Raking is the result of raking: one weight per unit, averaging 1
+type Raking struct {
+	Weights      []float64 `json:"-"`
+	Iterations   int       `json:"iterations"`
+	Converged    bool      `json:"converged"`
+	EffectiveN   float64   `json:"effectiveN"`   // Kish effective sample size
+	DesignEffect float64   `json:"designEffect"` // Kish design effect of the weights, 1 + CV^2
+	MinWeight    float64   `json:"minWeight"`
+	MaxWeight    float64   `json:"maxWeight"`
+}
+
+// Rake computes weights by iterative proportional fitting so that the
+// weighted shares of each variable's categories match its targets.
+// categories[v][i] is the category of unit i on variable v, or -1 if the unit
+// has none; targets[v][c] is the target share of category c of variable v,
+// scaled to sum to 1. Units without a category on a variable are left out of
+// that variable's adjustment. With MaxWeight set, weights are trimmed after
+// each pass, which can keep the margins from being met exactly.
+func Rake(categories [][]int, targets [][]float64, opts RakeOptions) (*Raking, error) {
+	if len(categories) == 0 || len(categories) != len(targets) || len(categories[0]) == 0 {
+		return nil, ErrInsufficientData
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = DefaultRakeIterations
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultRakeTolerance
+	}
+
+	n := len(categories[0])
+	shares := make([][]float64, len(targets))
+	for v, target := range targets {
+		if len(categories[v]) != n {
+			return nil, ErrInsufficientData
+		}
+		shares[v] = normalizeShares(target)
+		if shares[v] == nil {
+			return nil, ErrInsufficientData
+		}
+		units := make([]int, len(target))
+		for _, c := range categories[v] {
+			if c >= len(target) {
+				return nil, ErrInsufficientData
+			}
+			if c >= 0 {
+				units[c]++
+			}
+		}
+		for c, share := range shares[v] {
+			if share > 0 && units[c] == 0 {
+				return nil, ErrUnreachableTarget
+			}
+		}
+	}
+
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	result := &Raking{}
+	for result.Iterations < opts.MaxIterations {
+		result.Iterations++
+		for v := range categories {
+			totals, sum := categoryTotals(categories[v], weights, len(shares[v]))
+			for i, c := range categories[v] {
+				if c >= 0 && totals[c] > 0 {
+					weights[i] *= shares[v][c] * sum / totals[c]
+				}
+			}
+		}
+		if opts.MaxWeight > 0 {
+			trimWeights(weights, opts.MaxWeight)
+		}
+
+		if rakingGap(categories, shares, weights) <= opts.Tolerance {
+			result.Converged = true
+			break
+		}
+	}
+
+	scaleToMean(weights, 1)
+	result.Weights = weights
+	result.EffectiveN = EffectiveN(weights)
+	result.DesignEffect = float64(n) / result.EffectiveN
+	result.MinWeight, result.MaxWeight = math.Inf(1), 0
+	for _, w := range weights {
+		result.MinWeight = math.Min(result.MinWeight, w)
+		result.MaxWeight = math.Max(result.MaxWeight, w)
+	}
+	return result, nil
+}
+
+// EffectiveN returns Kish's effective sample size of weights,
+// (sum w)^2 / sum w^2
+func EffectiveN(weights []float64) float64 {
+	var sum, squares float64
+	for _, w := range weights {
+		sum += w
+		squares += w * w
+	}
+	if squares == 0 {
+		return 0
+	}
+	return sum * sum / squares
+}
+
+// normalizeShares scales non-negative shares to sum to 1, or returns nil if
+// they cannot be
+func normalizeShares(target []float64) []float64 {
+	var sum float64
+	for _, t := range target {
+		if t < 0 || math.IsNaN(t) || math.IsInf(t, 0) {
+			return nil
+		}
+		sum += t
+	}
+	if sum == 0 {
+		return nil
+	}
+	shares := make([]float64, len(target))
+	for c, t := range target {
+		shares[c] = t / sum
+	}
+	return shares
+}
+
+// categoryTotals sums the weights of each category and of all units with one
+func categoryTotals(categories []int, weights []float64, k int) ([]float64, float64) {
+	totals := make([]float64, k)
+	var sum float64
+	for i, c := range categories {
+		if c >= 0 {
+			totals[c] += weights[i]
+			sum += weights[i]
+		}
+	}
+	return totals, sum
+}
+
+// rakingGap returns the largest gap between a weighted and a target share
+func rakingGap(categories [][]int, shares [][]float64, weights []float64) float64 {
+	gap := 0.0
+	for v := range categories {
+		totals, sum := categoryTotals(categories[v], weights, len(shares[v]))
+		if sum == 0 {
+			continue
+		}
+		for c, share := range shares[v] {
+			gap = math.Max(gap, math.Abs(totals[c]/sum-share))
+		}
+	}
+	return gap
+}
+
+// trimWeights caps weights at limit times their mean, scaling the rest up so
+// the total is kept. Capping can push others over, so it repeats a few times.
+func trimWeights(weights []float64, limit float64) {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	for round := 0; round < 10; round++ {
+		capValue := limit * total / float64(len(weights))
+		var capped, free float64
+		for i, w := range weights {
+			if w >= capValue {
+				weights[i] = capValue
+				capped += capValue
+			} else {
+				free += w
+			}
+		}
+		if free == 0 || capped == 0 {
+			return
+		}
+		scale := (total - capped) / free
+		over := false
+		for i, w := range weights {
+			if w < capValue {
+				weights[i] = w * scale
+				over = over || weights[i] > capValue
+			}
+		}
+		if !over {
+			return
+		}
+	}
+}
+
+// scaleToMean scales weights so they average m
+func scaleToMean(weights []float64, m float64) {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return
+	}
+	scale := m * float64(len(weights)) / sum
+	for i := range weights {
+		weights[i] *= scale
+	}
+}
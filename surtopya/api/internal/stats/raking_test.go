@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEffectiveN(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		want    float64
+	}{
+		{"equal", []float64{1, 1, 1, 1}, 4},
+		{"scaled", []float64{3, 3, 3}, 3},
+		{"unequal", []float64{1, 2, 3}, 36.0 / 14},
+		{"empty", nil, 0},
+		{"zero", []float64{0, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "EffectiveN", EffectiveN(tt.weights), tt.want, 1e-12)
+		})
+	}
+}
+
+func TestRakeSingleVariable(t *testing.T) {
+	// With one variable, raking is post-stratification: each unit is
+	// weighted by its category's target share over its sample share
+	categories := [][]int{{0, 0, 0, 1}}
+	targets := [][]float64{{50, 50}}
+	result, err := Rake(categories, targets, RakeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{2.0 / 3, 2.0 / 3, 2.0 / 3, 2}
+	for i := range want {
+		assertClose(t, "weight", result.Weights[i], want[i], 1e-9)
+	}
+	if !result.Converged {
+		t.Error("raking did not converge")
+	}
+	assertClose(t, "effective n", result.EffectiveN, 3, 1e-9)
+	assertClose(t, "design effect", result.DesignEffect, 4.0/3, 1e-9)
+	assertClose(t, "min weight", result.MinWeight, 2.0/3, 1e-9)
+	assertClose(t, "max weight", result.MaxWeight, 2, 1e-9)
+}
+
+func TestRakeTwoVariables(t *testing.T) {
+	// Gender and age of 10 units, raked to 50/50 and 30/70 margins. With a
+	// missing age, that unit is left out of the age adjustment.
+	categories := [][]int{
+		{0, 0, 0, 0, 0, 0, 1, 1, 1, 1},
+		{0, 0, 0, 0, 1, 1, 0, 1, 1, -1},
+	}
+	targets := [][]float64{{0.5, 0.5}, {0.3, 0.7}}
+	result, err := Rake(categories, targets, RakeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Converged {
+		t.Fatal("raking did not converge")
+	}
+
+	for v := range categories {
+		totals, sum := categoryTotals(categories[v], result.Weights, len(targets[v]))
+		for c, target := range targets[v] {
+			assertClose(t, "weighted share", totals[c]/sum, target, DefaultRakeTolerance)
+		}
+	}
+
+	var total float64
+	for _, w := range result.Weights {
+		total += w
+	}
+	assertClose(t, "mean weight", total/float64(len(result.Weights)), 1, 1e-12)
+	assertClose(t, "effective n", result.EffectiveN, EffectiveN(result.Weights), 1e-12)
+	assertClose(t, "design effect", result.DesignEffect, 10/result.EffectiveN, 1e-12)
+}
+
+func TestRakeMaxWeight(t *testing.T) {
+	categories := [][]int{{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}
+	targets := [][]float64{{0.5, 0.5}}
+	result, err := Rake(categories, targets, RakeOptions{MaxWeight: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Untrimmed, the last unit would carry a weight of 5
+	if result.MaxWeight > 3+1e-9 {
+		t.Errorf("max weight = %g, want at most 3", result.MaxWeight)
+	}
+	if result.Converged {
+		t.Error("trimmed raking should not meet the margins")
+	}
+}
+
+func TestRakeErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories [][]int
+		targets    [][]float64
+		want       error
+	}{
+		{"no variables", nil, nil, ErrInsufficientData},
+		{"no units", [][]int{{}}, [][]float64{{1}}, ErrInsufficientData},
+		{"mismatched variables", [][]int{{0, 1}}, [][]float64{{1, 1}, {1, 1}}, ErrInsufficientData},
+		{"mismatched units", [][]int{{0, 1}, {0}}, [][]float64{{1, 1}, {1, 1}}, ErrInsufficientData},
+		{"category out of range", [][]int{{0, 2}}, [][]float64{{1, 1}}, ErrInsufficientData},
+		{"negative target", [][]int{{0, 1}}, [][]float64{{1, -1}}, ErrInsufficientData},
+		{"zero targets", [][]int{{0, 1}}, [][]float64{{0, 0}}, ErrInsufficientData},
+		{"unreachable target", [][]int{{0, 0}}, [][]float64{{1, 1}}, ErrUnreachableTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Rake(tt.categories, tt.targets, RakeOptions{})
+			assertErr(t, err, tt.want)
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"odd", []float64{3, 1, 2}, 2},
+		{"even", []float64{4, 1, 3, 2}, 2.5},
+		{"single", []float64{7}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "Median", Median(tt.values), tt.want, 0)
+		})
+	}
+
+	if !math.IsNaN(Median(nil)) {
+		t.Error("Median(nil) should be NaN")
+	}
+}
+
+func TestWeightedMeanCI(t *testing.T) {
+	// Equal weights give the unweighted interval
+	weights := make([]float64, len(sleepGroup1))
+	for i := range weights {
+		weights[i] = 2
+	}
+	ci, err := WeightedMeanCI(sleepGroup1, weights, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "lower", ci.Lower, -0.5297804134938648, 1e-8)
+	assertClose(t, "upper", ci.Upper, 2.029780413493865, 1e-8)
+
+	_, err = WeightedMeanCI([]float64{1, 2}, []float64{1}, 0.95)
+	assertErr(t, err, ErrInsufficientData)
+	_, err = WeightedMeanCI([]float64{1, 2}, []float64{0, 0}, 0.95)
+	assertErr(t, err, ErrInsufficientData)
+}
@@ -0,0 +1,87 @@
+package stats
+
+import "math"
+
+// Reliability is the internal consistency of a set of items
+type Reliability struct {
+	Alpha                 float64   `json:"alpha"` // Cronbach's alpha
+	Items                 int       `json:"items"`
+	N                     int       `json:"n"`
+	AlphaIfDeleted        []float64 `json:"alphaIfDeleted"`        // Alpha without each item
+	ItemTotalCorrelations []float64 `json:"itemTotalCorrelations"` // Corrected: each item against the sum of the others
+}
+
+// CronbachAlpha computes Cronbach's alpha of item scores, one row per
+// respondent and one column per item; rows must be complete
+func CronbachAlpha(rows [][]float64) (*Reliability, error) {
+	if len(rows) < 2 || len(rows[0]) < 2 {
+		return nil, ErrInsufficientData
+	}
+
+	k := len(rows[0])
+	result := &Reliability{Items: k, N: len(rows)}
+	alpha, ok := cronbachAlpha(rows, -1)
+	if !ok {
+		return nil, ErrInsufficientData
+	}
+	result.Alpha = alpha
+
+	for item := 0; item < k; item++ {
+		without := math.NaN()
+		if k > 2 {
+			without, _ = cronbachAlpha(rows, item)
+		}
+		result.AlphaIfDeleted = append(result.AlphaIfDeleted, nanToZero(without))
+
+		scores := make([]float64, len(rows))
+		rest := make([]float64, len(rows))
+		for i, row := range rows {
+			scores[i] = row[item]
+			for j, v := range row {
+				if j != item {
+					rest[i] += v
+				}
+			}
+		}
+		r := 0.0
+		if c, err := Pearson(scores, rest); err == nil {
+			r = c.R
+		}
+		result.ItemTotalCorrelations = append(result.ItemTotalCorrelations, r)
+	}
+
+	return result, nil
+}
+
+// cronbachAlpha computes alpha leaving out the item skip (or none if -1)
+func cronbachAlpha(rows [][]float64, skip int) (float64, bool) {
+	var itemVariances float64
+	items := 0
+	totals := make([]float64, len(rows))
+	for item := range rows[0] {
+		if item == skip {
+			continue
+		}
+		scores := make([]float64, len(rows))
+		for i, row := range rows {
+			scores[i] = row[item]
+			totals[i] += row[item]
+		}
+		itemVariances += variance(scores)
+		items++
+	}
+
+	totalVariance := variance(totals)
+	if items < 2 || totalVariance == 0 {
+		return math.NaN(), false
+	}
+	k := float64(items)
+	return k / (k - 1) * (1 - itemVariances/totalVariance), true
+}
+
+func nanToZero(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,49 @@
+package stats
+
+import "testing"
+
+func TestCronbachAlpha(t *testing.T) {
+	// psych::alpha(rows) in R: raw_alpha, alpha if an item is dropped and
+	// r.drop, the corrected item-total correlation
+	rows := [][]float64{
+		{4, 5, 4, 3},
+		{3, 3, 4, 2},
+		{5, 5, 5, 4},
+		{2, 3, 2, 2},
+		{4, 4, 5, 3},
+		{3, 4, 3, 3},
+	}
+	result, err := CronbachAlpha(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "alpha", result.Alpha, 0.9266136162687887, 1e-12)
+	if result.Items != 4 || result.N != 6 {
+		t.Errorf("items, n = %d, %d, want 4, 6", result.Items, result.N)
+	}
+
+	alphaIfDeleted := []float64{0.8457446808510638, 0.9141630901287555, 0.945, 0.9072580645161289}
+	itemTotal := []float64{0.9902815964320999, 0.8023570427399106, 0.7509343773089566, 0.8624575450871765}
+	for i := range alphaIfDeleted {
+		assertClose(t, "alpha if deleted", result.AlphaIfDeleted[i], alphaIfDeleted[i], 1e-12)
+		assertClose(t, "item-total correlation", result.ItemTotalCorrelations[i], itemTotal[i], 1e-12)
+	}
+}
+
+func TestCronbachAlphaErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]float64
+	}{
+		{"no rows", nil},
+		{"one respondent", [][]float64{{1, 2, 3}}},
+		{"one item", [][]float64{{1}, {2}, {3}}},
+		{"zero total variance", [][]float64{{1, 3}, {2, 2}, {3, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CronbachAlpha(tt.rows)
+			assertErr(t, err, ErrInsufficientData)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Package stats implements the statistical tests used by survey reports.
+// Functions take plain float64 data and return results with JSON tags so
+// handlers can return them as they are.
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInsufficientData is returned when there is too little data for a test
+var ErrInsufficientData = errors.New("insufficient data")
+
+// ErrInvalidConfidence is returned for a confidence level outside (0, 1)
+var ErrInvalidConfidence = errors.New("confidence level must be between 0 and 1")
+
+// mean returns the arithmetic mean of values
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// variance returns the sample variance of values (n - 1 denominator)
+func variance(values []float64) float64 {
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(values)-1)
+}
+
+// ranks returns the ranks of values, giving ties their average rank, and the
+// tie correction term sum(t^3 - t) over groups of t tied values
+func ranks(values []float64) ([]float64, float64) {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	result := make([]float64, len(values))
+	var ties float64
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[order[k]] = rank
+		}
+		if t := float64(j - i + 1); t > 1 {
+			ties += t*t*t - t
+		}
+		i = j + 1
+	}
+	return result, ties
+}
+
+// Median returns the median of values, or NaN if there are none
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Reference values come from R (stats package) and were cross-checked with
+// SciPy. The sleep data is R's datasets::sleep, split by group; the anscombe
+// data is the first pair of R's datasets::anscombe.
+var (
+	sleepGroup1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+	sleepGroup2 = []float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4}
+	anscombeX1  = []float64{10, 8, 13, 9, 11, 14, 6, 4, 12, 7, 5}
+	anscombeY1  = []float64{8.04, 6.95, 7.58, 8.81, 8.33, 9.96, 7.24, 4.26, 10.84, 4.82, 5.68}
+)
+
+// assertClose fails the test if got is further than tolerance from want
+func assertClose(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.IsNaN(got) || math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.12g, want %.12g (±%g)", name, got, want, tolerance)
+	}
+}
+
+// assertErr fails the test unless err is want
+func assertErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("error = %v, want %v", err, want)
+	}
+}
+
+func TestRanksTies(t *testing.T) {
+	r, ties := ranks([]float64{2, 1, 2, 3, 2})
+	want := []float64{3, 1, 3, 5, 3}
+	for i := range want {
+		assertClose(t, "rank", r[i], want[i], 0)
+	}
+	// One group of three tied values: 3^3 - 3
+	assertClose(t, "ties", ties, 24, 0)
+}
@@ -0,0 +1,138 @@
+// Package storage keeps generated files, such as background exports, until
+// they are downloaded or expire.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a stored file does not exist
+var ErrNotFound = errors.New("stored file not found")
+
+// Store keeps files by key
+type Store interface {
+	// Create returns a writer for a new file; the file is only stored once
+	// the writer is closed, replacing any file with the same key
+	Create(key string) (Writer, error)
+	// Open returns a stored file for reading
+	Open(key string) (File, error)
+	// Delete removes a stored file, if it exists
+	Delete(key string) error
+}
+
+// Writer writes a new file. Close stores it; Abort discards it.
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// File is a stored file open for reading
+type File interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// Disk stores files in a local directory
+type Disk struct {
+	dir string
+}
+
+// NewDisk creates a Disk storing files in dir, which is created when the
+// first file is stored
+func NewDisk(dir string) *Disk {
+	return &Disk{dir: dir}
+}
+
+// path returns the file path of a key, which may not leave the directory
+func (d *Disk) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(d.dir, key), nil
+}
+
+// Create writes to a temporary file that is renamed into place on Close
+func (d *Disk) Create(key string) (Writer, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(d.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	file, err := os.CreateTemp(d.dir, ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stored file: %w", err)
+	}
+	return &diskWriter{File: file, path: path}, nil
+}
+
+// Open opens a stored file
+func (d *Disk) Open(key string) (File, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stored file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open stored file: %w", err)
+	}
+	return &diskFile{File: file, info: info}, nil
+}
+
+// Delete removes a stored file
+func (d *Disk) Delete(key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	return nil
+}
+
+type diskWriter struct {
+	*os.File
+	path string
+}
+
+func (w *diskWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.Name())
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := os.Rename(w.Name(), w.path); err != nil {
+		os.Remove(w.Name())
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+	return nil
+}
+
+func (w *diskWriter) Abort() error {
+	w.File.Close()
+	return os.Remove(w.Name())
+}
+
+type diskFile struct {
+	*os.File
+	info os.FileInfo
+}
+
+func (f *diskFile) Size() int64        { return f.info.Size() }
+func (f *diskFile) ModTime() time.Time { return f.info.ModTime() }
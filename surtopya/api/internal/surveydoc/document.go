@@ -0,0 +1,322 @@
+// Package surveydoc converts surveys to and from a portable JSON document,
+// used to move surveys between environments and keep them in version control.
+//
+// The document format is described by schemas/survey-document.v1.json.
+// Question IDs in a document are opaque strings that only need to be unique
+// within the document; logic rules and quotas reference them. Exports use the
+// survey's question UUIDs so repeated exports diff cleanly; imports always
+// assign new IDs, so importing the same document twice creates two surveys.
+package surveydoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Format identifies a survey document
+const Format = "surtopya.survey"
+
+// Version is the current document version. Documents with a newer version are rejected.
+const Version = 1
+
+// Document is a portable survey definition
+type Document struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Survey     Survey    `json:"survey"`
+}
+
+// Survey holds the survey settings and content. Publication state, scheduling,
+// ownership and responses are environment-specific and not part of the document.
+type Survey struct {
+	Title                   string                 `json:"title"`
+	Description             string                 `json:"description"`
+	Visibility              string                 `json:"visibility"`
+	IncludeInDatasets       bool                   `json:"includeInDatasets"`
+	Theme                   *models.SurveyTheme    `json:"theme,omitempty"`
+	PointsReward            int                    `json:"pointsReward"`
+	ConsolationPoints       int                    `json:"consolationPoints"`
+	DisqualificationMessage *string                `json:"disqualificationMessage,omitempty"`
+	TargetAudience          *models.TargetAudience `json:"targetAudience,omitempty"`
+	Questions               []Question             `json:"questions"`
+	Quotas                  []Quota                `json:"quotas,omitempty"`
+}
+
+// Question is a survey question, in survey order
+type Question struct {
+	ID          string               `json:"id"`
+	Type        string               `json:"type"`
+	Title       string               `json:"title"`
+	Description string               `json:"description,omitempty"`
+	Options     []string             `json:"options,omitempty"`
+	Required    bool                 `json:"required"`
+	Points      int                  `json:"points"`
+	MaxRating   int                  `json:"maxRating,omitempty"`
+	Logic       []models.LogicRule   `json:"logic,omitempty"`
+	IsScreener  bool                 `json:"isScreener,omitempty"`
+	Eligibility *models.ScreenerRule `json:"eligibility,omitempty"`
+}
+
+// Quota is a response quota, optionally on an option of a question
+type Quota struct {
+	Name             string  `json:"name"`
+	QuestionID       *string `json:"questionId,omitempty"`
+	OptionValue      *string `json:"optionValue,omitempty"`
+	Target           int     `json:"target"`
+	ScreenOutMessage *string `json:"screenOutMessage,omitempty"`
+	IsActive         bool    `json:"isActive"`
+}
+
+// ValidationError lists the problems found in a document
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid survey document: " + strings.Join(e.Problems, "; ")
+}
+
+// Export builds the document for a survey and its quotas
+func Export(survey *models.Survey, quotas []models.SurveyQuota) *Document {
+	doc := &Document{
+		Format:     Format,
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		Survey: Survey{
+			Title:                   survey.Title,
+			Description:             survey.Description,
+			Visibility:              survey.Visibility,
+			IncludeInDatasets:       survey.IncludeInDatasets,
+			Theme:                   survey.Theme,
+			PointsReward:            survey.PointsReward,
+			ConsolationPoints:       survey.ConsolationPoints,
+			DisqualificationMessage: survey.DisqualificationMessage,
+			TargetAudience:          survey.TargetAudience,
+			Questions:               make([]Question, len(survey.Questions)),
+		},
+	}
+
+	questionIDs := make(map[string]bool, len(survey.Questions))
+	for _, q := range survey.Questions {
+		questionIDs[q.ID.String()] = true
+	}
+
+	for i, q := range survey.Questions {
+		question := Question{
+			ID:          q.ID.String(),
+			Type:        q.Type,
+			Title:       q.Title,
+			Options:     q.Options,
+			Required:    q.Required,
+			Points:      q.Points,
+			MaxRating:   q.MaxRating,
+			IsScreener:  q.IsScreener,
+			Eligibility: q.Eligibility,
+		}
+		if q.Description != nil {
+			question.Description = *q.Description
+		}
+		// Rules left pointing to deleted questions would make the document invalid
+		for _, rule := range q.Logic {
+			if rule.DestinationQuestionID == models.EndSurveyDestination || questionIDs[rule.DestinationQuestionID] {
+				question.Logic = append(question.Logic, rule)
+			}
+		}
+		doc.Survey.Questions[i] = question
+	}
+
+	for _, quota := range quotas {
+		q := Quota{
+			Name:             quota.Name,
+			OptionValue:      quota.OptionValue,
+			Target:           quota.Target,
+			ScreenOutMessage: quota.ScreenOutMessage,
+			IsActive:         quota.IsActive,
+		}
+		if quota.QuestionID != nil {
+			questionID := quota.QuestionID.String()
+			q.QuestionID = &questionID
+		}
+		doc.Survey.Quotas = append(doc.Survey.Quotas, q)
+	}
+
+	return doc
+}
+
+// Parse decodes and validates a document, returning a *ValidationError if it is invalid
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("malformed JSON: %v", err)}}
+	}
+
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// Validate checks the document format, version and internal references
+func (d *Document) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if d.Format != Format {
+		addf("format must be %q", Format)
+	}
+	if d.Version < 1 || d.Version > Version {
+		addf("unsupported version %d (supported: 1 to %d)", d.Version, Version)
+	}
+
+	s := d.Survey
+	if strings.TrimSpace(s.Title) == "" {
+		addf("survey title is required")
+	}
+	if s.Visibility != "" && s.Visibility != "public" && s.Visibility != "non-public" {
+		addf("visibility must be \"public\" or \"non-public\"")
+	}
+	if s.PointsReward < 0 || s.ConsolationPoints < 0 {
+		addf("points cannot be negative")
+	}
+	if s.ConsolationPoints > s.PointsReward {
+		addf("consolationPoints cannot exceed pointsReward")
+	}
+
+	questions := make(map[string]*Question, len(s.Questions))
+	for i := range s.Questions {
+		q := &s.Questions[i]
+		if q.ID == "" {
+			addf("question %d: id is required", i+1)
+			continue
+		}
+		if questions[q.ID] != nil {
+			addf("question %d: duplicate id %q", i+1, q.ID)
+			continue
+		}
+		questions[q.ID] = q
+	}
+
+	for i, q := range s.Questions {
+		if !isValidType(q.Type) {
+			addf("question %d: invalid type %q", i+1, q.Type)
+		}
+		for _, rule := range q.Logic {
+			if len(q.Options) > 0 && !slices.Contains(q.Options, rule.TriggerOption) {
+				addf("question %d: logic trigger %q is not an option", i+1, rule.TriggerOption)
+			}
+			if rule.DestinationQuestionID != models.EndSurveyDestination && questions[rule.DestinationQuestionID] == nil {
+				addf("question %d: logic destination %q does not exist", i+1, rule.DestinationQuestionID)
+			}
+		}
+	}
+
+	for i, quota := range s.Quotas {
+		if strings.TrimSpace(quota.Name) == "" {
+			addf("quota %d: name is required", i+1)
+		}
+		if quota.Target <= 0 {
+			addf("quota %d: target must be positive", i+1)
+		}
+		if (quota.QuestionID == nil) != (quota.OptionValue == nil) {
+			addf("quota %d: questionId and optionValue must be given together", i+1)
+			continue
+		}
+		if quota.QuestionID == nil {
+			continue
+		}
+		q := questions[*quota.QuestionID]
+		if q == nil {
+			addf("quota %d: question %q does not exist", i+1, *quota.QuestionID)
+		} else if quota.OptionValue != nil && !slices.Contains(q.Options, *quota.OptionValue) {
+			addf("quota %d: %q is not an option of question %q", i+1, *quota.OptionValue, *quota.QuestionID)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Build creates an unpublished draft survey owned by userID from a validated
+// document, with new question IDs; logic and quotas are remapped to them
+func (d *Document) Build(userID uuid.UUID) (*models.Survey, []models.SurveyQuota) {
+	s := d.Survey
+	survey := &models.Survey{
+		ID:                      uuid.New(),
+		UserID:                  userID,
+		Title:                   s.Title,
+		Description:             s.Description,
+		Visibility:              s.Visibility,
+		IsPublished:             false,
+		IncludeInDatasets:       s.IncludeInDatasets,
+		PublishedCount:          0,
+		Theme:                   s.Theme,
+		PointsReward:            s.PointsReward,
+		ConsolationPoints:       s.ConsolationPoints,
+		DisqualificationMessage: s.DisqualificationMessage,
+		TargetAudience:          s.TargetAudience,
+		Questions:               make([]models.Question, len(s.Questions)),
+	}
+	if survey.Visibility == "" {
+		survey.Visibility = "non-public"
+	}
+	if survey.Visibility == "public" {
+		survey.IncludeInDatasets = true
+	}
+
+	idMap := make(map[string]uuid.UUID, len(s.Questions))
+	for i, q := range s.Questions {
+		idMap[q.ID] = uuid.New()
+		description := q.Description
+		survey.Questions[i] = models.Question{
+			ID:          idMap[q.ID],
+			SurveyID:    survey.ID,
+			Type:        q.Type,
+			Title:       q.Title,
+			Description: &description,
+			Options:     q.Options,
+			Required:    q.Required,
+			Points:      q.Points,
+			MaxRating:   q.MaxRating,
+			Logic:       q.Logic,
+			IsScreener:  q.IsScreener,
+			Eligibility: q.Eligibility,
+			SortOrder:   i,
+		}
+	}
+	models.RemapLogic(survey.Questions, idMap)
+
+	quotas := make([]models.SurveyQuota, len(s.Quotas))
+	for i, q := range s.Quotas {
+		quotas[i] = models.SurveyQuota{
+			ID:               uuid.New(),
+			SurveyID:         survey.ID,
+			Name:             q.Name,
+			OptionValue:      q.OptionValue,
+			Target:           q.Target,
+			ScreenOutMessage: q.ScreenOutMessage,
+			IsActive:         q.IsActive,
+		}
+		if q.QuestionID != nil {
+			questionID := idMap[*q.QuestionID]
+			quotas[i].QuestionID = &questionID
+		}
+	}
+
+	return survey, quotas
+}
+
+func isValidType(questionType string) bool {
+	return slices.Contains(models.ValidQuestionTypes, questionType)
+}
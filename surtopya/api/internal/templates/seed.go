@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// namespace derives stable template and question IDs from slugs, so reseeding
+// keeps the IDs that instantiated surveys and clients already know
+var namespace = uuid.MustParse("6f1c3b0e-3d5a-4c84-9a6e-2b7f4f0d9c11")
+
+// File is the JSON format of a curated template file
+type File struct {
+	Slug                    string              `json:"slug"`
+	Category                string              `json:"category"`
+	Title                   string              `json:"title"`
+	Description             string              `json:"description"`
+	Theme                   *models.SurveyTheme `json:"theme"`
+	DisqualificationMessage *string             `json:"disqualificationMessage"`
+	Questions               []Question          `json:"questions"`
+}
+
+// Question is a template question; its ID is any string unique within the file,
+// referenced by logic destinations
+type Question struct {
+	ID          string               `json:"id"`
+	Type        string               `json:"type"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Options     []string             `json:"options"`
+	Required    bool                 `json:"required"`
+	Points      int                  `json:"points"`
+	MaxRating   int                  `json:"maxRating"`
+	Logic       []models.LogicRule   `json:"logic"`
+	IsScreener  bool                 `json:"isScreener"`
+	Eligibility *models.ScreenerRule `json:"eligibility"`
+}
+
+// Seed upserts the curated templates from the *.json files in dir.
+// A missing directory is not an error; invalid files are skipped and logged.
+func Seed(repo *repository.TemplateRepository, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	sort.Strings(paths)
+
+	seeded := 0
+	for _, path := range paths {
+		template, err := load(path)
+		if err != nil {
+			log.Printf("templates: skipping %s: %v", path, err)
+			continue
+		}
+		if err := repo.UpsertCurated(template); err != nil {
+			return err
+		}
+		seeded++
+	}
+
+	if seeded > 0 {
+		log.Printf("templates: seeded %d curated templates from %s", seeded, dir)
+	}
+	return nil
+}
+
+// load reads and validates a template file
+func load(path string) (*models.SurveyTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if file.Slug == "" || file.Category == "" || file.Title == "" {
+		return nil, fmt.Errorf("slug, category and title are required")
+	}
+
+	questions := make([]models.Question, len(file.Questions))
+	idMap := make(map[string]uuid.UUID, len(file.Questions))
+	for i, q := range file.Questions {
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("q%d", i+1)
+		}
+		if _, exists := idMap[q.ID]; exists {
+			return nil, fmt.Errorf("duplicate question id %q", q.ID)
+		}
+		if !isValidType(q.Type) {
+			return nil, fmt.Errorf("question %q has invalid type %q", q.ID, q.Type)
+		}
+
+		idMap[q.ID] = uuid.NewSHA1(namespace, []byte(file.Slug+"/"+q.ID))
+		description := q.Description
+		questions[i] = models.Question{
+			ID:          idMap[q.ID],
+			Type:        q.Type,
+			Title:       q.Title,
+			Description: &description,
+			Options:     q.Options,
+			Required:    q.Required,
+			Points:      q.Points,
+			MaxRating:   q.MaxRating,
+			Logic:       q.Logic,
+			IsScreener:  q.IsScreener,
+			Eligibility: q.Eligibility,
+			SortOrder:   i,
+		}
+	}
+
+	for _, q := range questions {
+		for _, rule := range q.Logic {
+			if _, ok := idMap[rule.DestinationQuestionID]; !ok && rule.DestinationQuestionID != models.EndSurveyDestination {
+				return nil, fmt.Errorf("logic destination %q does not exist", rule.DestinationQuestionID)
+			}
+		}
+	}
+	models.RemapLogic(questions, idMap)
+
+	slug := file.Slug
+	return &models.SurveyTemplate{
+		ID:                      uuid.NewSHA1(namespace, []byte(slug)),
+		Slug:                    &slug,
+		Category:                file.Category,
+		Title:                   file.Title,
+		Description:             file.Description,
+		Theme:                   file.Theme,
+		DisqualificationMessage: file.DisqualificationMessage,
+		Questions:               questions,
+		IsPublic:                true,
+		IsCurated:               true,
+	}, nil
+}
+
+func isValidType(questionType string) bool {
+	for _, t := range models.ValidQuestionTypes {
+		if t == questionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package textcluster
+
+import (
+	"math"
+	"sort"
+)
+
+// Limits of the clustering
+const (
+	MaxClusters    = 20
+	maxIterations  = 50
+	labelTerms     = 3
+	clusterTerms   = 8
+	clusterSamples = 3
+)
+
+// Group is a cluster of similar texts, by index into the input
+type Group struct {
+	Members  []int
+	Terms    []string // Highest-weighted terms of the cluster centre
+	Examples []int    // Members closest to the cluster centre
+}
+
+// Label joins the group's leading terms
+func (g Group) Label() string {
+	n := labelTerms
+	if len(g.Terms) < n {
+		n = len(g.Terms)
+	}
+	label := ""
+	for i, term := range g.Terms[:n] {
+		if i > 0 {
+			label += " / "
+		}
+		label += term
+	}
+	return label
+}
+
+// vector is a sparse, unit-length TF-IDF vector ordered by term, so sums
+// over it come out the same every run
+type vector []entry
+
+type entry struct {
+	term   int
+	weight float64
+}
+
+// Suggest clusters texts into at most k groups, largest first. With k <= 0
+// the number of groups follows the number of texts. Texts with no terms
+// shared with any other text are left out. The result is deterministic.
+func Suggest(texts []string, k int) []Group {
+	vectors, terms := tfidf(texts)
+
+	var docs []int
+	for i, v := range vectors {
+		if len(v) > 0 {
+			docs = append(docs, i)
+		}
+	}
+	if len(docs) == 0 {
+		return []Group{}
+	}
+
+	if k <= 0 {
+		k = int(math.Round(math.Sqrt(float64(len(docs)) / 2)))
+		if k < 2 {
+			k = 2
+		}
+	}
+	if k > MaxClusters {
+		k = MaxClusters
+	}
+	if k > len(docs) {
+		k = len(docs)
+	}
+
+	centroids := initialCentroids(vectors, docs, k, len(terms))
+	assignment := make(map[int]int, len(docs))
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+		for _, doc := range docs {
+			best, _ := nearest(vectors[doc], centroids)
+			if previous, ok := assignment[doc]; !ok || previous != best {
+				assignment[doc] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][]float64, k)
+		for c := range sums {
+			sums[c] = make([]float64, len(terms))
+		}
+		for _, doc := range docs {
+			for _, e := range vectors[doc] {
+				sums[assignment[doc]][e.term] += e.weight
+			}
+		}
+		for c := range centroids {
+			// An emptied cluster keeps its previous centre
+			if normalize(sums[c]) {
+				centroids[c] = sums[c]
+			}
+		}
+	}
+
+	groups := make([]Group, k)
+	for _, doc := range docs {
+		c := assignment[doc]
+		groups[c].Members = append(groups[c].Members, doc)
+	}
+
+	var result []Group
+	for c, group := range groups {
+		if len(group.Members) == 0 {
+			continue
+		}
+		group.Terms = topTerms(centroids[c], terms, clusterTerms)
+		group.Examples = closest(group.Members, vectors, centroids[c], clusterSamples)
+		result = append(result, group)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Members) > len(result[j].Members)
+	})
+	return result
+}
+
+// tfidf vectorizes texts with sublinear term frequencies and smoothed IDF.
+// With ten or more texts, terms found in only one text are dropped as noise.
+func tfidf(texts []string) ([]vector, []string) {
+	counts := make([]map[string]int, len(texts))
+	documentFrequency := make(map[string]int)
+	for i, text := range texts {
+		counts[i] = make(map[string]int)
+		for _, token := range Tokenize(text) {
+			counts[i][token]++
+		}
+		for token := range counts[i] {
+			documentFrequency[token]++
+		}
+	}
+
+	minFrequency := 1
+	if len(texts) >= 10 {
+		minFrequency = 2
+	}
+	var terms []string
+	for term, df := range documentFrequency {
+		if df >= minFrequency {
+			terms = append(terms, term)
+		}
+	}
+	sort.Strings(terms)
+	index := make(map[string]int, len(terms))
+	for i, term := range terms {
+		index[term] = i
+	}
+
+	n := float64(len(texts))
+	vectors := make([]vector, len(texts))
+	for i := range texts {
+		var v vector
+		for token, count := range counts[i] {
+			term, ok := index[token]
+			if !ok {
+				continue
+			}
+			idf := math.Log((1+n)/(1+float64(documentFrequency[token]))) + 1
+			v = append(v, entry{term, (1 + math.Log(float64(count))) * idf})
+		}
+		sort.Slice(v, func(a, b int) bool { return v[a].term < v[b].term })
+
+		norm := 0.0
+		for _, e := range v {
+			norm += e.weight * e.weight
+		}
+		norm = math.Sqrt(norm)
+		for j := range v {
+			v[j].weight /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors, terms
+}
+
+// initialCentroids seeds the clusters farthest-first: the most typical text,
+// then repeatedly the text least similar to every centre chosen so far
+func initialCentroids(vectors []vector, docs []int, k, dims int) [][]float64 {
+	mean := make([]float64, dims)
+	for _, doc := range docs {
+		for _, e := range vectors[doc] {
+			mean[e.term] += e.weight
+		}
+	}
+	normalize(mean)
+
+	first, bestSimilarity := docs[0], -1.0
+	for _, doc := range docs {
+		if s := dot(vectors[doc], mean); s > bestSimilarity {
+			first, bestSimilarity = doc, s
+		}
+	}
+
+	centroids := [][]float64{dense(vectors[first], dims)}
+	for len(centroids) < k {
+		next, lowest := -1, math.Inf(1)
+		for _, doc := range docs {
+			if _, s := nearest(vectors[doc], centroids); s < lowest {
+				next, lowest = doc, s
+			}
+		}
+		centroids = append(centroids, dense(vectors[next], dims))
+	}
+	return centroids
+}
+
+// nearest returns the index of the most similar centroid and its similarity
+func nearest(v vector, centroids [][]float64) (int, float64) {
+	best, bestSimilarity := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if s := dot(v, centroid); s > bestSimilarity {
+			best, bestSimilarity = c, s
+		}
+	}
+	return best, bestSimilarity
+}
+
+func dot(v vector, centroid []float64) float64 {
+	sum := 0.0
+	for _, e := range v {
+		sum += e.weight * centroid[e.term]
+	}
+	return sum
+}
+
+func dense(v vector, dims int) []float64 {
+	d := make([]float64, dims)
+	for _, e := range v {
+		d[e.term] = e.weight
+	}
+	return d
+}
+
+// normalize scales d to unit length, reporting false if it is zero
+func normalize(d []float64) bool {
+	norm := 0.0
+	for _, x := range d {
+		norm += x * x
+	}
+	if norm == 0 {
+		return false
+	}
+	norm = math.Sqrt(norm)
+	for i := range d {
+		d[i] /= norm
+	}
+	return true
+}
+
+// topTerms returns the n highest-weighted terms of a centroid
+func topTerms(centroid []float64, terms []string, n int) []string {
+	order := make([]int, 0, len(centroid))
+	for term, weight := range centroid {
+		if weight > 0 {
+			order = append(order, term)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return centroid[order[i]] > centroid[order[j]] })
+	if len(order) > n {
+		order = order[:n]
+	}
+
+	result := make([]string, len(order))
+	for i, term := range order {
+		result[i] = terms[term]
+	}
+	return result
+}
+
+// closest returns the n members most similar to the centroid
+func closest(members []int, vectors []vector, centroid []float64, n int) []int {
+	sorted := append([]int{}, members...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dot(vectors[sorted[i]], centroid) > dot(vectors[sorted[j]], centroid)
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
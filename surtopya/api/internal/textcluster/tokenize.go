@@ -0,0 +1,99 @@
+// Package textcluster groups short free-text answers by theme without a
+// language model: answers become TF-IDF vectors over CJK-aware tokens and
+// are clustered with spherical k-means.
+package textcluster
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words that carry no theme
+var stopWords = toSet(strings.Fields(`
+	a about all also am an and any are as at be because been but by can could
+	did do does doing for from get got had has have he her him his how i if in
+	into is it its just like me more most my no not of on one or other our out
+	really she so some such than that the their them then there these they this
+	those to too up us very was we were what when where which who why will with
+	would you your yes ok okay
+`))
+
+// cjkStopChars are common function characters; CJK text is split at them
+// before forming bigrams
+var cjkStopChars = toSet(strings.Split(
+	"的了是我你他她它們们很也都在和與与就有不這这那嗎吗呢吧啊喔哦而及或被把給给對对會会要還还再但",
+	"",
+))
+
+// isCJK reports whether r is written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Tokenize splits text into terms. Words in space-separated scripts are
+// lowercased and stop words dropped; runs of CJK characters, which have no
+// spaces, become overlapping character bigrams (or a single character when
+// a run is one character long).
+func Tokenize(text string) []string {
+	var tokens []string
+	var word, cjk []rune
+
+	flushWord := func() {
+		if len(word) > 1 && !stopWords[string(word)] && !allDigits(word) {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			if cjkStopChars[string(r)] {
+				flushCJK()
+			} else {
+				cjk = append(cjk, r)
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'':
+			flushCJK()
+			if r != '\'' {
+				word = append(word, r)
+			}
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+func allDigits(word []rune) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
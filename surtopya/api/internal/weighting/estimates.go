@@ -0,0 +1,89 @@
+package weighting
+
+import (
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/google/uuid"
+)
+
+// Apply adds weighted option shares and rating means to a report built over
+// the records, along with the diagnostics of the weights. Records without a
+// weight are left out of the estimates.
+func Apply(report *models.SurveyReport, survey *models.Survey, records []models.ResponseRecord, result *Result) {
+	report.Weighting = result.Diagnostics
+
+	for i := range report.Questions {
+		qr := &report.Questions[i]
+		q := findQuestion(survey, qr.QuestionID)
+		if q == nil {
+			continue
+		}
+		switch {
+		case len(qr.Options) > 0:
+			weightOptions(qr, q.ID, records, result.Weights, report.Confidence)
+		case qr.Rating != nil:
+			qr.Rating.WeightedMean = weightedMean(q.ID, records, result.Weights, report.Confidence)
+		}
+	}
+}
+
+// weightOptions sets the weighted share of each option among the weighted
+// responses that answered the question
+func weightOptions(qr *models.QuestionReport, questionID uuid.UUID, records []models.ResponseRecord, weights map[uuid.UUID]float64, confidence float64) {
+	chosen := make(map[string]float64)
+	var answered []float64
+	var total float64
+	for _, record := range records {
+		w, ok := weights[record.ID]
+		answer, answeredQuestion := record.Answers[questionID]
+		if !ok || !answeredQuestion {
+			continue
+		}
+		answered = append(answered, w)
+		total += w
+
+		values := answer.Values
+		if answer.Value != nil {
+			values = []string{*answer.Value}
+		}
+		for _, v := range values {
+			chosen[v] += w
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	n := stats.EffectiveN(answered)
+	for i := range qr.Options {
+		option := &qr.Options[i]
+		p := chosen[option.Option] / total
+		if p > 1 {
+			// An option listed twice in a multi-choice answer
+			p = 1
+		}
+		ci, _ := stats.WeightedProportionCI(p, n, confidence)
+		option.Weighted = &models.WeightedShare{Percentage: percentage(p, 1), CI: ci}
+	}
+}
+
+// weightedMean returns the weighted mean rating with its confidence
+// interval, or nil with fewer than two effective responses
+func weightedMean(questionID uuid.UUID, records []models.ResponseRecord, weights map[uuid.UUID]float64, confidence float64) *stats.Interval {
+	var values, ws []float64
+	for _, record := range records {
+		w, ok := weights[record.ID]
+		answer := record.Answers[questionID]
+		if !ok || answer.Rating == nil {
+			continue
+		}
+		values = append(values, float64(*answer.Rating))
+		ws = append(ws, w)
+	}
+
+	ci, err := stats.WeightedMeanCI(values, ws, confidence)
+	if err != nil {
+		return nil
+	}
+	return ci
+}
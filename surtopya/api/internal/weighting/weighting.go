@@ -0,0 +1,192 @@
+// Package weighting rakes survey responses to population margins, such as
+// the gender, age band and region shares of a census, and adds weighted
+// estimates to survey reports.
+package weighting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/TimLai666/surtopya-api/internal/models"
+	"github.com/TimLai666/surtopya-api/internal/stats"
+	"github.com/google/uuid"
+)
+
+// Limits of a weighting scheme
+const (
+	MaxMargins     = 10
+	MaxWeightLimit = 100 // Largest weight cap, as a multiple of the mean weight
+)
+
+// ErrNoResponses is returned when there are no responses to weight
+var ErrNoResponses = errors.New("no responses to weight")
+
+// ErrEmptyCategory is returned when no response chose an option that has a
+// target share, so no weight can make up for it
+var ErrEmptyCategory = errors.New("no responses for a target option")
+
+// Result is the weights of a set of responses
+type Result struct {
+	Weights     map[uuid.UUID]float64 // By response ID, averaging 1
+	Diagnostics *models.WeightingDiagnostics
+}
+
+// Validate checks a scheme against the survey's questions. The error
+// message is meant for the survey owner.
+func Validate(survey *models.Survey, scheme *models.WeightingScheme) error {
+	if len(scheme.Margins) == 0 {
+		return errors.New("at least one margin is required")
+	}
+	if len(scheme.Margins) > MaxMargins {
+		return fmt.Errorf("at most %d margins are allowed", MaxMargins)
+	}
+	if scheme.MaxWeight != nil && (*scheme.MaxWeight <= 1 || *scheme.MaxWeight > MaxWeightLimit) {
+		return fmt.Errorf("maxWeight must be greater than 1 and at most %d", MaxWeightLimit)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, margin := range scheme.Margins {
+		q := findQuestion(survey, margin.QuestionID)
+		if q == nil {
+			return fmt.Errorf("question %s not found", margin.QuestionID)
+		}
+		if q.Type != "single" && q.Type != "select" {
+			return fmt.Errorf("%q is not a single-choice or dropdown question", q.Title)
+		}
+		if seen[q.ID] {
+			return fmt.Errorf("%q has more than one margin", q.Title)
+		}
+		seen[q.ID] = true
+
+		if len(margin.Targets) < 2 {
+			return fmt.Errorf("the margin of %q needs at least two options", q.Title)
+		}
+		options := make(map[string]bool)
+		for _, target := range margin.Targets {
+			if !slices.Contains(q.Options, target.Option) {
+				return fmt.Errorf("%q is not an option of %q", target.Option, q.Title)
+			}
+			if options[target.Option] {
+				return fmt.Errorf("%q is listed twice in the margin of %q", target.Option, q.Title)
+			}
+			options[target.Option] = true
+			if !(target.Share > 0) || math.IsInf(target.Share, 0) {
+				return fmt.Errorf("the share of %q in %q must be positive", target.Option, q.Title)
+			}
+		}
+	}
+	return nil
+}
+
+// Compute rakes the responses to the scheme's margins. ids lists the
+// responses and choices holds the option each chose for the margin
+// questions. A response that skipped a margin question, or chose an option
+// without a target, is not adjusted for that margin.
+func Compute(survey *models.Survey, scheme *models.WeightingScheme, ids []uuid.UUID, choices map[uuid.UUID]map[uuid.UUID]string) (*Result, error) {
+	if len(ids) == 0 {
+		return nil, ErrNoResponses
+	}
+
+	categories := make([][]int, len(scheme.Margins))
+	targets := make([][]float64, len(scheme.Margins))
+	diagnostics := &models.WeightingDiagnostics{Responses: len(ids)}
+	for v, margin := range scheme.Margins {
+		title := margin.QuestionID.String()
+		if q := findQuestion(survey, margin.QuestionID); q != nil {
+			title = q.Title
+		}
+
+		index := make(map[string]int, len(margin.Targets))
+		targets[v] = make([]float64, len(margin.Targets))
+		for c, target := range margin.Targets {
+			index[target.Option] = c
+			targets[v][c] = target.Share
+		}
+
+		fit := models.MarginFit{QuestionID: margin.QuestionID, Title: title, Categories: []models.CategoryFit{}}
+		counts := make([]int, len(margin.Targets))
+		categories[v] = make([]int, len(ids))
+		for i, id := range ids {
+			c, ok := index[choices[id][margin.QuestionID]]
+			if !ok {
+				c = -1
+				fit.Missing++
+			} else {
+				counts[c]++
+			}
+			categories[v][i] = c
+		}
+		for c, target := range margin.Targets {
+			if counts[c] == 0 {
+				return nil, fmt.Errorf("%w: nobody chose %q in %q", ErrEmptyCategory, target.Option, title)
+			}
+			fit.Categories = append(fit.Categories, models.CategoryFit{Option: target.Option, Responses: counts[c]})
+		}
+		diagnostics.Margins = append(diagnostics.Margins, fit)
+	}
+
+	opts := stats.RakeOptions{}
+	if scheme.MaxWeight != nil {
+		opts.MaxWeight = *scheme.MaxWeight
+	}
+	raking, err := stats.Rake(categories, targets, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rake responses: %w", err)
+	}
+	diagnostics.Raking = *raking
+
+	for v := range scheme.Margins {
+		var targetSum, weightSum float64
+		for _, t := range targets[v] {
+			targetSum += t
+		}
+		weighted := make([]float64, len(targets[v]))
+		for i, c := range categories[v] {
+			if c >= 0 {
+				weighted[c] += raking.Weights[i]
+				weightSum += raking.Weights[i]
+			}
+		}
+		answered := len(ids) - diagnostics.Margins[v].Missing
+		for c := range diagnostics.Margins[v].Categories {
+			category := &diagnostics.Margins[v].Categories[c]
+			category.Target = percentage(targets[v][c], targetSum)
+			category.Sample = percentage(float64(category.Responses), float64(answered))
+			category.Weighted = percentage(weighted[c], weightSum)
+		}
+	}
+
+	result := &Result{Weights: make(map[uuid.UUID]float64, len(ids)), Diagnostics: diagnostics}
+	for i, id := range ids {
+		result.Weights[id] = raking.Weights[i]
+	}
+	return result, nil
+}
+
+// MarginQuestionIDs returns the IDs of the scheme's margin questions
+func MarginQuestionIDs(scheme *models.WeightingScheme) []string {
+	ids := make([]string, len(scheme.Margins))
+	for i, margin := range scheme.Margins {
+		ids[i] = margin.QuestionID.String()
+	}
+	return ids
+}
+
+func findQuestion(survey *models.Survey, id uuid.UUID) *models.Question {
+	for i := range survey.Questions {
+		if survey.Questions[i].ID == id {
+			return &survey.Questions[i]
+		}
+	}
+	return nil
+}
+
+// percentage returns part as a percentage of total, rounded to one decimal
+func percentage(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(part*1000/total) / 10
+}
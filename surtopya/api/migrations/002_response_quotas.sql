@@ -0,0 +1,41 @@
+-- Surtopya Database Schema
+-- Migration 002: Response quotas
+
+-- Quota definitions per survey
+-- A quota without a question_id is an overall quota (counts every completion).
+-- A quota with a question_id counts completions whose answer to that question
+-- includes option_value (e.g. 100 "Male" completes on a screener question).
+CREATE TABLE survey_quotas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- Matching condition (both NULL for the overall quota)
+    question_id UUID REFERENCES questions(id) ON DELETE CASCADE,
+    option_value TEXT,
+
+    -- Limits
+    target INTEGER NOT NULL CHECK (target > 0),
+    current_count INTEGER NOT NULL DEFAULT 0,
+
+    -- Message shown to respondents screened out by this quota
+    screen_out_message TEXT,
+
+    is_active BOOLEAN DEFAULT TRUE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CHECK ((question_id IS NULL) = (option_value IS NULL))
+);
+
+CREATE INDEX idx_survey_quotas_survey_id ON survey_quotas(survey_id);
+
+CREATE TRIGGER update_survey_quotas_updated_at BEFORE UPDATE ON survey_quotas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Responses rejected by a full quota get their own status
+ALTER TABLE responses DROP CONSTRAINT responses_status_check;
+ALTER TABLE responses ADD CONSTRAINT responses_status_check
+    CHECK (status IN ('in_progress', 'completed', 'abandoned', 'quota_full'));
@@ -0,0 +1,17 @@
+-- Surtopya Database Schema
+-- Migration 003: Screener questions and disqualification
+
+-- Screener questions terminate ineligible respondents early
+-- Eligibility rule (JSONB object):
+--   {"qualifyingOptions": ["Student", "Teacher"]}  for single/multi/select
+--   {"minRating": 3, "maxRating": 5}                for rating
+ALTER TABLE questions ADD COLUMN is_screener BOOLEAN DEFAULT FALSE;
+ALTER TABLE questions ADD COLUMN eligibility JSONB;
+
+-- Optional consolation award and message for disqualified respondents
+ALTER TABLE surveys ADD COLUMN consolation_points INTEGER DEFAULT 0;
+ALTER TABLE surveys ADD COLUMN disqualification_message TEXT;
+
+ALTER TABLE responses DROP CONSTRAINT responses_status_check;
+ALTER TABLE responses ADD CONSTRAINT responses_status_check
+    CHECK (status IN ('in_progress', 'completed', 'abandoned', 'quota_full', 'disqualified'));
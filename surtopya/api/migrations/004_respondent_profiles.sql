@@ -0,0 +1,29 @@
+-- Surtopya Database Schema
+-- Migration 004: Respondent demographic profiles and audience targeting
+
+-- Demographic attributes are only stored once the user has consented
+CREATE TABLE respondent_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+
+    birth_year INTEGER CHECK (birth_year BETWEEN 1900 AND 2100),
+    gender VARCHAR(30) CHECK (gender IN ('male', 'female', 'non-binary', 'other', 'prefer_not_to_say')),
+    region VARCHAR(100),
+    interests JSONB DEFAULT '[]',
+
+    -- Consent
+    consented BOOLEAN NOT NULL DEFAULT FALSE,
+    consented_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_respondent_profiles_region ON respondent_profiles(region);
+CREATE INDEX idx_respondent_profiles_gender ON respondent_profiles(gender);
+
+CREATE TRIGGER update_respondent_profiles_updated_at BEFORE UPDATE ON respondent_profiles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Target audience (JSONB object, NULL = everyone):
+--   {"minAge": 18, "maxAge": 35, "genders": ["female"], "regions": ["Taipei"], "interests": ["technology"]}
+ALTER TABLE surveys ADD COLUMN target_audience JSONB;
@@ -0,0 +1,29 @@
+-- Surtopya Database Schema
+-- Migration 005: Scheduled open/close windows and owner notifications
+
+-- opens_at holds a pending scheduled opening (cleared once the scheduler publishes)
+-- closed_at records when the survey was last closed by expiry or quota
+ALTER TABLE surveys ADD COLUMN opens_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE surveys ADD COLUMN closed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_surveys_opens_at ON surveys(opens_at) WHERE opens_at IS NOT NULL;
+CREATE INDEX idx_surveys_expires_at ON surveys(expires_at) WHERE is_published = TRUE;
+
+-- Notifications for users (e.g. a survey was closed)
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    type VARCHAR(50) NOT NULL CHECK (type IN ('survey_opened', 'survey_closed')),
+    title VARCHAR(500) NOT NULL,
+    message TEXT,
+
+    -- References
+    survey_id UUID REFERENCES surveys(id) ON DELETE CASCADE,
+
+    is_read BOOLEAN DEFAULT FALSE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC);
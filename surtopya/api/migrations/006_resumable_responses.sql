@@ -0,0 +1,10 @@
+-- Surtopya Database Schema
+-- Migration 006: Abandoned-response sweeper and resumable sessions
+
+-- Last time the respondent saved anything (used to detect abandoned responses)
+ALTER TABLE responses ADD COLUMN last_activity_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+-- SHA-256 hash of the resume token issued when the response was started
+ALTER TABLE responses ADD COLUMN resume_token_hash VARCHAR(64) UNIQUE;
+
+CREATE INDEX idx_responses_stale ON responses(last_activity_at) WHERE status = 'in_progress';
@@ -0,0 +1,29 @@
+-- Surtopya Database Schema
+-- Migration 007: Role-based access control and admin audit log
+
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'user';
+
+-- Surveys taken down by moderators cannot be republished by their owner
+ALTER TABLE surveys ADD COLUMN taken_down_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE surveys ADD COLUMN takedown_reason TEXT;
+
+-- Audit log of every admin action
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id UUID,
+    details JSONB DEFAULT '{}',
+    ip_address VARCHAR(64),
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX idx_audit_logs_target ON audit_logs(target_type, target_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at DESC);
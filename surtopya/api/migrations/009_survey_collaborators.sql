@@ -0,0 +1,31 @@
+-- Surtopya Database Schema
+-- Migration 009: Per-survey collaborators and share invitations
+
+CREATE TABLE survey_collaborators (
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer', 'analyst')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (survey_id, user_id)
+);
+
+CREATE INDEX idx_survey_collaborators_user_id ON survey_collaborators(user_id);
+
+-- Invitations are accepted with a single-use token; only its hash is stored
+CREATE TABLE survey_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer', 'analyst')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_survey_invitations_survey_id ON survey_invitations(survey_id);
@@ -0,0 +1,17 @@
+-- Surtopya Database Schema
+-- Migration 010: Survey revisions for optimistic concurrency on edits
+
+-- Incremented on every survey update; exposed as the ETag of GET /surveys/:id
+ALTER TABLE surveys ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+-- Snapshot of each revision (survey with its questions), used to report what
+-- changed on the server when a stale edit is rejected
+CREATE TABLE survey_revisions (
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (survey_id, revision)
+);
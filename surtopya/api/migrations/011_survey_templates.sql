@@ -0,0 +1,28 @@
+-- Surtopya Database Schema
+-- Migration 011: Survey template library
+
+CREATE TABLE survey_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- Curated templates are seeded from JSON files and identified by slug
+    slug VARCHAR(100) UNIQUE,
+    category VARCHAR(50) NOT NULL,
+
+    title VARCHAR(500) NOT NULL,
+    description TEXT,
+    theme JSONB,
+    disqualification_message TEXT,
+    questions JSONB NOT NULL DEFAULT '[]',
+
+    is_public BOOLEAN DEFAULT FALSE,
+    is_curated BOOLEAN DEFAULT FALSE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_survey_templates_category ON survey_templates(category) WHERE is_public = true;
+CREATE INDEX idx_survey_templates_created_by ON survey_templates(created_by);
+
+CREATE TRIGGER update_survey_templates_updated_at BEFORE UPDATE ON survey_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,21 @@
+-- Surtopya Database Schema
+-- Migration 012: Notify listeners of completed responses
+
+-- Every API instance LISTENs on survey_responses and streams the completions
+-- to the owners watching the survey. The notification is sent when the
+-- completing transaction commits, so its answers are visible to listeners.
+CREATE OR REPLACE FUNCTION notify_response_completed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'completed' AND (TG_OP = 'INSERT' OR OLD.status IS DISTINCT FROM 'completed') THEN
+        PERFORM pg_notify('survey_responses', json_build_object(
+            'surveyId', NEW.survey_id,
+            'responseId', NEW.id
+        )::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_responses_completed AFTER INSERT OR UPDATE OF status ON responses
+    FOR EACH ROW EXECUTE FUNCTION notify_response_completed();
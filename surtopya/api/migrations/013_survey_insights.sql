@@ -0,0 +1,16 @@
+-- Surtopya Database Schema
+-- Migration 013: Cached AI summaries of survey results
+
+-- One summary per survey revision and model; regenerating replaces it.
+-- responses records how many completed responses it was based on, so a
+-- summary can be flagged as stale once more arrive.
+CREATE TABLE survey_insights (
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    model VARCHAR(200) NOT NULL,
+    responses INTEGER NOT NULL,
+    summary TEXT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (survey_id, revision, model)
+);
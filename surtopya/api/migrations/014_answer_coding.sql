@@ -0,0 +1,35 @@
+-- Surtopya Database Schema
+-- Migration 014: Codebooks for coding open-text answers
+
+-- Each open-text question has its own codebook of themes
+CREATE TABLE answer_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    label VARCHAR(200) NOT NULL,
+    description TEXT,
+    color VARCHAR(20),
+    position INTEGER NOT NULL DEFAULT 0,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (question_id, label)
+);
+
+CREATE INDEX idx_answer_codes_survey ON answer_codes(survey_id);
+
+CREATE TRIGGER update_answer_codes_updated_at BEFORE UPDATE ON answer_codes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- An answer can carry several codes
+CREATE TABLE answer_code_assignments (
+    answer_id UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    code_id UUID NOT NULL REFERENCES answer_codes(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (answer_id, code_id)
+);
+
+CREATE INDEX idx_answer_code_assignments_code ON answer_code_assignments(code_id);
@@ -0,0 +1,18 @@
+-- Surtopya Database Schema
+-- Migration 015: Target margins for raking survey responses
+
+-- One weighting scheme per survey. margins is a JSON array of
+-- {questionId, targets: [{option, share}]}; weights are computed from it
+-- when reports and exports ask for them, so they follow new responses.
+CREATE TABLE survey_weighting (
+    survey_id UUID PRIMARY KEY REFERENCES surveys(id) ON DELETE CASCADE,
+    margins JSONB NOT NULL,
+    max_weight DOUBLE PRECISION CHECK (max_weight IS NULL OR max_weight > 1),
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_survey_weighting_updated_at BEFORE UPDATE ON survey_weighting
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
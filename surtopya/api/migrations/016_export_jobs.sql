@@ -0,0 +1,34 @@
+-- Surtopya Database Schema
+-- Migration 016: Background exports of survey responses
+
+-- An export is queued by a user with results access, picked up by a worker
+-- that pages through the responses and writes the file to storage, and
+-- downloaded through signed links until it expires. filter holds the report
+-- filter (from, to, statuses, segment) the export was requested with.
+CREATE TABLE export_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    survey_id UUID NOT NULL REFERENCES surveys(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'sav', 'dta', 'r')),
+    filter JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'running', 'completed', 'failed', 'expired')),
+
+    total_rows INTEGER,
+    rows_written INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    storage_key VARCHAR(255),
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    error TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_export_jobs_survey ON export_jobs(survey_id, created_at DESC);
+CREATE INDEX idx_export_jobs_pending ON export_jobs(created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_export_jobs_expiry ON export_jobs(expires_at) WHERE status = 'completed';
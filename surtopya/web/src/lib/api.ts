@@ -6,6 +6,20 @@ interface ApiError {
   error: string;
 }
 
+function responseTokenHeaders(responseToken?: string): Record<string, string> {
+  return responseToken ? { 'X-Response-Token': responseToken } : {};
+}
+
+function reportQuery(params: Record<string, string | number | boolean | SegmentCondition[] | undefined>): string {
+  const query = new URLSearchParams();
+  Object.entries(params).forEach(([key, value]) => {
+    if (value === undefined) return;
+    query.set(key, Array.isArray(value) ? JSON.stringify(value) : String(value));
+  });
+  const suffix = query.toString();
+  return suffix ? `?${suffix}` : '';
+}
+
 class ApiClient {
   private token: string | null = null;
 
@@ -39,6 +53,22 @@ class ApiClient {
     return response.json();
   }
 
+  // Fetches a file download, such as a printable survey
+  private async download(endpoint: string): Promise<Blob> {
+    const headers: Record<string, string> = {};
+    if (this.token) {
+      headers['Authorization'] = `Bearer ${this.token}`;
+    }
+
+    const response = await fetch(`${API_BASE_URL}${endpoint}`, { headers });
+    if (!response.ok) {
+      const error: ApiError = await response.json().catch(() => ({ error: 'Unknown error' }));
+      throw new Error(error.error || `HTTP error! status: ${response.status}`);
+    }
+
+    return response.blob();
+  }
+
   // Health check
   async health() {
     return this.request<{ status: string; message: string }>('/health');
@@ -53,6 +83,13 @@ class ApiClient {
     return this.request<{ surveys: Survey[] }>('/surveys/my');
   }
 
+  async acceptInvitation(token: string) {
+    return this.request<{ surveyId: string; role: SurveyAccessRole }>('/invitations/accept', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
   async getPublicSurveys(limit = 20, offset = 0) {
     return this.request<{ surveys: Survey[] }>(`/surveys/public?limit=${limit}&offset=${offset}`);
   }
@@ -71,6 +108,40 @@ class ApiClient {
     });
   }
 
+  async addQuestion(surveyId: string, question: Omit<Question, 'id' | 'surveyId' | 'sortOrder'> & { position?: number; revision?: number }) {
+    return this.request<QuestionEditResult>(`/surveys/${surveyId}/questions`, {
+      method: 'POST',
+      body: JSON.stringify(question),
+    });
+  }
+
+  async updateQuestion(surveyId: string, questionId: string, changes: Partial<Omit<Question, 'id' | 'surveyId' | 'sortOrder'>> & { revision?: number }) {
+    return this.request<QuestionEditResult>(`/surveys/${surveyId}/questions/${questionId}`, {
+      method: 'PATCH',
+      body: JSON.stringify(changes),
+    });
+  }
+
+  async deleteQuestion(surveyId: string, questionId: string, force = false) {
+    return this.request<QuestionEditResult>(`/surveys/${surveyId}/questions/${questionId}${force ? '?force=true' : ''}`, {
+      method: 'DELETE',
+    });
+  }
+
+  async moveQuestion(surveyId: string, questionId: string, position: number, revision?: number) {
+    return this.request<QuestionEditResult>(`/surveys/${surveyId}/questions/${questionId}/move`, {
+      method: 'POST',
+      body: JSON.stringify({ position, revision }),
+    });
+  }
+
+  async reorderQuestions(surveyId: string, questionIds: string[], revision?: number) {
+    return this.request<QuestionEditResult>(`/surveys/${surveyId}/questions/order`, {
+      method: 'PUT',
+      body: JSON.stringify({ questionIds, revision }),
+    });
+  }
+
   async deleteSurvey(id: string) {
     return this.request<{ message: string }>(`/surveys/${id}`, {
       method: 'DELETE',
@@ -90,6 +161,75 @@ class ApiClient {
     });
   }
 
+  async duplicateSurvey(id: string, data: { title?: string; organizationId?: string } = {}) {
+    return this.request<Survey>(`/surveys/${id}/duplicate`, {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async saveSurveyAsTemplate(id: string, data: { category: string; title?: string; description?: string; isPublic?: boolean }) {
+    return this.request<SurveyTemplate>(`/surveys/${id}/template`, {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async exportSurvey(id: string) {
+    return this.request<SurveyDocument>(`/surveys/${id}/export`);
+  }
+
+  async importSurvey(document: SurveyDocument, organizationId?: string) {
+    return this.request<Survey>(`/surveys/import${organizationId ? `?organizationId=${organizationId}` : ''}`, {
+      method: 'POST',
+      body: JSON.stringify(document),
+    });
+  }
+
+  // Renders the questionnaire for printing; section questions start new pages
+  async printSurvey(id: string, format: 'pdf' | 'markdown' = 'pdf') {
+    return this.download(`/surveys/${id}/print?format=${format}`);
+  }
+
+  // Converts a Google Forms (form JSON) or LimeSurvey (.lss) export into a new draft
+  async convertSurvey(format: 'google-forms' | 'limesurvey', file: Blob, options: { language?: string; organizationId?: string } = {}) {
+    const params = new URLSearchParams();
+    if (options.language) params.set('language', options.language);
+    if (options.organizationId) params.set('organizationId', options.organizationId);
+    const query = params.toString();
+    return this.request<{ survey: Survey; report: ConversionReport }>(`/surveys/import/${format}${query ? `?${query}` : ''}`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/octet-stream' },
+      body: file,
+    });
+  }
+
+  // Template endpoints
+  async getTemplates(category?: string) {
+    return this.request<{ templates: SurveyTemplate[] }>(`/templates${category ? `?category=${encodeURIComponent(category)}` : ''}`);
+  }
+
+  async getTemplateCategories() {
+    return this.request<{ categories: { category: string; count: number }[] }>('/templates/categories');
+  }
+
+  async getTemplate(id: string) {
+    return this.request<SurveyTemplate>(`/templates/${id}`);
+  }
+
+  async instantiateTemplate(id: string, data: { title?: string; organizationId?: string } = {}) {
+    return this.request<Survey>(`/templates/${id}/instantiate`, {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async deleteTemplate(id: string) {
+    return this.request<{ message: string }>(`/templates/${id}`, {
+      method: 'DELETE',
+    });
+  }
+
   // Response endpoints
   async startResponse(surveyId: string, anonymousId?: string) {
     return this.request<SurveyResponse>(`/surveys/${surveyId}/responses/start`, {
@@ -98,28 +238,231 @@ class ApiClient {
     });
   }
 
-  async submitAnswer(responseId: string, questionId: string, value: AnswerValue) {
+  // responseToken is the resumeToken returned by startResponse (required for anonymous respondents)
+  async getResponse(responseId: string, responseToken?: string) {
+    return this.request<SurveyResponse>(`/responses/${responseId}`, {
+      headers: responseTokenHeaders(responseToken),
+    });
+  }
+
+  async submitAnswer(responseId: string, questionId: string, value: AnswerValue, responseToken?: string) {
     return this.request<Answer>(`/responses/${responseId}/answers`, {
       method: 'POST',
+      headers: responseTokenHeaders(responseToken),
       body: JSON.stringify({ questionId, value }),
     });
   }
 
-  async submitAllAnswers(responseId: string, answers: SubmitAnswerRequest[]) {
+  async submitAllAnswers(responseId: string, answers: SubmitAnswerRequest[], responseToken?: string) {
     return this.request<{
       message: string;
       response: SurveyResponse;
       pointsAwarded: number;
+      screenedOut: boolean;
     }>(`/responses/${responseId}/submit`, {
       method: 'POST',
+      headers: responseTokenHeaders(responseToken),
       body: JSON.stringify({ answers }),
     });
   }
 
+  async resumeResponse(resumeToken: string) {
+    return this.request<{ response: SurveyResponse; survey: Survey }>('/responses/resume', {
+      method: 'POST',
+      body: JSON.stringify({ resumeToken }),
+    });
+  }
+
   async getSurveyResponses(surveyId: string) {
     return this.request<{ responses: SurveyResponse[] }>(`/surveys/${surveyId}/responses`);
   }
 
+  // Per-question aggregates for the survey report page
+  async getSurveyReport(surveyId: string, params: ReportParams = {}) {
+    return this.request<SurveyReport>(`/surveys/${surveyId}/report${reportQuery(params)}`);
+  }
+
+  // Cross-tabulates two choice or rating questions, with a chi-square test
+  async getCrosstab(surveyId: string, row: string, column: string, params: ReportFilterParams = {}) {
+    return this.request<Crosstab>(`/surveys/${surveyId}/crosstab${reportQuery({ ...params, row, column })}`);
+  }
+
+  // Compares a rating question between two segments (Welch's t-test and Mann–Whitney U)
+  async compareSegments(surveyId: string, question: string, segmentA: SegmentCondition[], segmentB: SegmentCondition[], params: ReportFilterParams & { confidence?: number } = {}) {
+    return this.request<SegmentComparison>(`/surveys/${surveyId}/compare${reportQuery({ ...params, question, segmentA, segmentB })}`);
+  }
+
+  // Cronbach's alpha of a set of rating questions
+  async getReliability(surveyId: string, questions: string[], params: ReportFilterParams = {}) {
+    return this.request<ReliabilityReport>(`/surveys/${surveyId}/reliability${reportQuery({ ...params, questions: questions.join(',') })}`);
+  }
+
+  // Pairwise correlations of rating questions (all rating questions by default)
+  async getCorrelations(surveyId: string, options: ReportFilterParams & { questions?: string[]; method?: 'pearson' | 'spearman' } = {}) {
+    const { questions, ...params } = options;
+    return this.request<CorrelationMatrix>(`/surveys/${surveyId}/correlations${reportQuery({ ...params, questions: questions?.join(',') })}`);
+  }
+
+  // Drop-off funnel along each response's skip-logic path (status defaults to all)
+  async getFunnel(surveyId: string, params: ReportFilterParams = {}) {
+    return this.request<Funnel>(`/surveys/${surveyId}/funnel${reportQuery(params)}`);
+  }
+
+  // Responses as a CSV file, with a 0/1 column per code of coded text questions
+  // csv downloads the table; sav, dta and r download a zip with a codebook
+  async exportResponses(surveyId: string, params: ReportFilterParams = {}, format: ExportFormat = 'csv') {
+    return this.download(`/surveys/${surveyId}/responses/export${reportQuery({ ...params, format })}`);
+  }
+
+  // Background exports for large surveys: queue, poll, then download from downloadUrl
+  async createExportJob(surveyId: string, params: ReportFilterParams = {}, format: ExportFormat = 'csv') {
+    return this.request<ExportJob>(`/surveys/${surveyId}/export-jobs${reportQuery({ ...params, format })}`, {
+      method: 'POST',
+    });
+  }
+
+  async getExportJobs(surveyId: string) {
+    return this.request<{ jobs: ExportJob[] }>(`/surveys/${surveyId}/export-jobs`);
+  }
+
+  async getExportJob(jobId: string) {
+    return this.request<ExportJob>(`/export-jobs/${jobId}`);
+  }
+
+  // The signed link works without a login until downloadUrlExpiresAt
+  exportDownloadUrl(job: ExportJob): string | undefined {
+    return job.downloadUrl ? `${API_BASE_URL}${job.downloadUrl}` : undefined;
+  }
+
+  // Codebooks of open-text questions
+  async getCodes(surveyId: string, questionId?: string) {
+    return this.request<{ codes: AnswerCode[] }>(`/surveys/${surveyId}/codes${questionId ? `?question=${questionId}` : ''}`);
+  }
+
+  async createCode(surveyId: string, code: { questionId: string; label: string; description?: string; color?: string }) {
+    return this.request<AnswerCode>(`/surveys/${surveyId}/codes`, {
+      method: 'POST',
+      body: JSON.stringify(code),
+    });
+  }
+
+  async updateCode(surveyId: string, codeId: string, code: { label: string; description?: string; color?: string; position?: number }) {
+    return this.request<AnswerCode>(`/surveys/${surveyId}/codes/${codeId}`, {
+      method: 'PUT',
+      body: JSON.stringify(code),
+    });
+  }
+
+  async deleteCode(surveyId: string, codeId: string) {
+    return this.request<{ message: string }>(`/surveys/${surveyId}/codes/${codeId}`, { method: 'DELETE' });
+  }
+
+  // Text answers of a question with their codes, for tagging
+  async getCodedAnswers(surveyId: string, questionId: string, params: { code?: string; uncoded?: boolean; search?: string; limit?: number; offset?: number } = {}) {
+    const searchParams = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== '') searchParams.append(key, String(value));
+    });
+    const query = searchParams.toString();
+    return this.request<{ answers: CodedAnswer[]; total: number }>(
+      `/surveys/${surveyId}/questions/${questionId}/answers${query ? `?${query}` : ''}`
+    );
+  }
+
+  // Tags answers with codes in bulk
+  async assignCodes(surveyId: string, questionId: string, assignment: { answerIds: string[]; codeIds: string[]; mode?: 'add' | 'remove' | 'set' }) {
+    return this.request<{ answers: number }>(`/surveys/${surveyId}/questions/${questionId}/codes`, {
+      method: 'POST',
+      body: JSON.stringify(assignment),
+    });
+  }
+
+  // Clusters a question's text answers into suggested codes (nothing is saved)
+  async suggestCodes(surveyId: string, questionId: string, k?: number) {
+    return this.request<{ suggestions: CodeSuggestion[]; answers: number }>(
+      `/surveys/${surveyId}/questions/${questionId}/code-suggestions${k ? `?k=${k}` : ''}`
+    );
+  }
+
+  // Population margins the responses are raked to
+  async getWeighting(surveyId: string) {
+    return this.request<WeightingScheme>(`/surveys/${surveyId}/weighting`);
+  }
+
+  async saveWeighting(surveyId: string, scheme: { margins: WeightingMargin[]; maxWeight?: number }) {
+    return this.request<WeightingScheme>(`/surveys/${surveyId}/weighting`, {
+      method: 'PUT',
+      body: JSON.stringify(scheme),
+    });
+  }
+
+  // Uploads margins as CSV rows of question (ID or title), option and share
+  async uploadWeighting(surveyId: string, file: Blob, maxWeight?: number) {
+    return this.request<WeightingScheme>(`/surveys/${surveyId}/weighting${maxWeight ? `?maxWeight=${maxWeight}` : ''}`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'text/csv' },
+      body: file,
+    });
+  }
+
+  async deleteWeighting(surveyId: string) {
+    return this.request<{ message: string }>(`/surveys/${surveyId}/weighting`, { method: 'DELETE' });
+  }
+
+  async getWeightingDiagnostics(surveyId: string, params: Omit<ReportFilterParams, 'segment'> = {}) {
+    return this.request<WeightingDiagnostics>(`/surveys/${surveyId}/weighting/diagnostics${reportQuery(params)}`);
+  }
+
+  // Cached AI summary of the survey's results for its current version
+  async getSummary(surveyId: string) {
+    return this.request<SurveyInsight>(`/surveys/${surveyId}/summary`);
+  }
+
+  // (Re)generates the AI summary with the language model
+  async generateSummary(surveyId: string) {
+    return this.request<SurveyInsight>(`/surveys/${surveyId}/summary`, { method: 'POST' });
+  }
+
+  // Live stream of completed responses for the survey owner (Server-Sent Events).
+  // Uses fetch rather than EventSource so the bearer token can be sent; abort
+  // the signal to close it. Resolves when the server ends the stream.
+  async streamResponses(surveyId: string, onEvent: (event: LiveResponseEvent) => void, signal?: AbortSignal) {
+    const headers: Record<string, string> = { Accept: 'text/event-stream' };
+    if (this.token) {
+      headers['Authorization'] = `Bearer ${this.token}`;
+    }
+
+    const response = await fetch(`${API_BASE_URL}/surveys/${surveyId}/responses/stream`, { headers, signal });
+    if (!response.ok || !response.body) {
+      const error: ApiError = await response.json().catch(() => ({ error: 'Unknown error' }));
+      throw new Error(error.error || `HTTP error! status: ${response.status}`);
+    }
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = '';
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) return;
+      buffer += value;
+
+      let end;
+      while ((end = buffer.indexOf('\n\n')) >= 0) {
+        const block = buffer.slice(0, end);
+        buffer = buffer.slice(end + 2);
+
+        let name = 'message';
+        const data: string[] = [];
+        for (const line of block.split('\n')) {
+          if (line.startsWith('event:')) name = line.slice(6).trim();
+          else if (line.startsWith('data:')) data.push(line.slice(5).trimStart());
+        }
+        if (data.length > 0 || name === 'resync') {
+          onEvent({ event: name, data: data.length > 0 ? JSON.parse(data.join('\n')) : null } as LiveResponseEvent);
+        }
+      }
+    }
+  }
+
   // Dataset endpoints
   async getDatasets(params?: {
     category?: string;
@@ -186,9 +529,12 @@ export interface Question {
   sortOrder?: number;
 }
 
+export type SurveyAccessRole = 'owner' | 'editor' | 'analyst' | 'viewer';
+
 export interface Survey {
   id: string;
   userId?: string;
+  organizationId?: string;
   title: string;
   description: string;
   visibility: 'public' | 'non-public';
@@ -199,10 +545,48 @@ export interface Survey {
   pointsReward: number;
   expiresAt?: string;
   responseCount: number;
+  revision: number;
   createdAt: string;
   updatedAt: string;
   publishedAt?: string;
   questions?: Question[];
+  role?: SurveyAccessRole; // Set on surveys returned by getMySurveys
+}
+
+// Portable survey document (see api/schemas/survey-document.v1.json)
+export interface SurveyDocument {
+  format: 'surtopya.survey';
+  version: number;
+  exportedAt?: string;
+  survey: Record<string, unknown>;
+}
+
+export interface ConversionReport {
+  source: string;
+  converted: number;
+  skipped: number;
+  issues: { item: string; kind: 'skipped' | 'changed'; message: string }[];
+}
+
+export interface SurveyTemplate {
+  id: string;
+  slug?: string;
+  category: string;
+  title: string;
+  description: string;
+  theme?: SurveyTheme;
+  questions: Question[];
+  isPublic: boolean;
+  isCurated: boolean;
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface QuestionEditResult {
+  revision: number;
+  questions: Question[];
+  question?: Question;
 }
 
 export interface CreateSurveyRequest {
@@ -221,6 +605,7 @@ export interface UpdateSurveyRequest {
   theme?: SurveyTheme;
   pointsReward?: number;
   questions?: Omit<Question, 'surveyId' | 'sortOrder'>[];
+  revision?: number; // Revision the edit is based on; stale edits are rejected with 409
 }
 
 export interface PublishSurveyRequest {
@@ -255,14 +640,320 @@ export interface SurveyResponse {
   surveyId: string;
   userId?: string;
   anonymousId?: string;
-  status: 'in_progress' | 'completed' | 'abandoned';
+  status: 'in_progress' | 'completed' | 'abandoned' | 'quota_full' | 'disqualified';
   pointsAwarded: number;
   startedAt: string;
   completedAt?: string;
+  lastActivityAt: string;
   createdAt: string;
+  resumeToken?: string;
   answers?: Answer[];
 }
 
+export interface SegmentCondition {
+  questionId: string;
+  operator: 'in' | 'not_in' | 'between' | 'answered' | 'not_answered';
+  values?: string[];
+  min?: number;
+  max?: number;
+}
+
+export interface ReportFilterParams {
+  from?: string;
+  to?: string;
+  status?: string; // Comma-separated statuses or 'all'; defaults to completed
+  segment?: SegmentCondition[];
+}
+
+export interface ReportParams extends ReportFilterParams {
+  dateInterval?: 'day' | 'week' | 'month' | 'year';
+  words?: number;
+  confidence?: number;
+  weighted?: boolean; // Add estimates raked to the survey's weighting scheme
+}
+
+export interface ConfidenceInterval {
+  estimate: number;
+  lower: number;
+  upper: number;
+  confidence: number;
+}
+
+export interface ReportFilter {
+  from?: string;
+  to?: string;
+  statuses: string[];
+  segment?: SegmentCondition[];
+}
+
+export interface OptionCount {
+  option: string;
+  count: number;
+  percentage: number;
+  ci?: ConfidenceInterval; // Of the proportion (0-1)
+  weighted?: { percentage: number; ci?: ConfidenceInterval }; // Weighted reports only
+}
+
+export interface QuestionReport {
+  questionId: string;
+  type: Question['type'];
+  title: string;
+  answered: number;
+  skipped: number;
+  options?: OptionCount[];
+  rating?: {
+    mean: number | null;
+    median: number | null;
+    stdDev: number | null;
+    meanCI?: ConfidenceInterval;
+    weightedMean?: ConfidenceInterval; // Weighted reports only
+    distribution: { rating: number; count: number; percentage: number }[];
+  };
+  dates?: {
+    interval: string;
+    earliest: string | null;
+    latest: string | null;
+    buckets: { start: string; count: number }[];
+  };
+  words?: { word: string; count: number }[];
+  codes?: { codeId: string; label: string; count: number; percentage: number }[];
+}
+
+export interface SurveyReport {
+  surveyId: string;
+  filter: ReportFilter;
+  responses: number;
+  confidence: number;
+  weighting?: WeightingDiagnostics; // Weighted reports only
+  questions: QuestionReport[];
+  generatedAt: string;
+}
+
+export interface ChiSquareTest {
+  statistic: number;
+  df: number;
+  pValue: number;
+  cramersV: number;
+  n: number;
+  minExpected: number;
+  lowExpectedCells: number;
+}
+
+export interface Crosstab {
+  surveyId: string;
+  filter: ReportFilter;
+  row: CrosstabAxis;
+  column: CrosstabAxis;
+  counts: number[][];
+  rowTotals: number[];
+  columnTotals: number[];
+  total: number;
+  rowPercentages: number[][];
+  columnPercentages: number[][];
+  chiSquare: ChiSquareTest | null;
+  notes: string[];
+}
+
+export interface CrosstabAxis {
+  questionId: string;
+  type: Question['type'];
+  title: string;
+  categories: string[];
+}
+
+export interface RatingGroup {
+  segment: SegmentCondition[];
+  n: number;
+  meanCI: ConfidenceInterval | null;
+}
+
+export interface SegmentComparison {
+  surveyId: string;
+  questionId: string;
+  filter: ReportFilter;
+  a: RatingGroup;
+  b: RatingGroup;
+  tTest: {
+    statistic: number;
+    df: number;
+    pValue: number;
+    meanA: number;
+    meanB: number;
+    difference: number;
+    cohensD: number;
+    nA: number;
+    nB: number;
+  } | null;
+  mannWhitney: { u: number; z: number; pValue: number; effectSize: number; nA: number; nB: number } | null;
+}
+
+export interface ReliabilityReport {
+  surveyId: string;
+  questionIds: string[];
+  filter: ReportFilter;
+  reliability: {
+    alpha: number;
+    items: number;
+    n: number;
+    alphaIfDeleted: number[];
+    itemTotalCorrelations: number[];
+  } | null;
+}
+
+export interface Correlation {
+  method: 'pearson' | 'spearman';
+  r: number;
+  pValue: number;
+  n: number;
+}
+
+export interface CorrelationMatrix {
+  surveyId: string;
+  questionIds: string[];
+  filter: ReportFilter;
+  method: 'pearson' | 'spearman';
+  correlations: (Correlation | null)[][];
+}
+
+export interface Funnel {
+  surveyId: string;
+  filter: ReportFilter;
+  responses: number;
+  completed: number;
+  screenedOut: number;
+  abandoned: number;
+  inProgress: number;
+  completionRate: number;
+  medianCompletionSeconds: number | null;
+  steps: FunnelStep[];
+  notes: string[];
+}
+
+export interface FunnelStep {
+  questionId: string | null;
+  type: string;
+  title: string;
+  reached: number;
+  answered: number;
+  bypassed: number;
+  droppedOff: number;
+  inProgress: number;
+  dropOffRate: number;
+  medianSeconds: number | null;
+  timedAnswers: number;
+}
+
+export interface AnswerCode {
+  id: string;
+  surveyId: string;
+  questionId: string;
+  label: string;
+  description?: string;
+  color?: string;
+  position: number;
+  answers: number;
+  createdBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface CodedAnswer {
+  answerId: string;
+  responseId: string;
+  text: string;
+  codeIds: string[];
+  createdAt: string;
+}
+
+export interface CodeSuggestion {
+  label: string;
+  terms: string[];
+  answerIds: string[];
+  examples: string[];
+}
+
+export interface WeightingMargin {
+  questionId: string; // A single-choice or dropdown question
+  targets: { option: string; share: number }[]; // Shares are scaled to sum to 1
+}
+
+export interface WeightingScheme {
+  surveyId: string;
+  margins: WeightingMargin[];
+  maxWeight?: number; // Cap as a multiple of the mean weight
+  updatedBy?: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WeightingDiagnostics {
+  responses: number;
+  iterations: number;
+  converged: boolean;
+  effectiveN: number;
+  designEffect: number;
+  minWeight: number;
+  maxWeight: number;
+  margins: {
+    questionId: string;
+    title: string;
+    missing: number;
+    categories: { option: string; responses: number; target: number; sample: number; weighted: number }[];
+  }[];
+}
+
+export type ExportFormat = 'csv' | 'sav' | 'dta' | 'r';
+
+export interface ExportJob {
+  id: string;
+  surveyId: string;
+  requestedBy?: string;
+  format: ExportFormat;
+  filter: ReportFilter;
+  status: 'queued' | 'running' | 'completed' | 'failed' | 'expired';
+  totalRows?: number;
+  rowsWritten: number;
+  attempts: number;
+  fileName?: string;
+  fileSize?: number;
+  error?: string;
+  createdAt: string;
+  startedAt?: string;
+  completedAt?: string;
+  expiresAt?: string;
+  downloadUrl?: string;
+  downloadUrlExpiresAt?: string;
+}
+
+export interface SurveyInsight {
+  surveyId: string;
+  revision: number;
+  model: string;
+  responses: number;
+  summary: string;
+  stale: boolean;
+  createdAt: string;
+}
+
+export type LiveResponseEvent =
+  | { event: 'ready'; data: { surveyId: string; completed: number } }
+  | { event: 'response'; data: ResponseEvent }
+  | { event: 'resync'; data: null };
+
+export interface ResponseEvent {
+  surveyId: string;
+  responseId: string;
+  completedAt: string;
+  completed: number;
+  deltas: AnswerDelta[];
+}
+
+export interface AnswerDelta {
+  questionId: string;
+  options?: string[];
+  rating?: number;
+}
+
 export interface Dataset {
   id: string;
   surveyId: string;